@@ -2,12 +2,14 @@ package beaconapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	eth2client "github.com/attestantio/go-eth2-client"
 	"github.com/attestantio/go-eth2-client/api"
 	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/http"
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -21,12 +23,21 @@ import (
 const (
 	SLOTS_PER_EPOCH  = "SLOTS_PER_EPOCH"
 	SECONDS_PER_SLOT = "SECONDS_PER_SLOT"
+	// ALTAIR_FORK_EPOCH is the first epoch with participation flags and the rewards api.
+	ALTAIR_FORK_EPOCH = "ALTAIR_FORK_EPOCH"
 )
 
 var (
 	validatorListCacheKey = "validator_list"
+	// validatorListCacheTTL keep the head validator list for about one epoch.
+	validatorListCacheTTL = time.Second * 384
 )
 
+type validatorListCache struct {
+	vals    []*phase0.Validator
+	fetched time.Time
+}
+
 type BeaconClient struct {
 	endpoint string
 	config   map[string]string
@@ -111,7 +122,10 @@ func (b *BeaconClient) getLatestBeaconHeader() (*apiv1.BeaconBlockHeader, error)
 
 func (b *BeaconClient) GetValidatorsList() ([]*phase0.Validator, error) {
 	if v, ok := b.cache.Get(validatorListCacheKey); ok {
-		return v.([]*phase0.Validator), nil
+		if cached := v.(*validatorListCache); time.Since(cached.fetched) < validatorListCacheTTL {
+			return cached.vals, nil
+		}
+		b.cache.Remove(validatorListCacheKey)
 	}
 	service, err := b.getService()
 	if err != nil {
@@ -133,11 +147,61 @@ func (b *BeaconClient) GetValidatorsList() ([]*phase0.Validator, error) {
 		log.WithError(err).Error("get validators failed")
 		return nil, err
 	}
-	b.cache.Add(validatorListCacheKey, vals)
+	b.cache.Add(validatorListCacheKey, &validatorListCache{vals: vals, fetched: time.Now()})
 
 	return vals, nil
 }
 
+// GetValidatorIndexByPubkeys resolve validator index for the given pubkeys at state,
+// pubkeys that are not yet in the registry are absent from the result.
+func (b *BeaconClient) GetValidatorIndexByPubkeys(state string, pubkeys []phase0.BLSPubKey) (map[phase0.BLSPubKey]phase0.ValidatorIndex, error) {
+	indices := make(map[phase0.BLSPubKey]phase0.ValidatorIndex)
+	if len(pubkeys) == 0 {
+		return indices, nil
+	}
+	service, err := b.getService()
+	if err != nil {
+		log.WithError(err).Error("create eth2client failed")
+		return nil, err
+	}
+	res, err := service.(eth2client.ValidatorsProvider).Validators(context.Background(), &api.ValidatorsOpts{
+		Common: api.CommonOpts{
+			Timeout: time.Second * 10,
+		},
+		State:   state,
+		PubKeys: pubkeys,
+	})
+	if err != nil {
+		log.WithError(err).Error("get validators by pubkeys failed")
+		return nil, err
+	}
+	for index, val := range res.Data {
+		indices[val.Validator.PublicKey] = index
+	}
+	return indices, nil
+}
+
+// GetValidators return the full validator registry with balance and status at state.
+// state: "head", "genesis", "finalized", "justified", <slot>, <hex encoded stateRoot with 0x prefix>.
+func (b *BeaconClient) GetValidators(state string) (map[phase0.ValidatorIndex]*apiv1.Validator, error) {
+	service, err := b.getService()
+	if err != nil {
+		log.WithError(err).Error("create eth2client failed")
+		return nil, err
+	}
+	res, err := service.(eth2client.ValidatorsProvider).Validators(context.Background(), &api.ValidatorsOpts{
+		Common: api.CommonOpts{
+			Timeout: time.Second * 120,
+		},
+		State: state,
+	})
+	if err != nil {
+		log.WithField("state", state).WithError(err).Error("get validators failed")
+		return nil, err
+	}
+	return res.Data, nil
+}
+
 func (b *BeaconClient) GetLatestValidators() (*spec.VersionedBeaconState, error) {
 	service, err := b.getService()
 	if err != nil {
@@ -180,6 +244,31 @@ func (b *BeaconClient) GetBeaconState(slot string) (*spec.VersionedBeaconState,
 	return res.Data, nil
 }
 
+// GetPreviousEpochParticipation return the participation flags of every validator for the
+// epoch before the one of state, indexed by validator index.
+func (b *BeaconClient) GetPreviousEpochParticipation(state string) ([]altair.ParticipationFlags, error) {
+	st, err := b.GetBeaconState(state)
+	if err != nil {
+		return nil, err
+	}
+	switch st.Version {
+	case spec.DataVersionAltair:
+		return st.Altair.PreviousEpochParticipation, nil
+	case spec.DataVersionBellatrix:
+		return st.Bellatrix.PreviousEpochParticipation, nil
+	case spec.DataVersionCapella:
+		return st.Capella.PreviousEpochParticipation, nil
+	case spec.DataVersionDeneb:
+		return st.Deneb.PreviousEpochParticipation, nil
+	case spec.DataVersionElectra:
+		return st.Electra.PreviousEpochParticipation, nil
+	case spec.DataVersionFulu:
+		return st.Fulu.PreviousEpochParticipation, nil
+	default:
+		return nil, fmt.Errorf("no participation flags in %s state", st.Version)
+	}
+}
+
 func (b *BeaconClient) GetLatestBeaconHeader() (*apiv1.BeaconBlockHeader, error) {
 	return b.getLatestBeaconHeader()
 }
@@ -243,7 +332,7 @@ func (b *BeaconClient) getAttesterDuties(epoch int, vals []int) ([]*apiv1.Attest
 		log.WithError(err).Error("create eth2client failed")
 		return nil, err
 	}
-	indices := make([]phase0.ValidatorIndex, len(vals))
+	indices := make([]phase0.ValidatorIndex, 0, len(vals))
 	for _, val := range vals {
 		indices = append(indices, phase0.ValidatorIndex(val))
 	}
@@ -369,6 +458,84 @@ func (b *BeaconClient) GetSlotRoot(slot int64) (string, error) {
 	return root.String(), nil
 }
 
+// GetCanonicalRoot return the root of the canonical block at slot, empty if the slot has no block.
+func (b *BeaconClient) GetCanonicalRoot(slot uint64) (string, error) {
+	root, err := b.getSlotRoot(int64(slot))
+	if IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil || root == nil {
+		return "", err
+	}
+	return root.String(), nil
+}
+
+// GetValidatorsByIndices return the validators with balance and status at state for the given indices.
+func (b *BeaconClient) GetValidatorsByIndices(state string, indices []phase0.ValidatorIndex) (map[phase0.ValidatorIndex]*apiv1.Validator, error) {
+	if len(indices) == 0 {
+		return make(map[phase0.ValidatorIndex]*apiv1.Validator), nil
+	}
+	service, err := b.getService()
+	if err != nil {
+		log.WithError(err).Error("create eth2client failed")
+		return nil, err
+	}
+	res, err := service.(eth2client.ValidatorsProvider).Validators(context.Background(), &api.ValidatorsOpts{
+		Common: api.CommonOpts{
+			Timeout: time.Second * 10,
+		},
+		State:   state,
+		Indices: indices,
+	})
+	if err != nil {
+		log.WithField("state", state).WithError(err).Error("get validators by indices failed")
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// GetSyncCommittee return the sync committee members of the epoch, in committee position order.
+func (b *BeaconClient) GetSyncCommittee(state string, epoch phase0.Epoch) ([]phase0.ValidatorIndex, error) {
+	service, err := b.getService()
+	if err != nil {
+		log.WithError(err).Error("create eth2client failed")
+		return nil, err
+	}
+	res, err := service.(eth2client.SyncCommitteesProvider).SyncCommittee(context.Background(), &api.SyncCommitteeOpts{
+		Common: api.CommonOpts{
+			Timeout: time.Second * 10,
+		},
+		State: state,
+		Epoch: &epoch,
+	})
+	if err != nil {
+		log.WithField("epoch", epoch).WithError(err).Error("get sync committee failed")
+		return nil, err
+	}
+	return res.Data.Validators, nil
+}
+
+// GetFinalityCheckpoints
+// state: "head", "genesis", "finalized", "justified", <slot>, <hex encoded stateRoot with 0x prefix>.
+func (b *BeaconClient) GetFinalityCheckpoints(state string) (*apiv1.Finality, error) {
+	service, err := b.getService()
+	if err != nil {
+		log.WithError(err).Error("create eth2client failed")
+		return nil, err
+	}
+	res, err := service.(eth2client.FinalityProvider).Finality(context.Background(), &api.FinalityOpts{
+		Common: api.CommonOpts{
+			Timeout: time.Second * 10,
+		},
+		State: state,
+	})
+	if err != nil {
+		log.WithField("state", state).WithError(err).Error("get finality checkpoints failed")
+		return nil, err
+	}
+	return res.Data, nil
+}
+
 func (b *BeaconClient) getService() (eth2client.Service, error) {
 	if b.service == nil {
 		service, err := newClient(context.Background(), b.endpoint)
@@ -415,6 +582,13 @@ func (b *BeaconClient) GetBlockHeaderById(id string) (*apiv1.BeaconBlockHeader,
 	return res.Data, nil
 }
 
+// IsNotFound return true if err is the beacon node reporting the requested object does not
+// exist, such as the block of an empty slot.
+func IsNotFound(err error) bool {
+	var apiErr *api.Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 404
+}
+
 func (b *BeaconClient) GetDenebBlockBySlot(slot uint64) (*deneb.SignedBeaconBlock, error) {
 	service, err := b.getService()
 	if err != nil {
@@ -1 +1,51 @@
 package beaconapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxRawBodySize bound the responses read by GetRaw, a block with blobs is a few MB.
+const maxRawBodySize = 32 << 20
+
+var rawClient = &http.Client{Timeout: time.Second * 30}
+
+// RawResponse is an undecoded response of the beacon node.
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// GetRaw send a GET request for path, with its query, to the beacon node and return the
+// response as is, with the Accept header of accept.
+func (b *BeaconClient) GetRaw(ctx context.Context, path string, accept string) (*RawResponse, error) {
+	endpoint := strings.TrimSuffix(b.endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	res, err := rawClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxRawBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxRawBodySize {
+		return nil, fmt.Errorf("response of %s is larger than %d bytes", path, maxRawBodySize)
+	}
+	return &RawResponse{StatusCode: res.StatusCode, Header: res.Header, Body: body}, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/internal/database"
+	"github.com/xueqianLu/deep-dive-beacon/internal/logger"
+	"github.com/xueqianLu/deep-dive-beacon/internal/redis"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"strconv"
+)
+
+var (
+	apiKeyName      string
+	apiKeyScopes    []string
+	apiKeyRateLimit int
+)
+
+var apikeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys",
+	Long:  `Create, list or revoke the keys used to access the API`,
+}
+
+var apikeyCreate = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key",
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, log := apiKeyService()
+		plain, key, err := svc.Create(apiKeyName, apiKeyScopes, apiKeyRateLimit)
+		if err != nil {
+			log.Fatalf("Failed to create api key: %v", err)
+		}
+		fmt.Printf("created api key %d (%s), it will not be shown again:\n%s\n", key.ID, key.Scopes, plain)
+	},
+}
+
+var apikeyList = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, log := apiKeyService()
+		keys, err := svc.List()
+		if err != nil {
+			log.Fatalf("Failed to list api keys: %v", err)
+		}
+		for _, k := range keys {
+			fmt.Printf("%d\t%s...\t%s\t%s\trate=%d\trevoked=%v\n", k.ID, k.Prefix, k.Name, k.Scopes, k.RateLimit, k.Revoked)
+		}
+	},
+}
+
+var apikeyRevoke = &cobra.Command{
+	Use:   "revoke <id>...",
+	Short: "Revoke API keys",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, log := apiKeyService()
+		for _, arg := range args {
+			id, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				log.Fatalf("Invalid api key id %s", arg)
+			}
+			revoked, err := svc.Revoke(uint(id))
+			if err != nil {
+				log.Fatalf("Failed to revoke api key %d: %v", id, err)
+			}
+			if !revoked {
+				fmt.Printf("api key %d not found\n", id)
+				continue
+			}
+			fmt.Printf("revoked api key %d\n", id)
+		}
+	},
+}
+
+func apiKeyService() (*services.ApiKeyService, *logrus.Logger) {
+	cfg := config.Load()
+	log := logger.Init(cfg.Log.Level)
+
+	db, err := database.Init(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	// redis is only used to drop cached keys on revoke.
+	rdb, err := redis.Init(cfg.Redis)
+	if err != nil {
+		log.WithError(err).Warn("Failed to initialize redis, revoked keys stay cached until expired")
+		rdb = nil
+	}
+	return services.NewApiKeyService(db, rdb, log), log
+}
+
+func init() {
+	apikeyCreate.Flags().StringVar(&apiKeyName, "name", "", "Name of the key")
+	apikeyCreate.Flags().StringSliceVar(&apiKeyScopes, "scopes", []string{"read"}, "Scopes of the key, read or admin")
+	apikeyCreate.Flags().IntVar(&apiKeyRateLimit, "rate-limit", 0, "Requests per minute, 0 to use the default")
+	apikeyCmd.AddCommand(apikeyCreate)
+	apikeyCmd.AddCommand(apikeyList)
+	apikeyCmd.AddCommand(apikeyRevoke)
+	rootCmd.AddCommand(apikeyCmd)
+}
@@ -30,18 +30,48 @@ func NewProdDeploy(db *gorm.DB, redis *redis.Client, logger *logrus.Logger, cfg
 
 func (d ProdDeploy) Execute() error {
 	if d.depcfg.BlockScan != nil {
-		d.addScanTask(d.depcfg.BlockScan)
+		d.addScanTask(constant.SCAN_TYPE_BEACON_BLOCK, d.depcfg.BlockScan.Start)
 	}
 	if d.depcfg.DirectScan != nil {
 		d.addDirectlyScan(d.depcfg.DirectScan)
 	}
+	if d.depcfg.ValidatorSnapshot != nil {
+		d.addScanTask(constant.SCAN_TYPE_VALIDATOR_SNAPSHOT, d.depcfg.ValidatorSnapshot.Start)
+	}
+	if d.depcfg.BalanceSample != nil {
+		d.addScanTask(constant.SCAN_TYPE_VALIDATOR_BALANCE, d.depcfg.BalanceSample.Start)
+	}
+	if d.depcfg.Duties != nil {
+		d.addScanTask(constant.SCAN_TYPE_DUTIES, d.depcfg.Duties.Start)
+	}
+	if d.depcfg.EpochSummary != nil {
+		d.addScanTask(constant.SCAN_TYPE_EPOCH_SUMMARY, d.depcfg.EpochSummary.Start)
+	}
+	if d.depcfg.Finality != nil {
+		d.addScanTask(constant.SCAN_TYPE_FINALITY, d.depcfg.Finality.Start)
+	}
+	if d.depcfg.Eth1Scan != nil {
+		d.addScanTask(constant.SCAN_TYPE_ETH1_BLOCK, d.depcfg.Eth1Scan.Start)
+	}
+	if d.depcfg.DepositScan != nil {
+		d.addScanTask(constant.SCAN_TYPE_DEPOSIT, d.depcfg.DepositScan.Start)
+	}
+	if d.depcfg.PayloadLink != nil {
+		d.addScanTask(constant.SCAN_TYPE_PAYLOAD_LINK, d.depcfg.PayloadLink.Start)
+	}
+	if d.depcfg.Mev != nil {
+		d.addScanTask(constant.SCAN_TYPE_MEV, d.depcfg.Mev.Start)
+	}
+	if d.depcfg.Watchlist != nil {
+		d.addScanTask(constant.SCAN_TYPE_WATCHLIST, d.depcfg.Watchlist.Start)
+	}
 	return nil
 }
 
-func (d ProdDeploy) addScanTask(scan *types.BlockScanTask) error {
+func (d ProdDeploy) addScanTask(taskType string, start uint64) error {
 	task := &dbmodels.ScanTask{
-		TaskType:   constant.SCAN_TYPE_BEACON_BLOCK,
-		LastNumber: scan.Start,
+		TaskType:   taskType,
+		LastNumber: start,
 		Enabled:    true,
 	}
 	return d.db.Create(task).Error
@@ -6,8 +6,19 @@ import (
 	"github.com/xueqianLu/deep-dive-beacon/internal/database"
 	"github.com/xueqianLu/deep-dive-beacon/internal/logger"
 	"github.com/xueqianLu/deep-dive-beacon/internal/redis"
+	"github.com/xueqianLu/deep-dive-beacon/processor/balancesampler"
 	"github.com/xueqianLu/deep-dive-beacon/processor/blockscanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/depositscanner"
 	"github.com/xueqianLu/deep-dive-beacon/processor/directlysync"
+	"github.com/xueqianLu/deep-dive-beacon/processor/dutyscanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/epochscanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/eth1scanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/finalityscanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/mevscanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/payloadlinker"
+	"github.com/xueqianLu/deep-dive-beacon/processor/validatorscanner"
+	"github.com/xueqianLu/deep-dive-beacon/processor/watchmonitor"
+	"github.com/xueqianLu/deep-dive-beacon/processor/webhooksender"
 	"os"
 	"os/signal"
 	"syscall"
@@ -95,7 +106,491 @@ var directScan = &cobra.Command{
 	},
 }
 
+var validatorScan = &cobra.Command{
+	Use:   "validator-scanner",
+	Short: "Start the validator snapshot scanner",
+	Long:  `Start the validator scanner to snapshot the validator registry and store to database`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := validatorscanner.NewValidatorScanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Validator scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping validator scanner...")
+		scanner.Stop()
+
+		log.Info("Validator scanner stopped")
+	},
+}
+
+var balanceSample = &cobra.Command{
+	Use:   "balance-sampler",
+	Short: "Start the validator balance sampler",
+	Long:  `Start the balance sampler to store validator balance history and daily rollups to database`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		sampler := balancesampler.NewBalanceSampler(cfg, db, rdb, log)
+
+		go func() {
+			if err := sampler.Start(); err != nil {
+				log.Fatalf("Balance sampler failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping balance sampler...")
+		sampler.Stop()
+
+		log.Info("Balance sampler stopped")
+	},
+}
+
+var dutyScan = &cobra.Command{
+	Use:   "duty-scanner",
+	Short: "Start the duty scanner",
+	Long:  `Start the duty scanner to store proposer and attester duties and track their fulfilment`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := dutyscanner.NewDutyScanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Duty scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping duty scanner...")
+		scanner.Stop()
+
+		log.Info("Duty scanner stopped")
+	},
+}
+
+var epochScan = &cobra.Command{
+	Use:   "epoch-scanner",
+	Short: "Start the epoch summary scanner",
+	Long:  `Start the epoch scanner to summarize finalized epochs and store to database`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := epochscanner.NewEpochScanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Epoch scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping epoch scanner...")
+		scanner.Stop()
+
+		log.Info("Epoch scanner stopped")
+	},
+}
+
+var finalityScan = &cobra.Command{
+	Use:   "finality-scanner",
+	Short: "Start the finality tracker",
+	Long:  `Start the finality scanner to record checkpoints, mark finalized blocks and alert on finality stalls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := finalityscanner.NewFinalityScanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Finality scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping finality scanner...")
+		scanner.Stop()
+
+		log.Info("Finality scanner stopped")
+	},
+}
+
+var eth1Scan = &cobra.Command{
+	Use:   "eth1-scanner",
+	Short: "Start the execution block scanner",
+	Long:  `Start the eth1 scanner to index execution blocks, transactions, receipts and logs`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := eth1scanner.NewEth1Scanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Eth1 scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping eth1 scanner...")
+		scanner.Stop()
+
+		log.Info("Eth1 scanner stopped")
+	},
+}
+
+var depositScan = &cobra.Command{
+	Use:   "deposit-scanner",
+	Short: "Start the deposit contract scanner",
+	Long:  `Start the deposit scanner to index deposit logs, verify deposit roots and link deposits to blocks and validators`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := depositscanner.NewDepositScanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Deposit scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping deposit scanner...")
+		scanner.Stop()
+
+		log.Info("Deposit scanner stopped")
+	},
+}
+
+var payloadLink = &cobra.Command{
+	Use:   "payload-linker",
+	Short: "Start the execution payload linker",
+	Long:  `Start the payload linker to join beacon blocks with indexed execution blocks and flag inconsistencies`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := payloadlinker.NewPayloadLinker(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Payload linker failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping payload linker...")
+		scanner.Stop()
+
+		log.Info("Payload linker stopped")
+	},
+}
+
+var mevScan = &cobra.Command{
+	Use:   "mev-scanner",
+	Short: "Start the mev-boost relay scanner",
+	Long:  `Start the mev scanner to ingest relay delivered payloads and attribute blocks to builders and relays`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := mevscanner.NewMevScanner(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Mev scanner failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping mev scanner...")
+		scanner.Stop()
+
+		log.Info("Mev scanner stopped")
+	},
+}
+
+var watchMonitor = &cobra.Command{
+	Use:   "watch-monitor",
+	Short: "Start the validator watchlist monitor",
+	Long:  `Start the watch monitor to notify missed duties, balance decreases and slashings of watched validators`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := watchmonitor.NewWatchMonitor(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Watch monitor failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping watch monitor...")
+		scanner.Stop()
+
+		log.Info("Watch monitor stopped")
+	},
+}
+
+var webhookSender = &cobra.Command{
+	Use:   "webhook-sender",
+	Short: "Start the webhook sender",
+	Long:  `Start the webhook sender to deliver the queued webhook events with retries`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration
+		cfg := config.Load()
+
+		// Initialize logger
+		log := logger.Init(cfg.Log.Level)
+
+		// Initialize database
+		db, err := database.Init(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+
+		// Initialize Redis
+		rdb, err := redis.Init(cfg.Redis)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis: %v", err)
+		}
+
+		// Handle graceful shutdown
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+		scanner := webhooksender.NewWebhookSender(cfg, db, rdb, log)
+
+		go func() {
+			if err := scanner.Start(); err != nil {
+				log.Fatalf("Webhook sender failed: %v", err)
+			}
+		}()
+
+		<-sigChan
+		log.Info("Received shutdown signal, stopping webhook sender...")
+		scanner.Stop()
+
+		log.Info("Webhook sender stopped")
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(blockScanner)
 	rootCmd.AddCommand(directScan)
+	rootCmd.AddCommand(validatorScan)
+	rootCmd.AddCommand(balanceSample)
+	rootCmd.AddCommand(dutyScan)
+	rootCmd.AddCommand(epochScan)
+	rootCmd.AddCommand(finalityScan)
+	rootCmd.AddCommand(eth1Scan)
+	rootCmd.AddCommand(depositScan)
+	rootCmd.AddCommand(payloadLink)
+	rootCmd.AddCommand(mevScan)
+	rootCmd.AddCommand(watchMonitor)
+	rootCmd.AddCommand(webhookSender)
 }
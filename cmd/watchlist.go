@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/internal/database"
+	"github.com/xueqianLu/deep-dive-beacon/internal/logger"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"strconv"
+)
+
+var watchLabel string
+
+var watchlistCmd = &cobra.Command{
+	Use:   "watchlist",
+	Short: "Manage the validator watchlist",
+	Long:  `Add, remove or list the validators watched by the watch monitor`,
+}
+
+var watchlistAdd = &cobra.Command{
+	Use:   "add <validator_index>...",
+	Short: "Watch validators",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, log := watchlistService()
+		for _, arg := range args {
+			index, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid validator index %s", arg)
+			}
+			if _, err := svc.Add(index, watchLabel); err != nil {
+				log.Fatalf("Failed to watch validator %d: %v", index, err)
+			}
+			fmt.Printf("watching validator %d\n", index)
+		}
+	},
+}
+
+var watchlistRemove = &cobra.Command{
+	Use:   "remove <validator_index>...",
+	Short: "Stop watching validators",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, log := watchlistService()
+		for _, arg := range args {
+			index, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid validator index %s", arg)
+			}
+			removed, err := svc.Remove(index)
+			if err != nil {
+				log.Fatalf("Failed to remove validator %d: %v", index, err)
+			}
+			if !removed {
+				fmt.Printf("validator %d is not watched\n", index)
+				continue
+			}
+			fmt.Printf("removed validator %d\n", index)
+		}
+	},
+}
+
+var watchlistList = &cobra.Command{
+	Use:   "list",
+	Short: "List watched validators",
+	Run: func(cmd *cobra.Command, args []string) {
+		svc, log := watchlistService()
+		watched, err := svc.List()
+		if err != nil {
+			log.Fatalf("Failed to list watchlist: %v", err)
+		}
+		for _, w := range watched {
+			fmt.Printf("%d\t%s\tslashed=%v\n", w.ValidatorIndex, w.Label, w.Slashed)
+		}
+	},
+}
+
+func watchlistService() (*services.WatchlistService, *logrus.Logger) {
+	cfg := config.Load()
+	log := logger.Init(cfg.Log.Level)
+
+	db, err := database.Init(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	return services.NewWatchlistService(db, nil, log), log
+}
+
+func init() {
+	watchlistAdd.Flags().StringVar(&watchLabel, "label", "", "Label of the watched validators")
+	watchlistCmd.AddCommand(watchlistAdd)
+	watchlistCmd.AddCommand(watchlistRemove)
+	watchlistCmd.AddCommand(watchlistList)
+	rootCmd.AddCommand(watchlistCmd)
+}
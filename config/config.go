@@ -10,6 +10,12 @@ type Config struct {
 	Redis    RedisConfig    `mapstructure:"redis"`
 	Log      LogConfig      `mapstructure:"log"`
 	Chain    ChainConfig    `mapstructure:"chain"`
+	Indexer  IndexerConfig  `mapstructure:"indexer"`
+	Mev      MevConfig      `mapstructure:"mev"`
+	Notify   NotifyConfig   `mapstructure:"notify"`
+	Stream   StreamConfig   `mapstructure:"stream"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+	GraphQL  GraphQLConfig  `mapstructure:"graphql"`
 }
 
 type ServerConfig struct {
@@ -17,6 +23,15 @@ type ServerConfig struct {
 	Port         string `mapstructure:"port"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	// CorsOrigins are the origins allowed to call the API with credentials, any origin is
+	// allowed without credentials if empty.
+	CorsOrigins []string `mapstructure:"cors_origins"`
+	// TrustedProxies are the proxy addresses or CIDRs whose forwarded headers give the client
+	// ip, used by the rate limit. No proxy is trusted if empty.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// SavedApiBlocks is the most finalized Beacon API blocks saved to be served again without
+	// the beacon node, all of them are kept if 0.
+	SavedApiBlocks int `mapstructure:"saved_api_blocks"`
 }
 
 type DatabaseConfig struct {
@@ -42,6 +57,76 @@ type LogConfig struct {
 type ChainConfig struct {
 	BeaconURL string `mapstructure:"beacon_url"`
 	GethUrl   string `mapstructure:"geth_url"`
+	// DepositContract is the address of the deposit contract on the execution chain.
+	DepositContract string `mapstructure:"deposit_contract"`
+}
+
+type IndexerConfig struct {
+	// ValidatorSnapshotInterval is the number of epochs between two validator registry snapshots.
+	ValidatorSnapshotInterval uint64 `mapstructure:"validator_snapshot_interval"`
+	// BalanceSampleInterval is the number of epochs between two validator balance samples, 225
+	// epochs is about one day on mainnet.
+	BalanceSampleInterval uint64 `mapstructure:"balance_sample_interval"`
+	// FinalityStallEpochs raise an alert when head is more epochs than this ahead of the finalized checkpoint.
+	FinalityStallEpochs uint64 `mapstructure:"finality_stall_epochs"`
+}
+
+type RelayConfig struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+}
+
+type MevConfig struct {
+	// Relays are the relay data api endpoints polled for delivered payloads.
+	Relays []RelayConfig `mapstructure:"relays"`
+	// DumpDir hold delivered payload json dumps, one file per relay named <relay>.json.
+	DumpDir string `mapstructure:"dump_dir"`
+}
+
+type NotifyConfig struct {
+	// Notifiers are the names of the enabled notifiers, such as "log" or "webhook".
+	Notifiers []string `mapstructure:"notifiers"`
+	// Webhooks receive the events queued by the webhook notifier.
+	Webhooks []WebhookConfig `mapstructure:"webhooks"`
+	// MaxAttempts bound the deliveries of one event before it is given up.
+	MaxAttempts int `mapstructure:"max_attempts"`
+}
+
+type WebhookConfig struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+	// Secret sign the payload with HMAC-SHA256, no signature header when empty.
+	Secret string `mapstructure:"secret"`
+	// Events filter the event types sent to the webhook, all events when empty.
+	Events []string `mapstructure:"events"`
+}
+
+type StreamConfig struct {
+	// Enabled publish indexed data to redis streams.
+	Enabled bool `mapstructure:"enabled"`
+	// Prefix of the stream keys, such as "beacon" for "beacon:blocks".
+	Prefix string `mapstructure:"prefix"`
+	// MaxLen cap every stream to about this many entries.
+	MaxLen int64 `mapstructure:"max_len"`
+}
+
+type AuthConfig struct {
+	// RequireKey reject requests without an API key, else anonymous requests may read.
+	RequireKey bool `mapstructure:"require_key"`
+	// AnonymousRateLimit is the requests per minute allowed per client ip without API key.
+	AnonymousRateLimit int `mapstructure:"anonymous_rate_limit"`
+	// DefaultRateLimit is the requests per minute allowed per API key without its own limit.
+	DefaultRateLimit int `mapstructure:"default_rate_limit"`
+	// KeyIPRateLimit is the requests per minute allowed per client ip for the requests presenting
+	// a key, it only bound key guessing and should exceed the limit of any key.
+	KeyIPRateLimit int `mapstructure:"key_ip_rate_limit"`
+}
+
+type GraphQLConfig struct {
+	// MaxComplexity bound the objects a query may resolve, list fields count their maximum length.
+	MaxComplexity int `mapstructure:"max_complexity"`
+	// MaxDepth bound the nesting of the query selections.
+	MaxDepth int `mapstructure:"max_depth"`
 }
 
 func Load() *Config {
@@ -52,6 +137,12 @@ func Load() *Config {
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
+	viper.SetDefault("server.saved_api_blocks", 100000)
+	viper.SetDefault("auth.anonymous_rate_limit", 60)
+	viper.SetDefault("auth.default_rate_limit", 600)
+	viper.SetDefault("auth.key_ip_rate_limit", 6000)
+	viper.SetDefault("graphql.max_complexity", 5000)
+	viper.SetDefault("graphql.max_depth", 8)
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.ssl_mode", "disable")
@@ -59,6 +150,15 @@ func Load() *Config {
 	viper.SetDefault("redis.port", 6379)
 	viper.SetDefault("redis.database", 0)
 	viper.SetDefault("log.level", "info")
+	viper.SetDefault("indexer.validator_snapshot_interval", 225)
+	viper.SetDefault("indexer.balance_sample_interval", 225)
+	viper.SetDefault("indexer.finality_stall_epochs", 4)
+	viper.SetDefault("notify.notifiers", []string{"log"})
+	viper.SetDefault("notify.max_attempts", 8)
+	viper.SetDefault("stream.enabled", true)
+	viper.SetDefault("stream.prefix", "beacon")
+	viper.SetDefault("stream.max_len", 100000)
+	viper.SetDefault("chain.deposit_contract", "0x00000000219ab540356cBB839Cbe05303d7705Fa")
 
 	if err := viper.Unmarshal(&config); err != nil {
 		panic(err)
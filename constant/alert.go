@@ -0,0 +1,7 @@
+package constant
+
+const (
+	ALERT_FINALITY_STALL     = "finality_stall"
+	ALERT_FINALITY_RECOVERED = "finality_recovered"
+	ALERT_DEPOSIT_ROOT       = "deposit_root_mismatch"
+)
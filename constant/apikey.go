@@ -0,0 +1,6 @@
+package constant
+
+const (
+	API_SCOPE_READ  = "read"
+	API_SCOPE_ADMIN = "admin"
+)
@@ -0,0 +1,8 @@
+package constant
+
+const (
+	DUTY_STATUS_PENDING   = "pending"
+	DUTY_STATUS_FULFILLED = "fulfilled"
+	DUTY_STATUS_LATE      = "late"
+	DUTY_STATUS_MISSED    = "missed"
+)
@@ -0,0 +1,18 @@
+package constant
+
+const (
+	EVENT_MISSED_ATTESTATION = "missed_attestation"
+	EVENT_MISSED_PROPOSAL    = "missed_proposal"
+	EVENT_MISSED_SYNC        = "missed_sync"
+	EVENT_BALANCE_DECREASE   = "balance_decrease"
+	EVENT_SLASHED            = "slashed"
+	EVENT_FINALIZED_EPOCH    = "finalized_epoch"
+	EVENT_REORG              = "reorg"
+	EVENT_TASK_FAILED        = "task_failed"
+)
+
+const (
+	OUTBOX_STATUS_PENDING   = "pending"
+	OUTBOX_STATUS_DELIVERED = "delivered"
+	OUTBOX_STATUS_FAILED    = "failed"
+)
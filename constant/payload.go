@@ -0,0 +1,7 @@
+package constant
+
+const (
+	PAYLOAD_STATUS_LINKED   = "linked"
+	PAYLOAD_STATUS_MISSING  = "missing"
+	PAYLOAD_STATUS_MISMATCH = "mismatch"
+)
@@ -3,4 +3,14 @@ package constant
 const (
 	SCAN_TYPE_BEACON_BLOCK          = "beacon_block"
 	DIRECTLY_SCAN_TYPE_BEACON_BLOCK = "directly_beacon_block"
+	SCAN_TYPE_VALIDATOR_SNAPSHOT    = "validator_snapshot"
+	SCAN_TYPE_VALIDATOR_BALANCE     = "validator_balance"
+	SCAN_TYPE_DUTIES                = "duties"
+	SCAN_TYPE_EPOCH_SUMMARY         = "epoch_summary"
+	SCAN_TYPE_FINALITY              = "finality"
+	SCAN_TYPE_ETH1_BLOCK            = "eth1_block"
+	SCAN_TYPE_DEPOSIT               = "deposit"
+	SCAN_TYPE_PAYLOAD_LINK          = "payload_link"
+	SCAN_TYPE_MEV                   = "mev"
+	SCAN_TYPE_WATCHLIST             = "watchlist"
 )
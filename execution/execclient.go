@@ -0,0 +1,177 @@
+package execapi
+
+import (
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the node does not know the requested block.
+	ErrNotFound = errors.New("not found")
+
+	defaultTimeout = time.Second * 30
+	defaultRetries = 3
+	defaultBackoff = time.Millisecond * 500
+	// batchLimit keep batch requests below the default limit of geth and reth.
+	batchLimit = 100
+)
+
+type ExecutionClient struct {
+	endpoint string
+	rpc      *rpcClient
+}
+
+func NewExecutionClient(endpoint string) *ExecutionClient {
+	return &ExecutionClient{
+		endpoint: endpoint,
+		rpc:      newRPCClient(endpoint, defaultTimeout, defaultRetries, defaultBackoff),
+	}
+}
+
+func (e *ExecutionClient) ChainId() (uint64, error) {
+	var id Quantity
+	if err := e.rpc.call(context.Background(), &id, "eth_chainId"); err != nil {
+		log.WithError(err).Error("get chain id failed")
+		return 0, err
+	}
+	return id.Uint64(), nil
+}
+
+func (e *ExecutionClient) BlockNumber() (uint64, error) {
+	var number Quantity
+	if err := e.rpc.call(context.Background(), &number, "eth_blockNumber"); err != nil {
+		log.WithError(err).Error("get latest block number failed")
+		return 0, err
+	}
+	return number.Uint64(), nil
+}
+
+// GetBlockByNumber return the block, with full transactions if fullTx is set.
+func (e *ExecutionClient) GetBlockByNumber(number uint64, fullTx bool) (*Block, error) {
+	var block *Block
+	if err := e.rpc.call(context.Background(), &block, "eth_getBlockByNumber", Quantity(number), fullTx); err != nil {
+		log.WithError(err).WithField("number", number).Error("get block by number failed")
+		return nil, err
+	}
+	if block == nil {
+		return nil, ErrNotFound
+	}
+	return block, nil
+}
+
+func (e *ExecutionClient) GetBlockByHash(hash string, fullTx bool) (*Block, error) {
+	var block *Block
+	if err := e.rpc.call(context.Background(), &block, "eth_getBlockByHash", hash, fullTx); err != nil {
+		log.WithError(err).WithField("hash", hash).Error("get block by hash failed")
+		return nil, err
+	}
+	if block == nil {
+		return nil, ErrNotFound
+	}
+	return block, nil
+}
+
+// GetBlocksByNumber fetch a range of blocks with batched requests, missing blocks are skipped.
+func (e *ExecutionClient) GetBlocksByNumber(numbers []uint64, fullTx bool) ([]*Block, error) {
+	blocks := make([]*Block, len(numbers))
+	batch := make([]*BatchElem, len(numbers))
+	for i, number := range numbers {
+		batch[i] = &BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{Quantity(number), fullTx},
+			Result: &blocks[i],
+		}
+	}
+	if err := e.batch(batch); err != nil {
+		return nil, err
+	}
+	result := make([]*Block, 0, len(blocks))
+	for _, block := range blocks {
+		if block != nil {
+			result = append(result, block)
+		}
+	}
+	return result, nil
+}
+
+// GetBlockReceipts return all receipts of the block. Nodes without eth_getBlockReceipts
+// are served by batching eth_getTransactionReceipt.
+func (e *ExecutionClient) GetBlockReceipts(block *Block) ([]*Receipt, error) {
+	var receipts []*Receipt
+	err := e.rpc.call(context.Background(), &receipts, "eth_getBlockReceipts", block.Hash)
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return e.GetTransactionReceipts(block.TransactionHashes)
+	}
+	if err != nil {
+		log.WithError(err).WithField("block", block.Hash).Error("get block receipts failed")
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (e *ExecutionClient) GetTransactionReceipts(hashes []string) ([]*Receipt, error) {
+	receipts := make([]*Receipt, len(hashes))
+	batch := make([]*BatchElem, len(hashes))
+	for i, hash := range hashes {
+		batch[i] = &BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+	if err := e.batch(batch); err != nil {
+		return nil, err
+	}
+	for i, receipt := range receipts {
+		if receipt == nil {
+			log.WithField("tx", hashes[i]).Error("transaction receipt not found")
+			return nil, ErrNotFound
+		}
+	}
+	return receipts, nil
+}
+
+func (e *ExecutionClient) GetLogs(filter LogFilter) ([]*Log, error) {
+	var logs []*Log
+	if err := e.rpc.call(context.Background(), &logs, "eth_getLogs", filter); err != nil {
+		log.WithError(err).Error("get logs failed")
+		return nil, err
+	}
+	return logs, nil
+}
+
+// TraceBlock return the call traces of every transaction of the block. It needs the debug
+// namespace enabled on the node, so callers should treat errors as optional data.
+func (e *ExecutionClient) TraceBlock(number uint64) ([]*TxTrace, error) {
+	var traces []*TxTrace
+	tracer := map[string]interface{}{"tracer": "callTracer"}
+	if err := e.rpc.call(context.Background(), &traces, "debug_traceBlockByNumber", Quantity(number), tracer); err != nil {
+		log.WithError(err).WithField("number", number).Debug("trace block failed")
+		return nil, err
+	}
+	return traces, nil
+}
+
+// batch send the requests in chunks of batchLimit and return the first request error.
+func (e *ExecutionClient) batch(elems []*BatchElem) error {
+	for start := 0; start < len(elems); start += batchLimit {
+		end := start + batchLimit
+		if end > len(elems) {
+			end = len(elems)
+		}
+		if err := e.rpc.batchCall(context.Background(), elems[start:end]); err != nil {
+			log.WithError(err).Error("batch call failed")
+			return err
+		}
+	}
+	for _, elem := range elems {
+		if elem.Error != nil {
+			log.WithError(elem.Error).WithField("method", elem.Method).Error("batch request failed")
+			return elem.Error
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package execapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient serve every JSON-RPC request with handle, batch requests are split into single calls.
+func newTestClient(t *testing.T, handle func(method string, params []json.RawMessage) (interface{}, *RPCError)) *ExecutionClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		type request struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		respond := func(req request) map[string]interface{} {
+			result, rpcErr := handle(req.Method, req.Params)
+			resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+			if rpcErr != nil {
+				resp["error"] = rpcErr
+			} else {
+				resp["result"] = result
+			}
+			return resp
+		}
+		if body[0] == '[' {
+			var reqs []request
+			require.NoError(t, json.Unmarshal(body, &reqs))
+			resps := make([]interface{}, 0, len(reqs))
+			for _, req := range reqs {
+				resps = append(resps, respond(req))
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req request
+		require.NoError(t, json.Unmarshal(body, &req))
+		json.NewEncoder(w).Encode(respond(req))
+	}))
+	t.Cleanup(server.Close)
+	client := NewExecutionClient(server.URL)
+	client.rpc.backoff = time.Millisecond
+	return client
+}
+
+func TestGetBlockByNumber(t *testing.T) {
+	client := newTestClient(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		assert.Equal(t, "eth_getBlockByNumber", method)
+		assert.JSONEq(t, `"0x10"`, string(params[0]))
+		return map[string]interface{}{
+			"hash":          "0xaa",
+			"parentHash":    "0xbb",
+			"number":        "0x10",
+			"gasUsed":       "0x5208",
+			"baseFeePerGas": "0x3b9aca00",
+			"transactions": []interface{}{
+				map[string]interface{}{"hash": "0x01", "value": "0xde0b6b3a7640000", "nonce": "0x1"},
+			},
+		}, nil
+	})
+	block, err := client.GetBlockByNumber(16, true)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16), block.Number.Uint64())
+	assert.Equal(t, uint64(21000), block.GasUsed.Uint64())
+	assert.Equal(t, "1000000000", block.BaseFeePerGas.String())
+	require.Len(t, block.Transactions, 1)
+	assert.Equal(t, "1000000000000000000", block.Transactions[0].Value.String())
+	assert.Equal(t, []string{"0x01"}, block.TransactionHashes)
+}
+
+func TestGetBlockByNumberNotFound(t *testing.T) {
+	client := newTestClient(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		return nil, nil
+	})
+	_, err := client.GetBlockByNumber(1, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetBlockReceiptsFallback(t *testing.T) {
+	client := newTestClient(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		switch method {
+		case "eth_getBlockReceipts":
+			return nil, &RPCError{Code: -32601, Message: "method not found"}
+		case "eth_getTransactionReceipt":
+			var hash string
+			json.Unmarshal(params[0], &hash)
+			return map[string]interface{}{"transactionHash": hash, "status": "0x1"}, nil
+		}
+		return nil, &RPCError{Code: -32601, Message: "unexpected " + method}
+	})
+	receipts, err := client.GetBlockReceipts(&Block{Hash: "0xaa", TransactionHashes: []string{"0x01", "0x02"}})
+	require.NoError(t, err)
+	require.Len(t, receipts, 2)
+	assert.Equal(t, "0x02", receipts[1].TransactionHash)
+	assert.Equal(t, uint64(1), receipts[1].Status.Uint64())
+}
+
+func TestRetryOnHTTPError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var req struct {
+			ID uint64 `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x2a"})
+	}))
+	defer server.Close()
+	client := NewExecutionClient(server.URL)
+	client.rpc.backoff = time.Millisecond
+
+	number, err := client.BlockNumber()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), number)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,171 @@
+package execapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RPCError is an error returned by the node in the JSON-RPC response.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// BatchElem is one request of a batch call, Result must be a pointer. Error is set
+// when this request failed while the batch itself succeeded.
+type BatchElem struct {
+	Method string
+	Args   []interface{}
+	Result interface{}
+	Error  error
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// rpcClient is a minimal JSON-RPC over http client with retries on transport errors.
+type rpcClient struct {
+	endpoint string
+	http     *http.Client
+	retries  int
+	backoff  time.Duration
+	nextID   uint64
+}
+
+func newRPCClient(endpoint string, timeout time.Duration, retries int, backoff time.Duration) *rpcClient {
+	return &rpcClient{
+		endpoint: endpoint,
+		http:     &http.Client{Timeout: timeout},
+		retries:  retries,
+		backoff:  backoff,
+	}
+}
+
+func (c *rpcClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	elem := &BatchElem{Method: method, Args: args, Result: result}
+	if err := c.batchCall(ctx, []*BatchElem{elem}); err != nil {
+		return err
+	}
+	return elem.Error
+}
+
+func (c *rpcClient) batchCall(ctx context.Context, batch []*BatchElem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	reqs := make([]*rpcRequest, len(batch))
+	byID := make(map[uint64]*BatchElem, len(batch))
+	for i, elem := range batch {
+		args := elem.Args
+		if args == nil {
+			args = []interface{}{}
+		}
+		reqs[i] = &rpcRequest{
+			JSONRPC: "2.0",
+			ID:      atomic.AddUint64(&c.nextID, 1),
+			Method:  elem.Method,
+			Params:  args,
+		}
+		byID[reqs[i].ID] = elem
+	}
+	var body []byte
+	var err error
+	if len(reqs) == 1 {
+		body, err = json.Marshal(reqs[0])
+	} else {
+		body, err = json.Marshal(reqs)
+	}
+	if err != nil {
+		return err
+	}
+
+	var resps []*rpcResponse
+	for attempt := 0; ; attempt++ {
+		resps, err = c.post(ctx, body)
+		if err == nil || attempt >= c.retries || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.backoff * time.Duration(1<<attempt)):
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, resp := range resps {
+		elem, exist := byID[resp.ID]
+		if !exist {
+			continue
+		}
+		delete(byID, resp.ID)
+		switch {
+		case resp.Error != nil:
+			elem.Error = resp.Error
+		case elem.Result != nil:
+			elem.Error = json.Unmarshal(resp.Result, elem.Result)
+		}
+	}
+	for _, elem := range byID {
+		elem.Error = errors.New("missing response for batch request")
+	}
+	return nil
+}
+
+// post send the encoded request and decode single or batch responses.
+func (c *rpcClient) post(ctx context.Context, body []byte) ([]*rpcResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rpc http status %d: %s", res.StatusCode, bytes.TrimSpace(data))
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var resps []*rpcResponse
+		if err := json.Unmarshal(data, &resps); err != nil {
+			return nil, err
+		}
+		return resps, nil
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return []*rpcResponse{&resp}, nil
+}
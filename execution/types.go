@@ -0,0 +1,220 @@
+package execapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a hex encoded unsigned integer as used by the JSON-RPC api.
+type Quantity uint64
+
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*q = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quantity %q: %w", s, err)
+	}
+	*q = Quantity(v)
+	return nil
+}
+
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("0x%x", uint64(q)))
+}
+
+func (q Quantity) Uint64() uint64 {
+	return uint64(q)
+}
+
+// BigInt is a hex encoded big integer, such as wei values.
+type BigInt struct {
+	big.Int
+}
+
+func (b *BigInt) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" || s == "0x" {
+		b.SetUint64(0)
+		return nil
+	}
+	if _, ok := b.SetString(strings.TrimPrefix(s, "0x"), 16); !ok {
+		return fmt.Errorf("invalid big integer %q", s)
+	}
+	return nil
+}
+
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal("0x" + b.Text(16))
+}
+
+// Block is an execution block, Transactions is filled only when requested with full transactions,
+// otherwise TransactionHashes is.
+type Block struct {
+	Hash              string         `json:"hash"`
+	ParentHash        string         `json:"parentHash"`
+	Number            Quantity       `json:"number"`
+	Timestamp         Quantity       `json:"timestamp"`
+	Miner             string         `json:"miner"`
+	StateRoot         string         `json:"stateRoot"`
+	TransactionsRoot  string         `json:"transactionsRoot"`
+	ReceiptsRoot      string         `json:"receiptsRoot"`
+	LogsBloom         string         `json:"logsBloom"`
+	ExtraData         string         `json:"extraData"`
+	GasLimit          Quantity       `json:"gasLimit"`
+	GasUsed           Quantity       `json:"gasUsed"`
+	BaseFeePerGas     *BigInt        `json:"baseFeePerGas"`
+	BlobGasUsed       *Quantity      `json:"blobGasUsed"`
+	ExcessBlobGas     *Quantity      `json:"excessBlobGas"`
+	WithdrawalsRoot   string         `json:"withdrawalsRoot"`
+	ParentBeaconRoot  string         `json:"parentBeaconBlockRoot"`
+	RequestsHash      string         `json:"requestsHash"`
+	Size              Quantity       `json:"size"`
+	Transactions      []*Transaction `json:"-"`
+	TransactionHashes []string       `json:"-"`
+	Withdrawals       []*Withdrawal  `json:"withdrawals"`
+}
+
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	var raw struct {
+		block
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = Block(raw.block)
+	for _, item := range raw.Transactions {
+		if len(item) > 0 && item[0] == '"' {
+			var hash string
+			if err := json.Unmarshal(item, &hash); err != nil {
+				return err
+			}
+			b.TransactionHashes = append(b.TransactionHashes, hash)
+			continue
+		}
+		tx := new(Transaction)
+		if err := json.Unmarshal(item, tx); err != nil {
+			return err
+		}
+		b.Transactions = append(b.Transactions, tx)
+		b.TransactionHashes = append(b.TransactionHashes, tx.Hash)
+	}
+	return nil
+}
+
+type Withdrawal struct {
+	Index          Quantity `json:"index"`
+	ValidatorIndex Quantity `json:"validatorIndex"`
+	Address        string   `json:"address"`
+	Amount         Quantity `json:"amount"`
+}
+
+type Transaction struct {
+	Hash                 string    `json:"hash"`
+	BlockHash            string    `json:"blockHash"`
+	BlockNumber          Quantity  `json:"blockNumber"`
+	TransactionIndex     Quantity  `json:"transactionIndex"`
+	Type                 Quantity  `json:"type"`
+	From                 string    `json:"from"`
+	To                   string    `json:"to"`
+	Nonce                Quantity  `json:"nonce"`
+	Value                BigInt    `json:"value"`
+	Gas                  Quantity  `json:"gas"`
+	GasPrice             *BigInt   `json:"gasPrice"`
+	MaxFeePerGas         *BigInt   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *BigInt   `json:"maxPriorityFeePerGas"`
+	MaxFeePerBlobGas     *BigInt   `json:"maxFeePerBlobGas"`
+	BlobVersionedHashes  []string  `json:"blobVersionedHashes"`
+	Input                string    `json:"input"`
+	ChainId              *Quantity `json:"chainId"`
+}
+
+type Receipt struct {
+	TransactionHash   string    `json:"transactionHash"`
+	TransactionIndex  Quantity  `json:"transactionIndex"`
+	BlockHash         string    `json:"blockHash"`
+	BlockNumber       Quantity  `json:"blockNumber"`
+	From              string    `json:"from"`
+	To                string    `json:"to"`
+	ContractAddress   string    `json:"contractAddress"`
+	Type              Quantity  `json:"type"`
+	Status            Quantity  `json:"status"`
+	GasUsed           Quantity  `json:"gasUsed"`
+	CumulativeGasUsed Quantity  `json:"cumulativeGasUsed"`
+	EffectiveGasPrice *BigInt   `json:"effectiveGasPrice"`
+	BlobGasUsed       *Quantity `json:"blobGasUsed"`
+	BlobGasPrice      *BigInt   `json:"blobGasPrice"`
+	Logs              []*Log    `json:"logs"`
+}
+
+type Log struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      Quantity `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex Quantity `json:"transactionIndex"`
+	LogIndex         Quantity `json:"logIndex"`
+	Removed          bool     `json:"removed"`
+}
+
+// LogFilter select logs by block range or block hash, address and topics.
+type LogFilter struct {
+	FromBlock uint64
+	ToBlock   uint64
+	BlockHash string
+	Addresses []string
+	Topics    [][]string
+}
+
+func (f LogFilter) MarshalJSON() ([]byte, error) {
+	arg := map[string]interface{}{}
+	if f.BlockHash != "" {
+		arg["blockHash"] = f.BlockHash
+	} else {
+		arg["fromBlock"] = Quantity(f.FromBlock)
+		arg["toBlock"] = Quantity(f.ToBlock)
+	}
+	if len(f.Addresses) > 0 {
+		arg["address"] = f.Addresses
+	}
+	if len(f.Topics) > 0 {
+		arg["topics"] = f.Topics
+	}
+	return json.Marshal(arg)
+}
+
+// CallTrace is one frame of the callTracer output.
+type CallTrace struct {
+	Type    string       `json:"type"`
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Value   *BigInt      `json:"value"`
+	Gas     Quantity     `json:"gas"`
+	GasUsed Quantity     `json:"gasUsed"`
+	Input   string       `json:"input"`
+	Output  string       `json:"output"`
+	Error   string       `json:"error"`
+	Calls   []*CallTrace `json:"calls"`
+}
+
+// TxTrace is the call trace of one transaction of a block.
+type TxTrace struct {
+	TxHash string     `json:"txHash"`
+	Result *CallTrace `json:"result"`
+}
@@ -0,0 +1,106 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// maxBlockAttestations is the most attestations a block may include.
+	maxBlockAttestations = 128
+	slotsPerEpoch        = 32
+	defaultRewardDays    = 7
+	maxRewardDays        = 365
+)
+
+type budgetKey struct{}
+
+// budget is the complexity left to the root fields of a request.
+type budget struct {
+	max  int64
+	used atomic.Int64
+}
+
+func withBudget(ctx context.Context, max int) context.Context {
+	return context.WithValue(ctx, budgetKey{}, &budget{max: int64(max)})
+}
+
+// charge take the cost of the current root field from the budget of the request, size is the
+// number of objects the root field return, or the page size of its nodes.
+func charge(ctx context.Context, size int) error {
+	b, _ := ctx.Value(budgetKey{}).(*budget)
+	if b == nil || b.max <= 0 {
+		return nil
+	}
+	cost := queryCost(ctx, size)
+	if used := b.used.Add(int64(cost)); used > b.max {
+		return fmt.Errorf("query too complex, cost %d exceed the limit %d", used, b.max)
+	}
+	return nil
+}
+
+// queryCost return the number of objects the selection of the current root field may resolve.
+// Scalar fields are free, list fields multiply the cost of their selection by their maximum
+// length.
+func queryCost(ctx context.Context, size int) int {
+	paths := graphql.SelectedFieldNames(ctx)
+	parents := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if i := strings.LastIndexByte(path, '.'); i >= 0 {
+			parents[path[:i]] = true
+		}
+	}
+	cost := size
+	for _, path := range paths {
+		// the nodes of a page are counted by size.
+		if !parents[path] || path == "nodes" {
+			continue
+		}
+		n := size
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			n = saturatedMul(n, listSize(ctx, strings.Join(names[:i+1], "."), name))
+		}
+		cost = saturatedAdd(cost, n)
+	}
+	return cost
+}
+
+// listSize return the maximum length of the field at path, 1 for objects.
+func listSize(ctx context.Context, path string, name string) int {
+	switch name {
+	case "attestations":
+		return maxBlockAttestations
+	case "blocks":
+		return slotsPerEpoch
+	case "rewards":
+		// one daily rollup per day.
+		var args struct{ Days int32 }
+		if ok, err := graphql.DecodeSelectedFieldArgs(ctx, path, &args); ok && err == nil {
+			if days := int(args.Days); days > 0 && days <= maxRewardDays {
+				return days
+			}
+			return maxRewardDays
+		}
+		return defaultRewardDays
+	}
+	return 1
+}
+
+func saturatedMul(a, b int) int {
+	if a != 0 && b > (1<<40)/a {
+		return 1 << 40
+	}
+	return a * b
+}
+
+func saturatedAdd(a, b int) int {
+	if a+b > 1<<40 {
+		return 1 << 40
+	}
+	return a + b
+}
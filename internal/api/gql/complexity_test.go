@@ -0,0 +1,78 @@
+package gql
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func newTestExecutor(cfg config.GraphQLConfig) *Executor {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	// the services are not reached, the queries are rejected first.
+	return NewExecutor(&services.Services{}, cfg, logger)
+}
+
+func TestQueryComplexity(t *testing.T) {
+	e := newTestExecutor(config.GraphQLConfig{MaxComplexity: 100})
+	for query, message := range map[string]string{
+		// 1 block, 128 attestations, 1 proposer and 30 days of rewards.
+		`{ block(slot: 1) { slot attestations { slot } proposer { index rewards(days: 30) { total } } } }`: "query too complex, cost 160 exceed the limit 100",
+		// 100 blocks and their attestations.
+		`{ blocks(pageSize: 100) { nodes { slot attestations { slot } } } }`: "query too complex, cost 12900 exceed the limit 100",
+	} {
+		resp := e.Exec(context.Background(), &Request{Query: query})
+		require.Len(t, resp.Errors, 1, query)
+		assert.Equal(t, message, resp.Errors[0].Message, query)
+	}
+}
+
+func TestChargeBudget(t *testing.T) {
+	// every root field of a request share the budget.
+	ctx := withBudget(context.Background(), 100)
+	assert.NoError(t, charge(ctx, 60))
+	assert.EqualError(t, charge(ctx, 41), "query too complex, cost 101 exceed the limit 100")
+
+	// no limit.
+	ctx = withBudget(context.Background(), 0)
+	assert.NoError(t, charge(ctx, 1000000))
+}
+
+func TestQueryDepth(t *testing.T) {
+	e := newTestExecutor(config.GraphQLConfig{MaxDepth: 3})
+	resp := e.Exec(context.Background(), &Request{Query: `{ block(slot: 1) { attestations { inclusionBlock { slot } } } }`})
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "depth")
+}
+
+func TestQueryArguments(t *testing.T) {
+	e := newTestExecutor(config.GraphQLConfig{})
+	ctx := WithAPIKey(context.Background(), &dbmodels.ApiKey{ID: 1, Scopes: constant.API_SCOPE_ADMIN})
+	for query, message := range map[string]string{
+		`{ block { slot } }`:                          "expect either slot or root",
+		`{ blocks(pageSize: 101) { total } }`:         "pageSize must be between 1 and 100",
+		`{ attestations(after: "x") { nextCursor } }`: "invalid after cursor",
+		`{ scanTasks(kind: "other") { id } }`:         "kind must be scan or direct",
+	} {
+		resp := e.Exec(ctx, &Request{Query: query})
+		require.Len(t, resp.Errors, 1, query)
+		assert.Equal(t, message, resp.Errors[0].Message, query)
+	}
+}
+
+func TestScanTasksRequireAdmin(t *testing.T) {
+	e := newTestExecutor(config.GraphQLConfig{})
+	for _, key := range []*dbmodels.ApiKey{nil, {ID: 1, Scopes: constant.API_SCOPE_READ}} {
+		resp := e.Exec(WithAPIKey(context.Background(), key), &Request{Query: `{ scanTasks { kind } }`})
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, errAdminRequired.Error(), resp.Errors[0].Message)
+	}
+}
@@ -0,0 +1,80 @@
+package gql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// loaderWait is how long a loader collect keys before fetching them in one query.
+	loaderWait     = 2 * time.Millisecond
+	loaderMaxBatch = 500
+)
+
+// fetchFunc load the values of many keys at once, keys without value are left out of the map.
+type fetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// loader batch the loads of one request: the resolvers of sibling fields run concurrently,
+// so their keys are collected for a short while and fetched together. Every key is fetched
+// at most once per request.
+type loader[K comparable, V any] struct {
+	fetch fetchFunc[K, V]
+
+	mux     sync.Mutex
+	batch   *loaderBatch[K, V]
+	batches map[K]*loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys    []K
+	once    sync.Once
+	done    chan struct{}
+	results map[K]V
+	err     error
+}
+
+func newLoader[K comparable, V any](fetch fetchFunc[K, V]) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, batches: make(map[K]*loaderBatch[K, V])}
+}
+
+// Load return the value of key, the zero value if there is none.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mux.Lock()
+	b, exist := l.batches[key]
+	if !exist {
+		if l.batch == nil {
+			next := &loaderBatch[K, V]{done: make(chan struct{})}
+			l.batch = next
+			time.AfterFunc(loaderWait, func() { l.dispatch(ctx, next) })
+		}
+		b = l.batch
+		b.keys = append(b.keys, key)
+		l.batches[key] = b
+		if len(b.keys) >= loaderMaxBatch {
+			l.batch = nil
+			go l.dispatch(ctx, b)
+		}
+	}
+	l.mux.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+	return b.results[key], b.err
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context, b *loaderBatch[K, V]) {
+	b.once.Do(func() {
+		l.mux.Lock()
+		if l.batch == b {
+			l.batch = nil
+		}
+		l.mux.Unlock()
+		b.results, b.err = l.fetch(ctx, b.keys)
+		close(b.done)
+	})
+}
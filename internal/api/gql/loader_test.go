@@ -0,0 +1,79 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderBatch(t *testing.T) {
+	var calls atomic.Int32
+	var fetched []int
+	l := newLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls.Add(1)
+		fetched = keys
+		results := make(map[int]string)
+		for _, key := range keys {
+			if key%2 == 0 {
+				results[key] = "even"
+			}
+		}
+		return results, nil
+	})
+
+	var wg sync.WaitGroup
+	values := make([]string, 6)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// keys 0..2 twice, duplicates are fetched once.
+			value, err := l.Load(context.Background(), i%3)
+			assert.NoError(t, err)
+			values[i] = value
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.ElementsMatch(t, []int{0, 1, 2}, fetched)
+	assert.Equal(t, []string{"even", "", "even", "even", "", "even"}, values)
+
+	// a loaded key is memoized.
+	value, err := l.Load(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "even", value)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestLoaderError(t *testing.T) {
+	l := newLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, errors.New("db down")
+	})
+	_, err := l.Load(context.Background(), 1)
+	assert.EqualError(t, err, "db down")
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	var calls atomic.Int32
+	l := newLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		calls.Add(1)
+		assert.LessOrEqual(t, len(keys), loaderMaxBatch)
+		return nil, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < loaderMaxBatch+1; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.Load(context.Background(), i)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,126 @@
+package gql
+
+import (
+	"context"
+	"time"
+
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+type loadersKey struct{}
+
+// rewardsKey identify the rewards of a validator over the last days.
+type rewardsKey struct {
+	index uint64
+	days  int
+}
+
+// rewards is the attestation and proposer rewards of a validator over some days.
+type rewards struct {
+	total int64
+	daily []*services.DailyReward
+}
+
+// loaders batch the queries of the nested fields of one request.
+type loaders struct {
+	validators   *loader[uint64, *dbmodels.Validator]
+	blocks       *loader[uint64, *dbmodels.BeaconBlock]
+	epochBlocks  *loader[uint64, []*dbmodels.BeaconBlock]
+	attestations *loader[uint64, []*dbmodels.BeaconAttestation]
+	mevs         *loader[uint64, *dbmodels.BlockMev]
+	rewards      *loader[rewardsKey, *rewards]
+}
+
+func newLoaders(svc *services.Services) *loaders {
+	return &loaders{
+		validators: newLoader(func(ctx context.Context, indices []uint64) (map[uint64]*dbmodels.Validator, error) {
+			vals, err := svc.Validator.GetValidatorsByIndices(indices)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[uint64]*dbmodels.Validator, len(vals))
+			for _, val := range vals {
+				results[val.ValidatorIndex] = val
+			}
+			return results, nil
+		}),
+		blocks: newLoader(func(ctx context.Context, slots []uint64) (map[uint64]*dbmodels.BeaconBlock, error) {
+			blocks, err := svc.BeaconBlock.GetBlocksBySlots(slots)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[uint64]*dbmodels.BeaconBlock, len(blocks))
+			for _, block := range blocks {
+				results[block.SlotNumber] = block
+			}
+			return results, nil
+		}),
+		epochBlocks: newLoader(func(ctx context.Context, epochs []uint64) (map[uint64][]*dbmodels.BeaconBlock, error) {
+			blocks, err := svc.BeaconBlock.GetBlocksByEpochs(epochs)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[uint64][]*dbmodels.BeaconBlock, len(epochs))
+			for _, block := range blocks {
+				results[block.EpochNumber] = append(results[block.EpochNumber], block)
+			}
+			return results, nil
+		}),
+		attestations: newLoader(func(ctx context.Context, slots []uint64) (map[uint64][]*dbmodels.BeaconAttestation, error) {
+			atts, err := svc.Attest.GetAttestationsBySlots(slots)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[uint64][]*dbmodels.BeaconAttestation, len(slots))
+			for _, att := range atts {
+				results[att.SlotNumber] = append(results[att.SlotNumber], att)
+			}
+			return results, nil
+		}),
+		mevs: newLoader(func(ctx context.Context, slots []uint64) (map[uint64]*dbmodels.BlockMev, error) {
+			mevs, err := svc.Mev.GetBlockMevs(slots)
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[uint64]*dbmodels.BlockMev, len(mevs))
+			for _, mev := range mevs {
+				results[mev.SlotNumber] = mev
+			}
+			return results, nil
+		}),
+		rewards: newLoader(func(ctx context.Context, keys []rewardsKey) (map[rewardsKey]*rewards, error) {
+			// one query per window, a request rarely ask for several.
+			byDays := make(map[int][]uint64)
+			for _, key := range keys {
+				byDays[key.days] = append(byDays[key.days], key.index)
+			}
+			now := time.Now()
+			results := make(map[rewardsKey]*rewards, len(keys))
+			for days, indices := range byDays {
+				dailies, err := svc.Balance.GetDailyRewardsOfValidators(indices, now.AddDate(0, 0, -days), now)
+				if err != nil {
+					return nil, err
+				}
+				byIndex := make(map[uint64][]*dbmodels.ValidatorRewardDaily)
+				for _, daily := range dailies {
+					byIndex[daily.ValidatorIndex] = append(byIndex[daily.ValidatorIndex], daily)
+				}
+				for _, index := range indices {
+					r := &rewards{}
+					r.daily, r.total = services.DailyRewards(byIndex[index])
+					results[rewardsKey{index: index, days: days}] = r
+				}
+			}
+			return results, nil
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
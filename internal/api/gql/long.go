@@ -0,0 +1,48 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Long is a 64 bits integer serialized as a string, javascript clients lose precision on
+// numbers above 2^53.
+type Long int64
+
+// ImplementsGraphQLType map Long to the Long scalar.
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+// UnmarshalGraphQL accept a string or an integer input.
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch v := input.(type) {
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Long %q", v)
+		}
+		*l = Long(n)
+	case int32:
+		*l = Long(v)
+	case int64:
+		*l = Long(v)
+	case float64:
+		*l = Long(v)
+	default:
+		return fmt.Errorf("invalid Long type %T", input)
+	}
+	return nil
+}
+
+func (l Long) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatInt(int64(l), 10)), nil
+}
+
+func longPtr(v *uint64) *Long {
+	if v == nil {
+		return nil
+	}
+	l := Long(*v)
+	return &l
+}
@@ -0,0 +1,351 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+const (
+	maxPageSize        = 100
+	maxAttestationPage = 1000
+	maxValidators      = 1000
+	maxQueryLength     = 16 * 1024
+)
+
+var (
+	errInternal      = errors.New("internal error")
+	errAdminRequired = errors.New("api key lacks the admin scope")
+)
+
+type apiKeyKey struct{}
+
+// WithAPIKey attach the API key of the request to ctx, nil for anonymous requests. The admin
+// fields are only resolved for keys granting the admin scope.
+func WithAPIKey(ctx context.Context, key *dbmodels.ApiKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, key)
+}
+
+// requireAdmin return an error unless the API key of the request grant the admin scope.
+func requireAdmin(ctx context.Context) error {
+	key, _ := ctx.Value(apiKeyKey{}).(*dbmodels.ApiKey)
+	if key == nil || !services.HasScope(key, constant.API_SCOPE_ADMIN) {
+		return errAdminRequired
+	}
+	return nil
+}
+
+// Request is the body of a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Executor run GraphQL queries over the indexed data.
+type Executor struct {
+	schema        *graphql.Schema
+	services      *services.Services
+	maxComplexity int
+}
+
+func NewExecutor(svc *services.Services, cfg config.GraphQLConfig, logger *logrus.Logger) *Executor {
+	opts := []graphql.SchemaOpt{graphql.MaxQueryLength(maxQueryLength)}
+	if cfg.MaxDepth > 0 {
+		opts = append(opts, graphql.MaxDepth(cfg.MaxDepth))
+	}
+	root := &Resolver{services: svc, logger: logger}
+	return &Executor{
+		schema:        graphql.MustParseSchema(schema, root, opts...),
+		services:      svc,
+		maxComplexity: cfg.MaxComplexity,
+	}
+}
+
+// Exec run the request, the nested fields are batched per request.
+func (e *Executor) Exec(ctx context.Context, req *Request) *graphql.Response {
+	ctx = withLoaders(ctx, newLoaders(e.services))
+	ctx = withBudget(ctx, e.maxComplexity)
+	return e.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// Resolver resolve the query root fields.
+type Resolver struct {
+	services *services.Services
+	logger   *logrus.Logger
+}
+
+// fail log an unexpected error and hide it from the client.
+func (r *Resolver) fail(err error) error {
+	r.logger.WithError(err).Error("graphql query failed")
+	return errInternal
+}
+
+func (r *Resolver) blocks(blocks []*dbmodels.BeaconBlock) []*blockResolver {
+	resolvers := make([]*blockResolver, 0, len(blocks))
+	for _, block := range blocks {
+		resolvers = append(resolvers, &blockResolver{root: r, block: block})
+	}
+	return resolvers
+}
+
+func (r *Resolver) attestations(atts []*dbmodels.BeaconAttestation) []*attestationResolver {
+	resolvers := make([]*attestationResolver, 0, len(atts))
+	for _, att := range atts {
+		resolvers = append(resolvers, &attestationResolver{root: r, att: att})
+	}
+	return resolvers
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Slot *int32
+	Root *string
+}) (*blockResolver, error) {
+	if (args.Slot == nil) == (args.Root == nil) {
+		return nil, errors.New("expect either slot or root")
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	var block *dbmodels.BeaconBlock
+	var err error
+	if args.Slot != nil {
+		block, err = r.services.BeaconBlock.GetBlockBySlot(uint64(*args.Slot))
+	} else {
+		block, err = r.services.BeaconBlock.GetBlockByRoot(*args.Root)
+	}
+	return r.block(block, err)
+}
+
+func (r *Resolver) LatestBlock(ctx context.Context) (*blockResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	return r.block(r.services.BeaconBlock.GetLatestBlock())
+}
+
+func (r *Resolver) block(block *dbmodels.BeaconBlock, err error) (*blockResolver, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return &blockResolver{root: r, block: block}, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	Page     int32
+	PageSize int32
+	Epoch    *int32
+	Proposer *int32
+	FromSlot *int32
+	ToSlot   *int32
+}) (*blockPageResolver, error) {
+	if err := checkPage(args.Page, args.PageSize); err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, int(args.PageSize)); err != nil {
+		return nil, err
+	}
+	filter := services.BlockFilter{
+		Epoch:    uint64Ptr(args.Epoch),
+		Proposer: uint64Ptr(args.Proposer),
+		FromSlot: uint64Ptr(args.FromSlot),
+		ToSlot:   uint64Ptr(args.ToSlot),
+	}
+	blocks, total, err := r.services.BeaconBlock.GetBlocks(filter, int(args.Page), int(args.PageSize))
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return &blockPageResolver{nodes: r.blocks(blocks), total: total}, nil
+}
+
+func (r *Resolver) Attestations(ctx context.Context, args struct {
+	First       int32
+	After       *graphql.ID
+	Slot        *int32
+	TargetEpoch *int32
+	Validator   *int32
+}) (*attestationPageResolver, error) {
+	if args.First < 1 || args.First > maxAttestationPage {
+		return nil, fmt.Errorf("first must be between 1 and %d", maxAttestationPage)
+	}
+	cursor := uint64(0)
+	if args.After != nil {
+		var err error
+		if cursor, err = strconv.ParseUint(string(*args.After), 10, 64); err != nil {
+			return nil, errors.New("invalid after cursor")
+		}
+	}
+	if err := charge(ctx, int(args.First)); err != nil {
+		return nil, err
+	}
+	filter := services.AttestationFilter{
+		InclusionSlot:  uint64Ptr(args.Slot),
+		TargetEpoch:    uint64Ptr(args.TargetEpoch),
+		ValidatorIndex: uint64Ptr(args.Validator),
+	}
+	atts, next, err := r.services.Attest.GetAttestations(filter, uint(cursor), int(args.First))
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return &attestationPageResolver{nodes: r.attestations(atts), next: next}, nil
+}
+
+func (r *Resolver) Validator(ctx context.Context, args struct {
+	Index  *int32
+	Pubkey *string
+}) (*validatorResolver, error) {
+	if (args.Index == nil) == (args.Pubkey == nil) {
+		return nil, errors.New("expect either index or pubkey")
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	var val *dbmodels.Validator
+	var err error
+	if args.Index != nil {
+		val, err = r.services.Validator.GetValidatorByIndex(uint64(*args.Index))
+	} else {
+		val, err = r.services.Validator.GetValidatorByPubkey(strings.ToLower(*args.Pubkey))
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return &validatorResolver{root: r, val: val}, nil
+}
+
+// Validators return the known validators of indices, in the order of indices.
+func (r *Resolver) Validators(ctx context.Context, args struct{ Indices []int32 }) ([]*validatorResolver, error) {
+	if len(args.Indices) > maxValidators {
+		return nil, fmt.Errorf("at most %d indices", maxValidators)
+	}
+	if err := charge(ctx, len(args.Indices)); err != nil {
+		return nil, err
+	}
+	indices := make([]uint64, 0, len(args.Indices))
+	for _, index := range args.Indices {
+		indices = append(indices, uint64(index))
+	}
+	vals, err := r.services.Validator.GetValidatorsByIndices(indices)
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	byIndex := make(map[uint64]*dbmodels.Validator, len(vals))
+	for _, val := range vals {
+		byIndex[val.ValidatorIndex] = val
+	}
+	resolvers := make([]*validatorResolver, 0, len(vals))
+	for _, index := range indices {
+		if val, exist := byIndex[index]; exist {
+			resolvers = append(resolvers, &validatorResolver{root: r, val: val})
+		}
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) Epoch(ctx context.Context, args struct{ Epoch int32 }) (*epochResolver, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	summary, err := r.services.Epoch.GetEpochSummary(uint64(args.Epoch))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	return &epochResolver{root: r, summary: summary}, nil
+}
+
+func (r *Resolver) Epochs(ctx context.Context, args struct {
+	Page     int32
+	PageSize int32
+}) (*epochPageResolver, error) {
+	if err := checkPage(args.Page, args.PageSize); err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, int(args.PageSize)); err != nil {
+		return nil, err
+	}
+	summaries, total, err := r.services.Epoch.GetEpochSummaries(int(args.Page), int(args.PageSize))
+	if err != nil {
+		return nil, r.fail(err)
+	}
+	nodes := make([]*epochResolver, 0, len(summaries))
+	for _, summary := range summaries {
+		nodes = append(nodes, &epochResolver{root: r, summary: summary})
+	}
+	return &epochPageResolver{nodes: nodes, total: total}, nil
+}
+
+// ScanTasks return the scan tasks then the directly scan tasks, kind select one of them. It
+// require an admin API key, like the admin routes.
+func (r *Resolver) ScanTasks(ctx context.Context, args struct{ Kind *string }) ([]*scanTaskResolver, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	kind := ""
+	if args.Kind != nil {
+		kind = *args.Kind
+	}
+	if kind != "" && kind != "scan" && kind != "direct" {
+		return nil, errors.New("kind must be scan or direct")
+	}
+	var tasks []*scanTaskResolver
+	if kind != "direct" {
+		scans, err := r.services.ScanTask.GetScanTasks()
+		if err != nil {
+			return nil, r.fail(err)
+		}
+		for _, task := range scans {
+			tasks = append(tasks, &scanTaskResolver{kind: "scan", id: task.ID, taskType: task.TaskType,
+				lastNumber: task.LastNumber, enabled: task.Enabled, updatedAt: graphql.Time{Time: task.UpdatedAt}})
+		}
+	}
+	if kind != "scan" {
+		directs, err := r.services.DirectlyScan.GetScanTasks()
+		if err != nil {
+			return nil, r.fail(err)
+		}
+		for _, task := range directs {
+			start, end := task.Start, task.End
+			tasks = append(tasks, &scanTaskResolver{kind: "direct", id: task.ID, taskType: task.TaskType,
+				lastNumber: task.LastNumber, start: &start, end: &end, enabled: task.Enabled,
+				updatedAt: graphql.Time{Time: task.UpdatedAt}})
+		}
+	}
+	return tasks, nil
+}
+
+func checkPage(page, pageSize int32) error {
+	if page < 1 {
+		return errors.New("invalid page")
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		return fmt.Errorf("pageSize must be between 1 and %d", maxPageSize)
+	}
+	return nil
+}
+
+func uint64Ptr(v *int32) *uint64 {
+	if v == nil {
+		return nil
+	}
+	n := uint64(*v)
+	return &n
+}
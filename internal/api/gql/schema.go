@@ -0,0 +1,147 @@
+package gql
+
+// schema is the GraphQL schema over the indexed data. Slots, epochs and validator indices are
+// Int, gwei amounts are Long and wei amounts decimal strings.
+const schema = `
+schema {
+	query: Query
+}
+
+# Long is a 64 bits integer, serialized as a string.
+scalar Long
+scalar Time
+
+type Query {
+	# block return the block at slot or with root.
+	block(slot: Int, root: String): Block
+	latestBlock: Block
+	# blocks return a page of blocks, newest first.
+	blocks(page: Int! = 1, pageSize: Int! = 20, epoch: Int, proposer: Int, fromSlot: Int, toSlot: Int): BlockPage!
+	# attestations return attestations newest first, after is the nextCursor of the previous page.
+	attestations(first: Int! = 100, after: ID, slot: Int, targetEpoch: Int, validator: Int): AttestationPage!
+	validator(index: Int, pubkey: String): Validator
+	validators(indices: [Int!]!): [Validator!]!
+	epoch(epoch: Int!): Epoch
+	# epochs return a page of finalized epoch summaries, newest first.
+	epochs(page: Int! = 1, pageSize: Int! = 20): EpochPage!
+	scanTasks(kind: String): [ScanTask!]!
+}
+
+type BlockPage {
+	nodes: [Block!]!
+	total: Int!
+}
+
+type AttestationPage {
+	nodes: [Attestation!]!
+	nextCursor: ID
+}
+
+type EpochPage {
+	nodes: [Epoch!]!
+	total: Int!
+}
+
+type Block {
+	slot: Int!
+	epoch: Int!
+	root: String!
+	parentRoot: String!
+	stateRoot: String!
+	finalized: Boolean!
+	proposerIndex: Int!
+	proposer: Validator
+	graffiti: String!
+	consensusClient: String!
+	executionClient: String!
+	executionBlockHash: String!
+	executionBlockNumber: Long
+	feeRecipient: String!
+	proposerSlashings: Int!
+	attesterSlashings: Int!
+	attestations: [Attestation!]!
+	# mev is the MEV-boost attribution of the payload, null if not attributed yet.
+	mev: BlockMev
+}
+
+type BlockMev {
+	locallyBuilt: Boolean!
+	builderPubkey: String!
+	relays: [String!]!
+	# value is the payment to the proposer in wei, as a decimal string.
+	value: String!
+}
+
+type Attestation {
+	id: ID!
+	inclusionSlot: Int!
+	inclusionBlock: Block
+	index: Int!
+	slot: Int!
+	committeeIndex: Int!
+	committeeBits: String!
+	aggregationBits: String!
+	beaconBlockRoot: String!
+	sourceEpoch: Int!
+	sourceRoot: String!
+	targetEpoch: Int!
+	targetRoot: String!
+	signature: String!
+}
+
+type Validator {
+	index: Int!
+	pubkey: String!
+	withdrawalCredentials: String!
+	effectiveBalance: Long!
+	slashed: Boolean!
+	status: String!
+	activationEligibilityEpoch: Long
+	activationEpoch: Long
+	exitEpoch: Long
+	withdrawableEpoch: Long
+	# rewards are the attestation and proposer rewards of the last days in gwei, penalties
+	# included.
+	rewards(days: Int! = 7): Rewards!
+}
+
+type Rewards {
+	total: Long!
+	daily: [DailyReward!]!
+}
+
+type DailyReward {
+	day: Time!
+	reward: Long!
+}
+
+type Epoch {
+	epoch: Int!
+	proposedBlocks: Int!
+	missedBlocks: Int!
+	sourceParticipation: Float!
+	targetParticipation: Float!
+	headParticipation: Float!
+	justifiedEpoch: Int!
+	finalizedEpoch: Int!
+	totalActiveBalance: Long!
+	activeValidators: Int!
+	attestationRewards: Long!
+	proposerRewards: Long!
+	totalRewards: Long!
+	blocks: [Block!]!
+}
+
+type ScanTask {
+	id: ID!
+	# kind is "scan" for the scanners following the chain, "direct" for the range tasks.
+	kind: String!
+	type: String!
+	lastNumber: Long!
+	# start and end are the range of a direct task.
+	start: Long
+	end: Long
+	enabled: Boolean!
+	updatedAt: Time!
+}
+`
@@ -0,0 +1,242 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+type blockResolver struct {
+	root  *Resolver
+	block *dbmodels.BeaconBlock
+}
+
+func (r *blockResolver) Slot() int32                { return int32(r.block.SlotNumber) }
+func (r *blockResolver) Epoch() int32               { return int32(r.block.EpochNumber) }
+func (r *blockResolver) Root() string               { return r.block.BlockRoot }
+func (r *blockResolver) ParentRoot() string         { return r.block.ParentRoot }
+func (r *blockResolver) StateRoot() string          { return r.block.StateRoot }
+func (r *blockResolver) Finalized() bool            { return r.block.Finalized }
+func (r *blockResolver) ProposerIndex() int32       { return int32(r.block.ProposerIndex) }
+func (r *blockResolver) Graffiti() string           { return r.block.GraffitiText }
+func (r *blockResolver) ConsensusClient() string    { return r.block.ConsensusClient }
+func (r *blockResolver) ExecutionClient() string    { return r.block.ExecutionClient }
+func (r *blockResolver) ExecutionBlockHash() string { return r.block.ExecutionBlockHash }
+func (r *blockResolver) ExecutionBlockNumber() *Long {
+	return longPtr(r.block.ExecutionBlockNumber)
+}
+func (r *blockResolver) FeeRecipient() string     { return r.block.FeeRecipient }
+func (r *blockResolver) ProposerSlashings() int32 { return int32(r.block.ProposerSlashed) }
+func (r *blockResolver) AttesterSlashings() int32 { return int32(r.block.AttesterSlashed) }
+
+func (r *blockResolver) Proposer(ctx context.Context) (*validatorResolver, error) {
+	val, err := loadersFrom(ctx).validators.Load(ctx, r.block.ProposerIndex)
+	if err != nil {
+		return nil, r.root.fail(err)
+	}
+	if val == nil {
+		return nil, nil
+	}
+	return &validatorResolver{root: r.root, val: val}, nil
+}
+
+func (r *blockResolver) Attestations(ctx context.Context) ([]*attestationResolver, error) {
+	atts, err := loadersFrom(ctx).attestations.Load(ctx, r.block.SlotNumber)
+	if err != nil {
+		return nil, r.root.fail(err)
+	}
+	return r.root.attestations(atts), nil
+}
+
+func (r *blockResolver) Mev(ctx context.Context) (*mevResolver, error) {
+	mev, err := loadersFrom(ctx).mevs.Load(ctx, r.block.SlotNumber)
+	if err != nil {
+		return nil, r.root.fail(err)
+	}
+	if mev == nil {
+		return nil, nil
+	}
+	return &mevResolver{mev: mev}, nil
+}
+
+type mevResolver struct {
+	mev *dbmodels.BlockMev
+}
+
+func (r *mevResolver) LocallyBuilt() bool    { return r.mev.LocallyBuilt }
+func (r *mevResolver) BuilderPubkey() string { return r.mev.BuilderPubkey }
+func (r *mevResolver) Value() string         { return r.mev.Value }
+
+func (r *mevResolver) Relays() []string {
+	if r.mev.Relays == "" {
+		return []string{}
+	}
+	return strings.Split(r.mev.Relays, ",")
+}
+
+type attestationResolver struct {
+	root *Resolver
+	att  *dbmodels.BeaconAttestation
+}
+
+func (r *attestationResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(r.att.ID), 10))
+}
+func (r *attestationResolver) InclusionSlot() int32    { return int32(r.att.SlotNumber) }
+func (r *attestationResolver) Index() int32            { return int32(r.att.AttestIndex) }
+func (r *attestationResolver) Slot() int32             { return int32(r.att.AttestationSlot) }
+func (r *attestationResolver) CommitteeIndex() int32   { return int32(r.att.CommitteeIndex) }
+func (r *attestationResolver) CommitteeBits() string   { return r.att.CommitteeBits }
+func (r *attestationResolver) AggregationBits() string { return r.att.AggregationBits }
+func (r *attestationResolver) BeaconBlockRoot() string { return r.att.BeaconBlockRoot }
+func (r *attestationResolver) SourceEpoch() int32      { return int32(r.att.SourceEpoch) }
+func (r *attestationResolver) SourceRoot() string      { return r.att.SourceRoot }
+func (r *attestationResolver) TargetEpoch() int32      { return int32(r.att.TargetEpoch) }
+func (r *attestationResolver) TargetRoot() string      { return r.att.TargetRoot }
+func (r *attestationResolver) Signature() string       { return r.att.Signature }
+
+func (r *attestationResolver) InclusionBlock(ctx context.Context) (*blockResolver, error) {
+	block, err := loadersFrom(ctx).blocks.Load(ctx, r.att.SlotNumber)
+	if err != nil {
+		return nil, r.root.fail(err)
+	}
+	if block == nil {
+		return nil, nil
+	}
+	return &blockResolver{root: r.root, block: block}, nil
+}
+
+type validatorResolver struct {
+	root *Resolver
+	val  *dbmodels.Validator
+}
+
+func (r *validatorResolver) Index() int32                  { return int32(r.val.ValidatorIndex) }
+func (r *validatorResolver) Pubkey() string                { return r.val.Pubkey }
+func (r *validatorResolver) WithdrawalCredentials() string { return r.val.WithdrawalCredentials }
+func (r *validatorResolver) EffectiveBalance() Long        { return Long(r.val.EffectiveBalance) }
+func (r *validatorResolver) Slashed() bool                 { return r.val.Slashed }
+func (r *validatorResolver) Status() string                { return r.val.Status }
+func (r *validatorResolver) ActivationEligibilityEpoch() *Long {
+	return longPtr(r.val.ActivationEligibilityEpoch)
+}
+func (r *validatorResolver) ActivationEpoch() *Long   { return longPtr(r.val.ActivationEpoch) }
+func (r *validatorResolver) ExitEpoch() *Long         { return longPtr(r.val.ExitEpoch) }
+func (r *validatorResolver) WithdrawableEpoch() *Long { return longPtr(r.val.WithdrawableEpoch) }
+
+func (r *validatorResolver) Rewards(ctx context.Context, args struct{ Days int32 }) (*rewardsResolver, error) {
+	if args.Days < 1 || args.Days > maxRewardDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", maxRewardDays)
+	}
+	rewards, err := loadersFrom(ctx).rewards.Load(ctx, rewardsKey{index: r.val.ValidatorIndex, days: int(args.Days)})
+	if err != nil {
+		return nil, r.root.fail(err)
+	}
+	return &rewardsResolver{rewards: rewards}, nil
+}
+
+type rewardsResolver struct {
+	rewards *rewards
+}
+
+func (r *rewardsResolver) Total() Long { return Long(r.rewards.total) }
+
+func (r *rewardsResolver) Daily() []*dailyRewardResolver {
+	daily := make([]*dailyRewardResolver, 0, len(r.rewards.daily))
+	for _, reward := range r.rewards.daily {
+		daily = append(daily, &dailyRewardResolver{day: graphql.Time{Time: reward.Day}, reward: Long(reward.Reward)})
+	}
+	return daily
+}
+
+type dailyRewardResolver struct {
+	day    graphql.Time
+	reward Long
+}
+
+func (r *dailyRewardResolver) Day() graphql.Time { return r.day }
+func (r *dailyRewardResolver) Reward() Long      { return r.reward }
+
+type epochResolver struct {
+	root    *Resolver
+	summary *dbmodels.EpochSummary
+}
+
+func (r *epochResolver) Epoch() int32                 { return int32(r.summary.Epoch) }
+func (r *epochResolver) ProposedBlocks() int32        { return int32(r.summary.ProposedBlocks) }
+func (r *epochResolver) MissedBlocks() int32          { return int32(r.summary.MissedBlocks) }
+func (r *epochResolver) SourceParticipation() float64 { return r.summary.SourceParticipation }
+func (r *epochResolver) TargetParticipation() float64 { return r.summary.TargetParticipation }
+func (r *epochResolver) HeadParticipation() float64   { return r.summary.HeadParticipation }
+func (r *epochResolver) JustifiedEpoch() int32        { return int32(r.summary.JustifiedEpoch) }
+func (r *epochResolver) FinalizedEpoch() int32        { return int32(r.summary.FinalizedEpoch) }
+func (r *epochResolver) TotalActiveBalance() Long     { return Long(r.summary.TotalActiveBalance) }
+func (r *epochResolver) ActiveValidators() int32      { return int32(r.summary.ActiveValidators) }
+func (r *epochResolver) AttestationRewards() Long     { return Long(r.summary.AttestationRewards) }
+func (r *epochResolver) ProposerRewards() Long        { return Long(r.summary.ProposerRewards) }
+func (r *epochResolver) TotalRewards() Long           { return Long(r.summary.TotalRewards) }
+
+func (r *epochResolver) Blocks(ctx context.Context) ([]*blockResolver, error) {
+	blocks, err := loadersFrom(ctx).epochBlocks.Load(ctx, r.summary.Epoch)
+	if err != nil {
+		return nil, r.root.fail(err)
+	}
+	return r.root.blocks(blocks), nil
+}
+
+// scanTaskResolver resolve both the scan tasks and the directly scan tasks.
+type scanTaskResolver struct {
+	kind       string
+	id         uint
+	taskType   string
+	lastNumber uint64
+	start, end *uint64
+	enabled    bool
+	updatedAt  graphql.Time
+}
+
+func (r *scanTaskResolver) ID() graphql.ID {
+	return graphql.ID(fmt.Sprintf("%s:%d", r.kind, r.id))
+}
+func (r *scanTaskResolver) Kind() string            { return r.kind }
+func (r *scanTaskResolver) Type() string            { return r.taskType }
+func (r *scanTaskResolver) LastNumber() Long        { return Long(r.lastNumber) }
+func (r *scanTaskResolver) Start() *Long            { return longPtr(r.start) }
+func (r *scanTaskResolver) End() *Long              { return longPtr(r.end) }
+func (r *scanTaskResolver) Enabled() bool           { return r.enabled }
+func (r *scanTaskResolver) UpdatedAt() graphql.Time { return r.updatedAt }
+
+type blockPageResolver struct {
+	nodes []*blockResolver
+	total int64
+}
+
+func (r *blockPageResolver) Nodes() []*blockResolver { return r.nodes }
+func (r *blockPageResolver) Total() int32            { return int32(r.total) }
+
+type attestationPageResolver struct {
+	nodes []*attestationResolver
+	next  uint
+}
+
+func (r *attestationPageResolver) Nodes() []*attestationResolver { return r.nodes }
+
+func (r *attestationPageResolver) NextCursor() *graphql.ID {
+	if r.next == 0 {
+		return nil
+	}
+	id := graphql.ID(strconv.FormatUint(uint64(r.next), 10))
+	return &id
+}
+
+type epochPageResolver struct {
+	nodes []*epochResolver
+	total int64
+}
+
+func (r *epochPageResolver) Nodes() []*epochResolver { return r.nodes }
+func (r *epochPageResolver) Total() int32            { return int32(r.total) }
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+const (
+	taskKindScan   = "scan"
+	taskKindDirect = "direct"
+
+	lagUnitSlot  = "slot"
+	lagUnitEpoch = "epoch"
+)
+
+// taskLagUnits is the unit of LastNumber of the scan tasks which follow the beacon chain,
+// tasks following the execution chain have no lag.
+var taskLagUnits = map[string]string{
+	constant.SCAN_TYPE_BEACON_BLOCK:       lagUnitSlot,
+	constant.SCAN_TYPE_PAYLOAD_LINK:       lagUnitSlot,
+	constant.SCAN_TYPE_MEV:                lagUnitSlot,
+	constant.SCAN_TYPE_VALIDATOR_SNAPSHOT: lagUnitEpoch,
+	constant.SCAN_TYPE_VALIDATOR_BALANCE:  lagUnitEpoch,
+	constant.SCAN_TYPE_DUTIES:             lagUnitEpoch,
+	constant.SCAN_TYPE_EPOCH_SUMMARY:      lagUnitEpoch,
+	constant.SCAN_TYPE_FINALITY:           lagUnitEpoch,
+	constant.SCAN_TYPE_WATCHLIST:          lagUnitEpoch,
+}
+
+var scanTaskTypes = map[string]bool{
+	constant.SCAN_TYPE_BEACON_BLOCK:       true,
+	constant.SCAN_TYPE_VALIDATOR_SNAPSHOT: true,
+	constant.SCAN_TYPE_VALIDATOR_BALANCE:  true,
+	constant.SCAN_TYPE_DUTIES:             true,
+	constant.SCAN_TYPE_EPOCH_SUMMARY:      true,
+	constant.SCAN_TYPE_FINALITY:           true,
+	constant.SCAN_TYPE_ETH1_BLOCK:         true,
+	constant.SCAN_TYPE_DEPOSIT:            true,
+	constant.SCAN_TYPE_PAYLOAD_LINK:       true,
+	constant.SCAN_TYPE_MEV:                true,
+	constant.SCAN_TYPE_WATCHLIST:          true,
+}
+
+// AdminScanTask is a scan task with its distance to the chain head.
+type AdminScanTask struct {
+	*dbmodels.ScanTask
+	Lag     *uint64 `json:"lag,omitempty"`
+	LagUnit string  `json:"lag_unit,omitempty"`
+}
+
+// AdminDirectlyTask is a range task with its remaining work.
+type AdminDirectlyTask struct {
+	*dbmodels.DirectlyScanTask
+	Remaining uint64 `json:"remaining"`
+	Completed bool   `json:"completed"`
+}
+
+type createTaskRequest struct {
+	Kind     string  `json:"kind" binding:"required"`
+	TaskType string  `json:"task_type"`
+	Start    *uint64 `json:"start" binding:"required"`
+	End      uint64  `json:"end"`
+}
+
+type resetTaskRequest struct {
+	LastNumber *uint64 `json:"last_number" binding:"required"`
+}
+
+type reindexRequest struct {
+	Start *uint64 `json:"start" binding:"required"`
+	End   *uint64 `json:"end" binding:"required"`
+}
+
+// ListTasks return every scan task and range task with its progress.
+func (h *Handlers) ListTasks(c *gin.Context) {
+	tasks, err := h.services.ScanTask.GetScanTasks()
+	if err != nil {
+		h.logger.WithError(err).Error("get scan tasks failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	directs, err := h.services.DirectlyScan.GetScanTasks()
+	if err != nil {
+		h.logger.WithError(err).Error("get directly scan tasks failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	headSlot, err := h.currentSlot(time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("get chain clock failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	scanTasks := make([]*AdminScanTask, 0, len(tasks))
+	for _, task := range tasks {
+		scanTasks = append(scanTasks, scanTaskStatus(task, headSlot))
+	}
+	directTasks := make([]*AdminDirectlyTask, 0, len(directs))
+	for _, task := range directs {
+		directTasks = append(directTasks, directlyTaskStatus(task))
+	}
+	c.JSON(http.StatusOK, gin.H{"scan_tasks": scanTasks, "directly_tasks": directTasks})
+}
+
+// CreateTask add a scan task of a type not deployed yet, or a range task.
+func (h *Handlers) CreateTask(c *gin.Context) {
+	var req createTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch req.Kind {
+	case taskKindScan:
+		if !scanTaskTypes[req.TaskType] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown task type"})
+			return
+		}
+		headSlot, err := h.currentSlot(time.Now())
+		if err != nil {
+			h.logger.WithError(err).Error("get chain clock failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		task := &dbmodels.ScanTask{TaskType: req.TaskType, LastNumber: *req.Start, Enabled: true}
+		if err := h.services.ScanTask.CreateScanTask(task); err != nil {
+			if errors.Is(err, services.ErrTaskExists) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			h.logger.WithError(err).Error("create scan task failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		c.JSON(http.StatusCreated, scanTaskStatus(task, headSlot))
+	case taskKindDirect:
+		if req.TaskType != "" && req.TaskType != constant.DIRECTLY_SCAN_TYPE_BEACON_BLOCK {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown task type"})
+			return
+		}
+		task, err := h.createRangeTask(*req.Start, req.End)
+		if err != nil {
+			if errors.Is(err, errInvalidRange) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			h.logger.WithError(err).Error("create directly scan task failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		c.JSON(http.StatusCreated, directlyTaskStatus(task))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be scan or direct"})
+	}
+}
+
+// PauseTask stop the scanner of the task on its next tick.
+func (h *Handlers) PauseTask(c *gin.Context) {
+	h.setTaskEnabled(c, false)
+}
+
+// ResumeTask let the scanner pick up the task again.
+func (h *Handlers) ResumeTask(c *gin.Context) {
+	h.setTaskEnabled(c, true)
+}
+
+func (h *Handlers) setTaskEnabled(c *gin.Context, enabled bool) {
+	kind, id, ok := taskParams(c)
+	if !ok {
+		return
+	}
+	var (
+		found bool
+		err   error
+	)
+	if kind == taskKindScan {
+		found, err = h.services.ScanTask.SetScanTaskEnabled(id, enabled)
+	} else {
+		found, err = h.services.DirectlyScan.SetScanTaskEnabled(id, enabled)
+	}
+	h.respondTaskChange(c, kind, id, found, err)
+}
+
+// ResetTask move the checkpoint of a paused task.
+func (h *Handlers) ResetTask(c *gin.Context) {
+	kind, id, ok := taskParams(c)
+	if !ok {
+		return
+	}
+	var req resetTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var (
+		found bool
+		err   error
+	)
+	if kind == taskKindScan {
+		found, err = h.services.ScanTask.ResetScanTask(id, *req.LastNumber)
+	} else {
+		found, err = h.services.DirectlyScan.ResetScanTask(id, *req.LastNumber)
+	}
+	if err == nil && !found {
+		// tell a missing task apart from a running one.
+		if h.taskExists(kind, id) {
+			c.JSON(http.StatusConflict, gin.H{"error": "task must be paused before reset"})
+			return
+		}
+	}
+	h.respondTaskChange(c, kind, id, found, err)
+}
+
+// CancelTask delete the task.
+func (h *Handlers) CancelTask(c *gin.Context) {
+	kind, id, ok := taskParams(c)
+	if !ok {
+		return
+	}
+	var (
+		found bool
+		err   error
+	)
+	if kind == taskKindScan {
+		found, err = h.services.ScanTask.DeleteScanTask(id)
+	} else {
+		found, err = h.services.DirectlyScan.DeleteScanTask(id)
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("delete task failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Reindex delete the indexed blocks of a slot range and schedule a range task to index them again,
+// the payload links and mev attributions of the range are computed again after it.
+func (h *Handlers) Reindex(c *gin.Context) {
+	var req reindexRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *req.End < *req.Start {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidRange.Error()})
+		return
+	}
+	// the range must be behind the block scanner, or both would write the same slots.
+	blockTask, err := h.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.WithError(err).Error("get beacon block task failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	if blockTask == nil || *req.End > blockTask.LastNumber {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "range is not indexed yet"})
+		return
+	}
+	deleted, err := h.services.BeaconBlock.DeleteBlocksInRange(*req.Start, *req.End)
+	if err != nil {
+		h.logger.WithError(err).Error("delete blocks failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	task, err := h.createRangeTask(*req.Start, *req.End)
+	if err != nil {
+		h.logger.WithError(err).Error("create reindex task failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	// the tasks deriving data from the blocks start over from the range, they wait for the
+	// range task before going past its checkpoint.
+	for _, taskType := range reindexDerivedTasks {
+		if _, err := h.services.ScanTask.RewindScanTask(taskType, task.LastNumber); err != nil {
+			h.logger.WithField("task", taskType).WithError(err).Error("rewind scan task failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+	}
+	h.logger.WithFields(logrus.Fields{
+		"start":   *req.Start,
+		"end":     *req.End,
+		"deleted": deleted,
+	}).Info("Scheduled reindex")
+	c.JSON(http.StatusAccepted, gin.H{"deleted_blocks": deleted, "task": directlyTaskStatus(task)})
+}
+
+var errInvalidRange = errors.New("end must not be lower than start")
+
+// reindexDerivedTasks are the scan tasks computing their data from the indexed blocks, their
+// checkpoints are exclusive slots.
+var reindexDerivedTasks = []string{constant.SCAN_TYPE_PAYLOAD_LINK, constant.SCAN_TYPE_MEV}
+
+// createRangeTask add a range task indexing the slots from start to end inclusive.
+func (h *Handlers) createRangeTask(start, end uint64) (*dbmodels.DirectlyScanTask, error) {
+	if end < start {
+		return nil, errInvalidRange
+	}
+	task := &dbmodels.DirectlyScanTask{
+		TaskType: constant.DIRECTLY_SCAN_TYPE_BEACON_BLOCK,
+		Start:    start,
+		End:      end,
+		Enabled:  true,
+	}
+	if start > 0 {
+		task.LastNumber = start - 1
+	}
+	if err := h.services.DirectlyScan.CreateScanTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (h *Handlers) taskExists(kind string, id uint) bool {
+	if kind == taskKindScan {
+		_, err := h.services.ScanTask.GetScanTaskByID(id)
+		return err == nil
+	}
+	_, err := h.services.DirectlyScan.GetScanTaskByID(id)
+	return err == nil
+}
+
+func (h *Handlers) respondTaskChange(c *gin.Context, kind string, id uint, found bool, err error) {
+	if err != nil {
+		h.logger.WithError(err).Error("update task failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	if kind == taskKindScan {
+		task, err := h.services.ScanTask.GetScanTaskByID(id)
+		if err != nil {
+			h.logger.WithError(err).Error("get scan task failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		headSlot, err := h.currentSlot(time.Now())
+		if err != nil {
+			h.logger.WithError(err).Error("get chain clock failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		c.JSON(http.StatusOK, scanTaskStatus(task, headSlot))
+		return
+	}
+	task, err := h.services.DirectlyScan.GetScanTaskByID(id)
+	if err != nil {
+		h.logger.WithError(err).Error("get directly scan task failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, directlyTaskStatus(task))
+}
+
+// taskParams parse the kind and id of the task path, and respond an error if invalid.
+func taskParams(c *gin.Context) (string, uint, bool) {
+	kind := c.Param("kind")
+	if kind != taskKindScan && kind != taskKindDirect {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be scan or direct"})
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return "", 0, false
+	}
+	return kind, uint(id), true
+}
+
+func scanTaskStatus(task *dbmodels.ScanTask, headSlot uint64) *AdminScanTask {
+	status := &AdminScanTask{ScanTask: task}
+	unit, exist := taskLagUnits[task.TaskType]
+	if !exist {
+		return status
+	}
+	head := headSlot
+	if unit == lagUnitEpoch {
+		head = headSlot / slotsPerEpoch
+	}
+	lag := uint64(0)
+	if head > task.LastNumber {
+		lag = head - task.LastNumber
+	}
+	status.Lag, status.LagUnit = &lag, unit
+	return status
+}
+
+func directlyTaskStatus(task *dbmodels.DirectlyScanTask) *AdminDirectlyTask {
+	status := &AdminDirectlyTask{DirectlyScanTask: task}
+	if task.LastNumber >= task.End {
+		status.Completed = true
+		return status
+	}
+	// slots before start are never indexed by the task.
+	done := task.LastNumber
+	if done < task.Start && task.Start > 0 {
+		done = task.Start - 1
+	}
+	status.Remaining = task.End - done
+	return status
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func TestScanTaskStatus(t *testing.T) {
+	status := scanTaskStatus(&dbmodels.ScanTask{TaskType: constant.SCAN_TYPE_BEACON_BLOCK, LastNumber: 900}, 1000)
+	assert.Equal(t, uint64(100), *status.Lag)
+	assert.Equal(t, lagUnitSlot, status.LagUnit)
+
+	status = scanTaskStatus(&dbmodels.ScanTask{TaskType: constant.SCAN_TYPE_DUTIES, LastNumber: 32}, 1000)
+	assert.Equal(t, uint64(0), *status.Lag)
+	assert.Equal(t, lagUnitEpoch, status.LagUnit)
+
+	status = scanTaskStatus(&dbmodels.ScanTask{TaskType: constant.SCAN_TYPE_EPOCH_SUMMARY, LastNumber: 20}, 1000)
+	assert.Equal(t, uint64(11), *status.Lag)
+
+	status = scanTaskStatus(&dbmodels.ScanTask{TaskType: constant.SCAN_TYPE_ETH1_BLOCK, LastNumber: 20}, 1000)
+	assert.Nil(t, status.Lag)
+}
+
+func TestDirectlyTaskStatus(t *testing.T) {
+	status := directlyTaskStatus(&dbmodels.DirectlyScanTask{Start: 100, End: 199})
+	assert.Equal(t, uint64(100), status.Remaining)
+	assert.False(t, status.Completed)
+
+	status = directlyTaskStatus(&dbmodels.DirectlyScanTask{Start: 100, End: 199, LastNumber: 150})
+	assert.Equal(t, uint64(49), status.Remaining)
+
+	status = directlyTaskStatus(&dbmodels.DirectlyScanTask{Start: 100, End: 199, LastNumber: 199})
+	assert.True(t, status.Completed)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"gorm.io/gorm"
+)
+
+// ListAttestations return attestations newest first, paginated by cursor. The next_cursor of
+// the response is passed as cursor to get the next page, it is absent on the last page.
+func (h *Handlers) ListAttestations(c *gin.Context) {
+	cursor, limit, err := parseCursor(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	values, err := queryUints(c, "slot", "committee_index", "target_epoch", "validator_index")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	root := c.Query("beacon_block_root")
+	if root != "" && !isRoot(root) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid beacon_block_root"})
+		return
+	}
+	filter := services.AttestationFilter{
+		InclusionSlot:   values[0],
+		CommitteeIndex:  values[1],
+		TargetEpoch:     values[2],
+		ValidatorIndex:  values[3],
+		BeaconBlockRoot: root,
+	}
+	atts, next, err := h.services.Attest.GetAttestations(filter, cursor, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("list attestations failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	res := gin.H{"data": atts}
+	if next > 0 {
+		res["next_cursor"] = next
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetBlockAttestations return the attestations included in the block identified by slot or root.
+func (h *Handlers) GetBlockAttestations(c *gin.Context) {
+	slot, err := h.blockSlot(c.Param("id"))
+	if errors.Is(err, errInvalidBlockID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "block not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("get block failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	atts, err := h.services.Attest.GetAttestationsByBlock(slot)
+	if err != nil {
+		h.logger.WithError(err).Error("get block attestations failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": atts})
+}
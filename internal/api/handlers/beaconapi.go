@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+// The Beacon API routes serve finalized data from the index, in the format of the standard
+// Beacon API, and forward everything else to the beacon node.
+
+// beaconIndex is the indexed data served by the Beacon API routes.
+type beaconIndex interface {
+	GetBlockBySlot(slot uint64) (*dbmodels.BeaconBlock, error)
+	GetBlockByRoot(root string) (*dbmodels.BeaconBlock, error)
+	GetFinalizedBlockByParentRoot(root string) (*dbmodels.BeaconBlock, error)
+	GetFinalizedBlockByStateRoot(root string) (*dbmodels.BeaconBlock, error)
+	GetApiBlock(slot uint64) (*dbmodels.BeaconApiBlock, error)
+	SaveApiBlock(block *dbmodels.BeaconApiBlock, maxSaved int) error
+	GetLatestCheckpoint() (*dbmodels.FinalityCheckpoint, error)
+	GetCheckpointByEpoch(epoch uint64) (*dbmodels.FinalityCheckpoint, error)
+}
+
+// serviceIndex serve the Beacon API routes from the block and finality services.
+type serviceIndex struct {
+	*services.BeaconBlockService
+	*services.FinalityService
+}
+
+// beaconResponse is the envelope of the Beacon API responses.
+type beaconResponse struct {
+	ExecutionOptimistic bool        `json:"execution_optimistic"`
+	Finalized           bool        `json:"finalized"`
+	Data                interface{} `json:"data"`
+}
+
+type beaconHeaderMessage struct {
+	Slot          string `json:"slot"`
+	ProposerIndex string `json:"proposer_index"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	BodyRoot      string `json:"body_root"`
+}
+
+type beaconSignedHeader struct {
+	Message   beaconHeaderMessage `json:"message"`
+	Signature string              `json:"signature"`
+}
+
+type beaconHeader struct {
+	Root      string             `json:"root"`
+	Canonical bool               `json:"canonical"`
+	Header    beaconSignedHeader `json:"header"`
+}
+
+type beaconCheckpoint struct {
+	Epoch string `json:"epoch"`
+	Root  string `json:"root"`
+}
+
+type beaconFinality struct {
+	PreviousJustified beaconCheckpoint `json:"previous_justified"`
+	CurrentJustified  beaconCheckpoint `json:"current_justified"`
+	Finalized         beaconCheckpoint `json:"finalized"`
+}
+
+// beaconApiBlock is the part of a /eth/v2/beacon/blocks response needed to save it.
+type beaconApiBlock struct {
+	Version   string `json:"version"`
+	Finalized bool   `json:"finalized"`
+	Data      struct {
+		Message struct {
+			Slot string `json:"slot"`
+		} `json:"message"`
+	} `json:"data"`
+}
+
+// BeaconHeaders serve /eth/v1/beacon/headers, the header at slot or the child of parent_root
+// is served from the index when finalized.
+func (h *Handlers) BeaconHeaders(c *gin.Context) {
+	var block *dbmodels.BeaconBlock
+	var err error
+	slot, slotErr := queryUint(c, "slot")
+	parentRoot := c.Query("parent_root")
+	switch {
+	case slotErr != nil:
+	case parentRoot != "" && isRoot(parentRoot):
+		block, err = h.index.GetFinalizedBlockByParentRoot(parentRoot)
+		if block != nil && slot != nil && block.SlotNumber != *slot {
+			block = nil
+		}
+	case parentRoot == "" && slot != nil:
+		block, err = h.findBeaconBlock(strconv.FormatUint(*slot, 10))
+	}
+	if err != nil {
+		h.logger.WithError(err).Warn("get indexed header failed, forward to beacon node")
+	}
+	if !servableHeader(block) {
+		h.proxyBeacon(c)
+		return
+	}
+	c.JSON(http.StatusOK, beaconResponse{Finalized: true, Data: []*beaconHeader{toBeaconHeader(block)}})
+}
+
+// BeaconHeader serve /eth/v1/beacon/headers/{block_id}, finalized headers by slot or root
+// are served from the index.
+func (h *Handlers) BeaconHeader(c *gin.Context) {
+	block, err := h.findBeaconBlock(c.Param("block_id"))
+	if err != nil {
+		h.logger.WithError(err).Warn("get indexed header failed, forward to beacon node")
+	}
+	if !servableHeader(block) {
+		h.proxyBeacon(c)
+		return
+	}
+	c.JSON(http.StatusOK, beaconResponse{Finalized: true, Data: toBeaconHeader(block)})
+}
+
+// BeaconBlock serve /eth/v2/beacon/blocks/{block_id} in JSON. The index does not hold the
+// block bodies, so the responses of finalized blocks are saved the first time they are
+// forwarded to the beacon node and served from this read-through cache after.
+func (h *Handlers) BeaconBlock(c *gin.Context) {
+	if !acceptJSON(c) {
+		h.proxyBeacon(c)
+		return
+	}
+	saved, err := h.findApiBlock(c.Param("block_id"))
+	if err != nil {
+		h.logger.WithError(err).Warn("get saved block failed, forward to beacon node")
+	}
+	if saved != nil {
+		c.Header("Eth-Consensus-Version", saved.Version)
+		c.Data(http.StatusOK, "application/json", []byte(saved.Body))
+		return
+	}
+	res := h.proxyBeacon(c)
+	if res == nil || res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+		return
+	}
+	var block beaconApiBlock
+	if err := json.Unmarshal(res.Body, &block); err != nil || !block.Finalized {
+		return
+	}
+	slot, err := strconv.ParseUint(block.Data.Message.Slot, 10, 64)
+	if err != nil {
+		return
+	}
+	err = h.index.SaveApiBlock(&dbmodels.BeaconApiBlock{SlotNumber: slot, Version: block.Version, Body: string(res.Body)},
+		h.config.Server.SavedApiBlocks)
+	if err != nil {
+		h.logger.WithField("slot", slot).WithError(err).Warn("save finalized block failed")
+	}
+}
+
+// BeaconFinalityCheckpoints serve /eth/v1/beacon/states/{state_id}/finality_checkpoints. The
+// checkpoints only change at epoch boundaries, so the checkpoints recorded for an epoch are
+// served for any state of the epoch once it is behind the finalized checkpoint.
+func (h *Handlers) BeaconFinalityCheckpoints(c *gin.Context) {
+	checkpoint, err := h.findCheckpoint(c.Param("state_id"))
+	if err != nil {
+		h.logger.WithError(err).Warn("get indexed checkpoints failed, forward to beacon node")
+	}
+	if checkpoint == nil {
+		h.proxyBeacon(c)
+		return
+	}
+	c.JSON(http.StatusOK, beaconResponse{Finalized: true, Data: &beaconFinality{
+		PreviousJustified: beaconCheckpoint{Epoch: strconv.FormatUint(checkpoint.PreviousJustifiedEpoch, 10), Root: checkpoint.PreviousJustifiedRoot},
+		CurrentJustified:  beaconCheckpoint{Epoch: strconv.FormatUint(checkpoint.JustifiedEpoch, 10), Root: checkpoint.JustifiedRoot},
+		Finalized:         beaconCheckpoint{Epoch: strconv.FormatUint(checkpoint.FinalizedEpoch, 10), Root: checkpoint.FinalizedRoot},
+	}})
+}
+
+// findBeaconBlock return the finalized block identified by a slot or a root, nil for the
+// other block ids and for blocks not indexed or not finalized.
+func (h *Handlers) findBeaconBlock(id string) (*dbmodels.BeaconBlock, error) {
+	var block *dbmodels.BeaconBlock
+	var err error
+	if isRoot(id) {
+		block, err = h.index.GetBlockByRoot(id)
+	} else if slot, perr := strconv.ParseUint(id, 10, 64); perr == nil {
+		block, err = h.index.GetBlockBySlot(slot)
+	} else {
+		return nil, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !block.Finalized {
+		return nil, nil
+	}
+	return block, nil
+}
+
+// findApiBlock return the saved response of the block identified by a slot or a root.
+func (h *Handlers) findApiBlock(id string) (*dbmodels.BeaconApiBlock, error) {
+	slot, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		block, err := h.findBeaconBlock(id)
+		if block == nil {
+			return nil, err
+		}
+		slot = block.SlotNumber
+	}
+	return h.index.GetApiBlock(slot)
+}
+
+// findCheckpoint return the checkpoints of the state at a slot or with a state root, nil if
+// the state is not finalized or its epoch has no recorded checkpoints.
+func (h *Handlers) findCheckpoint(id string) (*dbmodels.FinalityCheckpoint, error) {
+	var slot uint64
+	if isRoot(id) {
+		block, err := h.index.GetFinalizedBlockByStateRoot(id)
+		if block == nil {
+			return nil, err
+		}
+		slot = block.SlotNumber
+	} else {
+		var err error
+		if slot, err = strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, nil
+		}
+	}
+	latest, err := h.index.GetLatestCheckpoint()
+	if latest == nil || err != nil {
+		return nil, err
+	}
+	epoch := slot / slotsPerEpoch
+	if epoch >= latest.FinalizedEpoch {
+		return nil, nil
+	}
+	return h.index.GetCheckpointByEpoch(epoch)
+}
+
+// proxyBeacon forward the request to the beacon node and copy its response, which is also
+// returned, nil if the beacon node could not be reached.
+func (h *Handlers) proxyBeacon(c *gin.Context) *beaconapi.RawResponse {
+	res, err := h.beacon.GetRaw(c.Request.Context(), c.Request.URL.RequestURI(), c.GetHeader("Accept"))
+	if err != nil {
+		h.logger.WithField("path", c.Request.URL.Path).WithError(err).Error("forward to beacon node failed")
+		c.JSON(http.StatusBadGateway, gin.H{"code": http.StatusBadGateway, "message": "beacon node unavailable"})
+		return nil
+	}
+	for _, name := range []string{"Eth-Consensus-Version", "Eth-Execution-Payload-Blinded"} {
+		if value := res.Header.Get(name); value != "" {
+			c.Header(name, value)
+		}
+	}
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), res.Body)
+	return res
+}
+
+// servableHeader return true if the header of block can be served from the index, blocks
+// indexed before the body root was recorded cannot. A block is only flagged finalized when
+// the finality scanner walk the chain back from the finalized checkpoint, and the orphans
+// found on the way are deleted, so a finalized block is canonical.
+func servableHeader(block *dbmodels.BeaconBlock) bool {
+	return block != nil && block.Finalized && block.BodyRoot != ""
+}
+
+func toBeaconHeader(block *dbmodels.BeaconBlock) *beaconHeader {
+	return &beaconHeader{
+		Root:      block.BlockRoot,
+		Canonical: true,
+		Header: beaconSignedHeader{
+			Message: beaconHeaderMessage{
+				Slot:          strconv.FormatUint(block.SlotNumber, 10),
+				ProposerIndex: strconv.FormatUint(block.ProposerIndex, 10),
+				ParentRoot:    block.ParentRoot,
+				StateRoot:     block.StateRoot,
+				BodyRoot:      block.BodyRoot,
+			},
+			Signature: block.Signature,
+		},
+	}
+}
+
+// acceptJSON return true if the client accept a JSON response, the default of the Beacon API.
+func acceptJSON(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+	return accept == "" || strings.Contains(accept, "application/json") || strings.Contains(accept, "*/*")
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+// fakeIndex is an index holding a few blocks.
+type fakeIndex struct {
+	serviceIndex
+	blocks    map[uint64]*dbmodels.BeaconBlock
+	apiBlocks map[uint64]*dbmodels.BeaconApiBlock
+}
+
+func (f *fakeIndex) GetBlockBySlot(slot uint64) (*dbmodels.BeaconBlock, error) {
+	if block, exist := f.blocks[slot]; exist {
+		return block, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeIndex) GetApiBlock(slot uint64) (*dbmodels.BeaconApiBlock, error) {
+	return f.apiBlocks[slot], nil
+}
+
+func (f *fakeIndex) SaveApiBlock(block *dbmodels.BeaconApiBlock, maxSaved int) error {
+	f.apiBlocks[block.SlotNumber] = block
+	return nil
+}
+
+// newBeaconTestRouter serve the Beacon API routes from index, or from an empty index if nil.
+func newBeaconTestRouter(beaconURL string, index beaconIndex) *gin.Engine {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := &config.Config{Chain: config.ChainConfig{BeaconURL: beaconURL}}
+	// without index, the ids used are never looked up.
+	h := NewHandlers(&services.Services{}, cfg, nil, logger)
+	if index != nil {
+		h.index = index
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/eth/v1/beacon/headers", h.BeaconHeaders)
+	r.GET("/eth/v1/beacon/headers/:block_id", h.BeaconHeader)
+	r.GET("/eth/v2/beacon/blocks/:block_id", h.BeaconBlock)
+	r.GET("/eth/v1/beacon/states/:state_id/finality_checkpoints", h.BeaconFinalityCheckpoints)
+	return r
+}
+
+func TestBeaconProxy(t *testing.T) {
+	var requested []string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		if r.Header.Get("Accept") == "application/octet-stream" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Eth-Consensus-Version", "deneb")
+			w.Write([]byte{1, 2, 3})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"not found"}`))
+	}))
+	defer node.Close()
+	r := newBeaconTestRouter(node.URL, nil)
+
+	for _, target := range []string{
+		"/eth/v1/beacon/headers",
+		"/eth/v1/beacon/headers/head",
+		"/eth/v1/beacon/headers?slot=x",
+		"/eth/v2/beacon/blocks/finalized",
+		"/eth/v1/beacon/states/head/finality_checkpoints",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, target)
+		assert.JSONEq(t, `{"code":404,"message":"not found"}`, w.Body.String(), target)
+	}
+	assert.Equal(t, []string{
+		"/eth/v1/beacon/headers",
+		"/eth/v1/beacon/headers/head",
+		"/eth/v1/beacon/headers?slot=x",
+		"/eth/v2/beacon/blocks/finalized",
+		"/eth/v1/beacon/states/head/finality_checkpoints",
+	}, requested)
+
+	// ssz is only served by the beacon node.
+	req := httptest.NewRequest(http.MethodGet, "/eth/v2/beacon/blocks/head", nil)
+	req.Header.Set("Accept", "application/octet-stream")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "deneb", w.Header().Get("Eth-Consensus-Version"))
+	assert.Equal(t, []byte{1, 2, 3}, w.Body.Bytes())
+}
+
+func TestBeaconProxyUnavailable(t *testing.T) {
+	node := httptest.NewServer(http.NotFoundHandler())
+	node.Close()
+	r := newBeaconTestRouter(node.URL, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/eth/v1/beacon/headers/head", nil))
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.JSONEq(t, `{"code":502,"message":"beacon node unavailable"}`, w.Body.String())
+}
+
+func TestBeaconFromIndex(t *testing.T) {
+	var requested []string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Eth-Consensus-Version", "electra")
+		w.Write([]byte(`{"version":"electra","finalized":true,"data":{"message":{"slot":"102"}}}`))
+	}))
+	defer node.Close()
+	index := &fakeIndex{
+		blocks: map[uint64]*dbmodels.BeaconBlock{
+			100: {SlotNumber: 100, ProposerIndex: 7, BlockRoot: "0x01", ParentRoot: "0x02", StateRoot: "0x03",
+				BodyRoot: "0x04", Signature: "0x05", Finalized: true},
+			101: {SlotNumber: 101, BlockRoot: "0x06", BodyRoot: "0x07"},
+		},
+		apiBlocks: map[uint64]*dbmodels.BeaconApiBlock{
+			100: {SlotNumber: 100, Version: "electra", Body: `{"version":"electra","finalized":true}`},
+		},
+	}
+	r := newBeaconTestRouter(node.URL, index)
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	// the finalized block is served from the index.
+	w := get("/eth/v1/beacon/headers/100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"execution_optimistic":false,"finalized":true,"data":{"root":"0x01","canonical":true,
+		"header":{"message":{"slot":"100","proposer_index":"7","parent_root":"0x02","state_root":"0x03","body_root":"0x04"},
+		"signature":"0x05"}}}`, w.Body.String())
+	w = get("/eth/v2/beacon/blocks/100")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "electra", w.Header().Get("Eth-Consensus-Version"))
+	assert.JSONEq(t, `{"version":"electra","finalized":true}`, w.Body.String())
+	assert.Empty(t, requested)
+
+	// not finalized, or not saved yet, the beacon node is asked and a finalized block saved.
+	get("/eth/v1/beacon/headers/101")
+	w = get("/eth/v2/beacon/blocks/102")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"/eth/v1/beacon/headers/101", "/eth/v2/beacon/blocks/102"}, requested)
+	if assert.Contains(t, index.apiBlocks, uint64(102)) {
+		assert.Equal(t, "electra", index.apiBlocks[102].Version)
+	}
+}
+
+func TestToBeaconHeader(t *testing.T) {
+	block := &dbmodels.BeaconBlock{SlotNumber: 100, ProposerIndex: 7, BlockRoot: "0x01", ParentRoot: "0x02",
+		StateRoot: "0x03", BodyRoot: "0x04", Signature: "0x05", Finalized: true}
+	assert.True(t, servableHeader(block))
+	assert.Equal(t, &beaconHeader{
+		Root:      "0x01",
+		Canonical: true,
+		Header: beaconSignedHeader{
+			Message:   beaconHeaderMessage{Slot: "100", ProposerIndex: "7", ParentRoot: "0x02", StateRoot: "0x03", BodyRoot: "0x04"},
+			Signature: "0x05",
+		},
+	}, toBeaconHeader(block))
+
+	// indexed before the body root was recorded.
+	block.BodyRoot = ""
+	assert.False(t, servableHeader(block))
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+// GetBlockExecution return the beacon block at slot together with its execution block.
+func (h *Handlers) GetBlockExecution(c *gin.Context) {
+	slot, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid slot"})
+		return
+	}
+	block, err := h.services.BeaconBlock.GetBlockWithExecution(slot)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "block not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("get block with execution failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, block)
+}
+
+// ListBlocks return a page of blocks, filtered by epoch, proposer, slot range and graffiti.
+func (h *Handlers) ListBlocks(c *gin.Context) {
+	page, size, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	values, err := queryUints(c, "epoch", "proposer", "from_slot", "to_slot")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter := services.BlockFilter{
+		Epoch:    values[0],
+		Proposer: values[1],
+		FromSlot: values[2],
+		ToSlot:   values[3],
+		Graffiti: c.Query("graffiti"),
+	}
+	blocks, total, err := h.services.BeaconBlock.GetBlocks(filter, page, size)
+	if err != nil {
+		h.logger.WithError(err).Error("list blocks failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      blocks,
+		"page":      page,
+		"page_size": size,
+		"total":     total,
+	})
+}
+
+// GetBlock return the block identified by slot or block root.
+func (h *Handlers) GetBlock(c *gin.Context) {
+	id := c.Param("id")
+	var block *dbmodels.BeaconBlock
+	var err error
+	if isRoot(id) {
+		block, err = h.services.BeaconBlock.GetBlockByRoot(id)
+	} else {
+		slot, perr := strconv.ParseUint(id, 10, 64)
+		if perr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidBlockID.Error()})
+			return
+		}
+		block, err = h.services.BeaconBlock.GetBlockBySlot(slot)
+	}
+	h.respondBlock(c, block, err)
+}
+
+// GetLatestBlock return the most recent indexed block.
+func (h *Handlers) GetLatestBlock(c *gin.Context) {
+	block, err := h.services.BeaconBlock.GetLatestBlock()
+	h.respondBlock(c, block, err)
+}
+
+func (h *Handlers) respondBlock(c *gin.Context, block *dbmodels.BeaconBlock, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "block not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("get block failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, block)
+}
+
+var errInvalidBlockID = errors.New("invalid block id, expect slot or block root")
+
+// blockSlot resolve a block id, slot or block root, to the slot of the block.
+func (h *Handlers) blockSlot(id string) (uint64, error) {
+	if isRoot(id) {
+		block, err := h.services.BeaconBlock.GetBlockByRoot(id)
+		if err != nil {
+			return 0, err
+		}
+		return block.SlotNumber, nil
+	}
+	slot, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, errInvalidBlockID
+	}
+	return slot, nil
+}
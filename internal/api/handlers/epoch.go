@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+const slotsPerEpoch = uint64(32)
+
+// ListEpochs return a page of epoch summaries, newest first.
+func (h *Handlers) ListEpochs(c *gin.Context) {
+	page, size, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	summaries, total, err := h.services.Epoch.GetEpochSummaries(page, size)
+	if err != nil {
+		h.logger.WithError(err).Error("list epochs failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      summaries,
+		"page":      page,
+		"page_size": size,
+		"total":     total,
+	})
+}
+
+// GetEpoch return the summary of the epoch. An epoch not summarized yet, as it is not
+// finalized, only carry the block counts known so far.
+func (h *Handlers) GetEpoch(c *gin.Context) {
+	epoch, err := strconv.ParseUint(c.Param("epoch"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid epoch"})
+		return
+	}
+	summary, err := h.services.Epoch.GetEpochSummary(epoch)
+	if err == nil {
+		c.JSON(http.StatusOK, gin.H{"summarized": true, "epoch": summary})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.WithError(err).Error("get epoch summary failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	headSlot, err := h.currentSlot(time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("get chain clock failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	if epoch > headSlot/slotsPerEpoch {
+		c.JSON(http.StatusNotFound, gin.H{"error": "epoch not reached yet"})
+		return
+	}
+	proposed, err := h.services.BeaconBlock.CountBlocksByEpoch(epoch)
+	if err != nil {
+		h.logger.WithError(err).Error("count epoch blocks failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"summarized": false, "epoch": &dbmodels.EpochSummary{
+		Epoch:          epoch,
+		ProposedBlocks: uint(proposed),
+	}})
+}
+
+// SyncStatus is how far the block indexer is behind the chain clock.
+type SyncStatus struct {
+	HeadSlot    uint64 `json:"head_slot"`
+	IndexedSlot uint64 `json:"indexed_slot"`
+	LagSlots    uint64 `json:"lag_slots"`
+	LagSeconds  uint64 `json:"lag_seconds"`
+}
+
+// TaskStatus is the progress of one scan task.
+type TaskStatus struct {
+	TaskType   string    `json:"task_type"`
+	LastNumber uint64    `json:"last_number"`
+	Enabled    bool      `json:"enabled"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NetworkOverview is the current state of the chain as seen by the indexer.
+type NetworkOverview struct {
+	HeadEpoch    uint64                       `json:"head_epoch"`
+	Sync         *SyncStatus                  `json:"sync"`
+	Finality     *dbmodels.FinalityCheckpoint `json:"finality"`
+	LatestEpoch  *dbmodels.EpochSummary       `json:"latest_epoch"`
+	PendingQueue uint64                       `json:"pending_queue"`
+	ExitQueue    uint64                       `json:"exit_queue"`
+	Tasks        []*TaskStatus                `json:"tasks"`
+}
+
+// GetNetworkOverview return participation, finality, validator queues and rewards of the latest
+// summarized epoch, together with the indexer sync lag.
+func (h *Handlers) GetNetworkOverview(c *gin.Context) {
+	overview, err := h.networkOverview(time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("get network overview failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+func (h *Handlers) networkOverview(now time.Time) (*NetworkOverview, error) {
+	genesis, secondsPerSlot, err := h.chainClock()
+	if err != nil {
+		return nil, err
+	}
+	headSlot := slotAt(genesis.Unix(), secondsPerSlot, now)
+	overview := &NetworkOverview{HeadEpoch: headSlot / slotsPerEpoch}
+
+	indexedSlot := uint64(0)
+	latest, err := h.services.BeaconBlock.GetLatestBlock()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		indexedSlot = latest.SlotNumber
+	}
+	overview.Sync = syncStatus(headSlot, indexedSlot, secondsPerSlot)
+
+	if overview.Finality, err = h.services.Finality.GetLatestCheckpoint(); err != nil {
+		return nil, err
+	}
+	if overview.LatestEpoch, err = h.services.Epoch.GetLatestEpochSummary(); err != nil {
+		return nil, err
+	}
+	if overview.LatestEpoch != nil {
+		overview.PendingQueue = overview.LatestEpoch.PendingValidators
+		overview.ExitQueue = overview.LatestEpoch.ExitingValidators
+	}
+
+	tasks, err := h.services.ScanTask.GetScanTasks()
+	if err != nil {
+		return nil, err
+	}
+	overview.Tasks = make([]*TaskStatus, 0, len(tasks))
+	for _, task := range tasks {
+		overview.Tasks = append(overview.Tasks, &TaskStatus{
+			TaskType:   task.TaskType,
+			LastNumber: task.LastNumber,
+			Enabled:    task.Enabled,
+			UpdatedAt:  task.UpdatedAt,
+		})
+	}
+	return overview, nil
+}
+
+// chainClock return the genesis time and slot duration of the beacon chain, fetched from the
+// beacon node the first time.
+func (h *Handlers) chainClock() (time.Time, uint64, error) {
+	h.clockMux.Lock()
+	defer h.clockMux.Unlock()
+	if h.secondsPerSlot != 0 {
+		return h.genesis, h.secondsPerSlot, nil
+	}
+	genesis, err := h.beacon.GetGenesis()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	secondsPerSlot, err := h.beacon.GetIntConfig(beaconapi.SECONDS_PER_SLOT)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if secondsPerSlot == 0 {
+		secondsPerSlot = 12
+	}
+	h.genesis, h.secondsPerSlot = genesis.GenesisTime, uint64(secondsPerSlot)
+	return h.genesis, h.secondsPerSlot, nil
+}
+
+// currentSlot return the slot of the chain clock at t.
+func (h *Handlers) currentSlot(t time.Time) (uint64, error) {
+	genesis, secondsPerSlot, err := h.chainClock()
+	if err != nil {
+		return 0, err
+	}
+	return slotAt(genesis.Unix(), secondsPerSlot, t), nil
+}
+
+func slotAt(genesis int64, secondsPerSlot uint64, t time.Time) uint64 {
+	if secondsPerSlot == 0 || t.Unix() <= genesis {
+		return 0
+	}
+	return uint64(t.Unix()-genesis) / secondsPerSlot
+}
+
+func syncStatus(headSlot, indexedSlot, secondsPerSlot uint64) *SyncStatus {
+	status := &SyncStatus{HeadSlot: headSlot, IndexedSlot: indexedSlot}
+	if headSlot > indexedSlot {
+		status.LagSlots = headSlot - indexedSlot
+		status.LagSeconds = status.LagSlots * secondsPerSlot
+	}
+	return status
+}
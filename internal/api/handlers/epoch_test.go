@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotAt(t *testing.T) {
+	genesis := int64(1606824023)
+	assert.Equal(t, uint64(0), slotAt(genesis, 12, time.Unix(genesis-100, 0)))
+	assert.Equal(t, uint64(0), slotAt(genesis, 12, time.Unix(genesis+11, 0)))
+	assert.Equal(t, uint64(100), slotAt(genesis, 12, time.Unix(genesis+1200, 0)))
+	assert.Equal(t, uint64(0), slotAt(genesis, 0, time.Unix(genesis+1200, 0)))
+}
+
+func TestSyncStatus(t *testing.T) {
+	status := syncStatus(1000, 990, 12)
+	assert.Equal(t, uint64(10), status.LagSlots)
+	assert.Equal(t, uint64(120), status.LagSeconds)
+
+	status = syncStatus(1000, 1000, 12)
+	assert.Equal(t, uint64(0), status.LagSlots)
+}
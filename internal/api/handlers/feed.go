@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
+)
+
+const (
+	feedWriteWait    = 10 * time.Second
+	feedPongWait     = 60 * time.Second
+	feedPingInterval = 30 * time.Second
+	feedReadLimit    = 4096
+)
+
+// feedTopics are the topics served by the live feeds, attestations are too many for clients.
+var feedTopics = []string{stream.TopicBlocks, stream.TopicEpochs, stream.TopicFinality, stream.TopicReorgs, stream.TopicValidators}
+
+var streamIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// errChainClock is returned when from_slot cannot be mapped to a stream id, the beacon node
+// serving the genesis time is not reachable.
+var errChainClock = errors.New("beacon node unavailable")
+
+// feedCommand change the topics of a websocket subscription.
+type feedCommand struct {
+	Op     string   `json:"op"` // "subscribe" or "unsubscribe"
+	Topics []string `json:"topics"`
+}
+
+// feedSubscription is the set of topics a client want, changed while the feed is running.
+type feedSubscription struct {
+	mux    sync.RWMutex
+	topics map[string]bool
+}
+
+func newFeedSubscription(topics []string) *feedSubscription {
+	sub := &feedSubscription{topics: make(map[string]bool)}
+	sub.apply("subscribe", topics)
+	return sub
+}
+
+func (s *feedSubscription) has(topic string) bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.topics[topic]
+}
+
+func (s *feedSubscription) apply(op string, topics []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, topic := range topics {
+		if op == "subscribe" {
+			s.topics[topic] = true
+		} else {
+			delete(s.topics, topic)
+		}
+	}
+}
+
+func (s *feedSubscription) list() []string {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	topics := make([]string, 0, len(s.topics))
+	for _, topic := range feedTopics {
+		if s.topics[topic] {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// feedConn serialize the writes to a websocket, which allow one writer at a time.
+type feedConn struct {
+	*websocket.Conn
+	mux sync.Mutex
+}
+
+func (c *feedConn) send(v interface{}) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.SetWriteDeadline(time.Now().Add(feedWriteWait))
+	return c.WriteJSON(v)
+}
+
+// FeedWebSocket stream the live feed over a websocket. The topics query select the initial
+// topics, changed later by sending feed commands; last_id or from_slot resume the feed.
+func (h *Handlers) FeedWebSocket(c *gin.Context) {
+	topics, start, ok := h.feedParams(c)
+	// the topics may be changed later, the position must be retained for all of them.
+	if !ok || !h.checkFeedStart(c, feedTopics, start) {
+		return
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already responded.
+		return
+	}
+	conn := &feedConn{Conn: ws}
+	defer conn.Close()
+
+	sub := newFeedSubscription(topics)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.readFeedCommands(conn, sub, cancel)
+	go pingFeed(ctx, conn)
+
+	err = h.feed.Tail(ctx, feedTopics, start, func(msg stream.Message) error {
+		if !sub.has(msg.Topic) {
+			return nil
+		}
+		return conn.send(msg)
+	})
+	if err != nil && ctx.Err() == nil {
+		h.logger.WithError(err).Debug("websocket feed stopped")
+	}
+}
+
+// readFeedCommands apply the commands of the client until the connection is closed.
+func (h *Handlers) readFeedCommands(conn *feedConn, sub *feedSubscription, cancel context.CancelFunc) {
+	defer cancel()
+	conn.SetReadLimit(feedReadLimit)
+	conn.SetReadDeadline(time.Now().Add(feedPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(feedPongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var cmd feedCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			conn.send(gin.H{"error": "invalid command"})
+			continue
+		}
+		if cmd.Op != "subscribe" && cmd.Op != "unsubscribe" {
+			conn.send(gin.H{"error": "op must be subscribe or unsubscribe"})
+			continue
+		}
+		if err := checkFeedTopics(cmd.Topics); err != nil {
+			conn.send(gin.H{"error": err.Error()})
+			continue
+		}
+		sub.apply(cmd.Op, cmd.Topics)
+		conn.send(gin.H{"topics": sub.list()})
+	}
+}
+
+func pingFeed(ctx context.Context, conn *feedConn) {
+	ticker := time.NewTicker(feedPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(feedWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// FeedEvents stream the live feed as server-sent events, with the same query as the websocket.
+// The Last-Event-ID header of a reconnecting client resume the feed.
+func (h *Handlers) FeedEvents(c *gin.Context) {
+	topics, start, ok := h.feedParams(c)
+	if !ok || !h.checkFeedStart(c, topics, start) {
+		return
+	}
+	// the stream outlive the write timeout of the server.
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	var mux sync.Mutex
+	write := func(format string, args ...interface{}) error {
+		mux.Lock()
+		defer mux.Unlock()
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(feedPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := write(": ping\n\n"); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := h.feed.Tail(ctx, topics, start, func(msg stream.Message) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return write("id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Topic, data)
+	})
+	if err != nil && ctx.Err() == nil {
+		h.logger.WithError(err).Debug("event feed stopped")
+	}
+}
+
+// feedParams parse the topics and the resume position of a feed request, and respond an
+// error if invalid.
+func (h *Handlers) feedParams(c *gin.Context) ([]string, string, bool) {
+	if h.feed == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "live feed is not available"})
+		return nil, "", false
+	}
+	topics := feedTopics
+	if value := c.Query("topics"); value != "" {
+		topics = strings.Split(value, ",")
+		if err := checkFeedTopics(topics); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, "", false
+		}
+	}
+	start, err := h.feedStart(c)
+	if errors.Is(err, errChainClock) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return nil, "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, "", false
+	}
+	return topics, start, true
+}
+
+// checkFeedStart verify the entries of the topics after start are still retained, and respond
+// an error if some were trimmed.
+func (h *Handlers) checkFeedStart(c *gin.Context, topics []string, start string) bool {
+	if start == "$" {
+		return true
+	}
+	err := h.feed.Check(c.Request.Context(), topics, start)
+	if errors.Is(err, stream.ErrTrimmed) {
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return false
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("check feed position failed")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "live feed is not available"})
+		return false
+	}
+	return true
+}
+
+// feedStart return the stream id to resume after: the last received id, else the start of
+// from_slot, else "$" for new entries only.
+func (h *Handlers) feedStart(c *gin.Context) (string, error) {
+	lastID := c.Query("last_id")
+	if lastID == "" {
+		lastID = c.GetHeader("Last-Event-ID")
+	}
+	if lastID != "" {
+		if !streamIDPattern.MatchString(lastID) {
+			return "", errors.New("invalid last_id")
+		}
+		return lastID, nil
+	}
+	if value := c.Query("from_slot"); value != "" {
+		slot, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", errors.New("invalid from_slot")
+		}
+		genesis, secondsPerSlot, err := h.chainClock()
+		if err != nil {
+			h.logger.WithError(err).Error("get chain clock failed")
+			return "", errChainClock
+		}
+		return slotStartID(genesis.Unix(), secondsPerSlot, slot), nil
+	}
+	return "$", nil
+}
+
+// slotStartID return the stream id just before the start of slot. Everything about the slot
+// or later is published after the slot started, so reading after this id miss none of it.
+func slotStartID(genesis int64, secondsPerSlot uint64, slot uint64) string {
+	ms := (genesis+int64(slot*secondsPerSlot))*1000 - 1
+	if ms < 0 {
+		return "0-0"
+	}
+	return fmt.Sprintf("%d-0", ms)
+}
+
+func checkFeedTopics(topics []string) error {
+	for _, topic := range topics {
+		known := false
+		for _, t := range feedTopics {
+			known = known || t == topic
+		}
+		if !known {
+			return fmt.Errorf("unknown topic %q", topic)
+		}
+	}
+	return nil
+}
+
+// checkOrigin accept websocket connections from the allowed CORS origins.
+func (h *Handlers) checkOrigin(r *http.Request) bool {
+	origins := h.config.Server.CorsOrigins
+	if len(origins) == 0 {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// not a browser.
+		return true
+	}
+	for _, allowed := range origins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
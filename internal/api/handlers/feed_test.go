@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redismock/v9"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
+)
+
+func TestSlotStartID(t *testing.T) {
+	assert.Equal(t, "1606824022999-0", slotStartID(1606824023, 12, 0))
+	assert.Equal(t, "1606824034999-0", slotStartID(1606824023, 12, 1))
+	assert.Equal(t, "0-0", slotStartID(0, 12, 0))
+}
+
+func TestCheckFeedTopics(t *testing.T) {
+	assert.NoError(t, checkFeedTopics([]string{stream.TopicBlocks, stream.TopicValidators}))
+	assert.Error(t, checkFeedTopics([]string{stream.TopicAttestations}))
+	assert.Error(t, checkFeedTopics([]string{"unknown"}))
+}
+
+func TestFeedSubscription(t *testing.T) {
+	sub := newFeedSubscription([]string{stream.TopicReorgs, stream.TopicBlocks})
+	assert.Equal(t, []string{stream.TopicBlocks, stream.TopicReorgs}, sub.list())
+	sub.apply("unsubscribe", []string{stream.TopicBlocks})
+	sub.apply("subscribe", []string{stream.TopicFinality})
+	assert.False(t, sub.has(stream.TopicBlocks))
+	assert.True(t, sub.has(stream.TopicFinality))
+}
+
+func TestFeedWebSocket(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := NewHandlers(nil, &config.Config{}, stream.NewSubscriber(client, "beacon"), logger)
+
+	// new entries only, after the last entry of every topic, only blocks are delivered.
+	streams := []string{"beacon:blocks", "beacon:epochs", "beacon:finality", "beacon:reorgs", "beacon:validators"}
+	ids := []string{"10-0", "10-0", "10-0", "10-0", "10-0"}
+	for _, key := range streams {
+		mock.ExpectXRevRangeN(key, "+", "-", 1).SetVal([]redis.XMessage{{ID: "10-0"}})
+	}
+	mock.ExpectXRead(&redis.XReadArgs{
+		Streams: append(streams, ids...),
+		Count:   100,
+		Block:   5 * time.Second,
+	}).SetVal([]redis.XStream{
+		{Stream: "beacon:reorgs", Messages: []redis.XMessage{{ID: "11-0", Values: map[string]interface{}{"type": "reorg", "data": `{}`}}}},
+		{Stream: "beacon:blocks", Messages: []redis.XMessage{{ID: "12-0", Values: map[string]interface{}{"type": "block", "data": `{"slot_number":5}`}}}},
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/ws", h.FeedWebSocket)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?topics=blocks"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var msg stream.Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "12-0", msg.ID)
+	assert.Equal(t, stream.TopicBlocks, msg.Topic)
+	assert.JSONEq(t, `{"slot_number":5}`, string(msg.Data))
+}
+
+func TestFeedTrimmed(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := NewHandlers(nil, &config.Config{}, stream.NewSubscriber(client, "beacon"), logger)
+	mock.ExpectXInfoStream("beacon:blocks").SetVal(&redis.XInfoStream{MaxDeletedEntryID: "20-0"})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/events", h.FeedEvents)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?topics=blocks&last_id=10-0", nil))
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
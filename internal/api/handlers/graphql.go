@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xueqianLu/deep-dive-beacon/internal/api/gql"
+	"github.com/xueqianLu/deep-dive-beacon/internal/api/middleware"
+)
+
+// GraphQL run a GraphQL query, posted as a json body or passed as the query, operationName and
+// variables query parameters. Query errors are reported in the response with status 200.
+func (h *Handlers) GraphQL(c *gin.Context) {
+	var req gql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if value := c.Query("variables"); value != "" {
+			if err := json.Unmarshal([]byte(value), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variables"})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+		return
+	}
+	ctx := gql.WithAPIKey(c.Request.Context(), middleware.GetAPIKey(c))
+	c.JSON(http.StatusOK, h.graphql.Exec(ctx, &req))
+}
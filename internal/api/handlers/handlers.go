@@ -2,22 +2,46 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/internal/api/gql"
 	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type Handlers struct {
 	services *services.Services
+	config   *config.Config
+	feed     *stream.Subscriber
+	graphql  *gql.Executor
+	beacon   *beaconapi.BeaconClient
+	index    beaconIndex
 	logger   *logrus.Logger
+
+	clockMux       sync.Mutex
+	genesis        time.Time
+	secondsPerSlot uint64
 }
 
-func NewHandlers(services *services.Services, logger *logrus.Logger) *Handlers {
-	return &Handlers{
+// NewHandlers create the handlers, the live feeds are not available if feed is nil.
+func NewHandlers(services *services.Services, cfg *config.Config, feed *stream.Subscriber, logger *logrus.Logger) *Handlers {
+	h := &Handlers{
 		services: services,
+		config:   cfg,
+		feed:     feed,
+		graphql:  gql.NewExecutor(services, cfg.GraphQL, logger),
+		beacon:   beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
 		logger:   logger,
 	}
+	if services != nil {
+		h.index = &serviceIndex{BeaconBlockService: services.BeaconBlock, FinalityService: services.Finality}
+	}
+	return h
 }
 
 func (h *Handlers) Health(c *gin.Context) {
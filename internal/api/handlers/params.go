@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePage read the page and page_size query parameters, page start at 1.
+func parsePage(c *gin.Context) (int, int, error) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, fmt.Errorf("invalid page")
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || size < 1 || size > maxPageSize {
+		return 0, 0, fmt.Errorf("invalid page_size, must be between 1 and %d", maxPageSize)
+	}
+	return page, size, nil
+}
+
+// queryUint read an optional unsigned integer query parameter, nil if absent.
+func queryUint(c *gin.Context, name string) (*uint64, error) {
+	value, exist := c.GetQuery(name)
+	if !exist || value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &n, nil
+}
+
+// queryUints read several optional unsigned integer query parameters, stopping at the first invalid one.
+func queryUints(c *gin.Context, names ...string) ([]*uint64, error) {
+	values := make([]*uint64, 0, len(names))
+	for _, name := range names {
+		v, err := queryUint(c, name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// isRoot return true if id look like a 32 bytes hex root.
+func isRoot(id string) bool {
+	return isHex(id, 32)
+}
+
+// isPubkey return true if id look like a 48 bytes hex BLS public key.
+func isPubkey(id string) bool {
+	return isHex(id, 48)
+}
+
+// isAddress return true if id look like a 20 bytes hex execution address.
+func isAddress(id string) bool {
+	return isHex(id, 20)
+}
+
+// isHex return true if s is 0x followed by size bytes of hex.
+func isHex(s string, size int) bool {
+	if len(s) != 2+size*2 || s[:2] != "0x" {
+		return false
+	}
+	for _, ch := range s[2:] {
+		if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	defaultCursorLimit = 100
+	maxCursorLimit     = 1000
+)
+
+// parseCursor read the cursor and limit query parameters of cursor paginated lists,
+// an absent cursor start from the newest entry.
+func parseCursor(c *gin.Context) (uint, int, error) {
+	cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultCursorLimit)))
+	if err != nil || limit < 1 || limit > maxCursorLimit {
+		return 0, 0, fmt.Errorf("invalid limit, must be between 1 and %d", maxCursorLimit)
+	}
+	return uint(cursor), limit, nil
+}
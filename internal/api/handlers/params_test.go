@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContext(target string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func TestParsePage(t *testing.T) {
+	page, size, err := parsePage(newContext("/blocks"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, page)
+	assert.Equal(t, defaultPageSize, size)
+
+	page, size, err = parsePage(newContext("/blocks?page=3&page_size=50"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, page)
+	assert.Equal(t, 50, size)
+
+	_, _, err = parsePage(newContext("/blocks?page=0"))
+	assert.Error(t, err)
+	_, _, err = parsePage(newContext("/blocks?page_size=1000"))
+	assert.Error(t, err)
+}
+
+func TestQueryUints(t *testing.T) {
+	values, err := queryUints(newContext("/blocks?epoch=10&to_slot="), "epoch", "proposer", "to_slot")
+	require.NoError(t, err)
+	require.NotNil(t, values[0])
+	assert.Equal(t, uint64(10), *values[0])
+	assert.Nil(t, values[1])
+	assert.Nil(t, values[2])
+
+	_, err = queryUints(newContext("/blocks?proposer=-1"), "epoch", "proposer")
+	assert.EqualError(t, err, "invalid proposer")
+}
+
+func TestIsRoot(t *testing.T) {
+	assert.True(t, isRoot("0x4d611d5b93fdab69013a7f0a2f961caca0c853f87cfe9595fe50038163079360"))
+	assert.False(t, isRoot("4d611d5b93fdab69013a7f0a2f961caca0c853f87cfe9595fe50038163079360"))
+	assert.False(t, isRoot("0x4d61"))
+	assert.False(t, isRoot("123456"))
+	assert.True(t, isAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa"))
+	assert.False(t, isAddress("0x00000000219ab540356cBB839Cbe05303d7705F"))
+	assert.True(t, isPubkey("0x"+strings.Repeat("a1", 48)))
+}
+
+func TestParseCursor(t *testing.T) {
+	cursor, limit, err := parseCursor(newContext("/attestations"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), cursor)
+	assert.Equal(t, defaultCursorLimit, limit)
+
+	cursor, limit, err = parseCursor(newContext("/attestations?cursor=1234&limit=10"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(1234), cursor)
+	assert.Equal(t, 10, limit)
+
+	_, _, err = parseCursor(newContext("/attestations?limit=5000"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchLimit bound the graffiti matches of one search.
+const searchLimit = 20
+
+// Search return the typed matches of q: slots, epochs, block and state roots, validator
+// indices and pubkeys, execution block hashes or graffiti substrings.
+func (h *Handlers) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing q"})
+		return
+	}
+	results, err := h.services.Search.Search(q, searchLimit)
+	if err != nil {
+		h.logger.WithError(err).Error("search failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"query": q, "data": results})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+)
+
+const (
+	// profileBalanceEpochs is the number of recent balance samples in a validator profile, about one day.
+	profileBalanceEpochs = 225
+	defaultProfileDays   = 30
+	maxProfileDays       = 365
+	defaultProfileDuties = 50
+	maxProfileDuties     = 500
+)
+
+// ValidatorProfile gather everything indexed about one validator.
+type ValidatorProfile struct {
+	Validator       *dbmodels.Validator               `json:"validator"`
+	Balances        []*dbmodels.ValidatorBalance      `json:"balances"`
+	DailyBalances   []*dbmodels.ValidatorBalanceDaily `json:"daily_balances"`
+	Rewards         []*services.DailyReward           `json:"rewards"`
+	TotalRewards    int64                             `json:"total_rewards"`
+	ProposerDuties  []*dbmodels.ProposerDuty          `json:"proposer_duties"`
+	AttesterDuties  []*dbmodels.AttesterDuty          `json:"attester_duties"`
+	Attestations    *services.AttestationPerformance  `json:"attestation_performance"`
+	History         []*dbmodels.ValidatorHistory      `json:"history"`
+	SlashingHistory []*dbmodels.ValidatorHistory      `json:"slashing_history"`
+}
+
+// GetValidator return the profile of the validator identified by index or pubkey. The days
+// query parameter bound the daily balances and rewards, limit the number of recent duties.
+func (h *Handlers) GetValidator(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultProfileDays)))
+	if err != nil || days < 1 || days > maxProfileDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultProfileDuties)))
+	if err != nil || limit < 1 || limit > maxProfileDuties {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+	val, err := h.findValidator(c.Param("id"))
+	if errors.Is(err, errInvalidValidatorID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "validator not found"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("get validator failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	profile, err := h.validatorProfile(val, days, limit)
+	if err != nil {
+		h.logger.WithField("validator", val.ValidatorIndex).WithError(err).Error("get validator profile failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+var errInvalidValidatorID = errors.New("invalid validator id, expect index or pubkey")
+
+func (h *Handlers) findValidator(id string) (*dbmodels.Validator, error) {
+	if isPubkey(id) {
+		return h.services.Validator.GetValidatorByPubkey(strings.ToLower(id))
+	}
+	index, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, errInvalidValidatorID
+	}
+	return h.services.Validator.GetValidatorByIndex(index)
+}
+
+func (h *Handlers) validatorProfile(val *dbmodels.Validator, days int, limit int) (*ValidatorProfile, error) {
+	index := val.ValidatorIndex
+	profile := &ValidatorProfile{Validator: val}
+
+	latestEpoch := uint64(0)
+	latest, err := h.services.BeaconBlock.GetLatestBlock()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		latestEpoch = latest.EpochNumber
+	}
+	fromEpoch := uint64(0)
+	if latestEpoch > profileBalanceEpochs {
+		fromEpoch = latestEpoch - profileBalanceEpochs
+	}
+	if profile.Balances, err = h.services.Balance.GetBalanceHistory(index, fromEpoch, latestEpoch); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if profile.DailyBalances, err = h.services.Balance.GetDailyBalances(index, now.AddDate(0, 0, -days), now); err != nil {
+		return nil, err
+	}
+	rewards, err := h.services.Balance.GetDailyRewards(index, now.AddDate(0, 0, -days), now)
+	if err != nil {
+		return nil, err
+	}
+	profile.Rewards, profile.TotalRewards = services.DailyRewards(rewards)
+
+	if profile.ProposerDuties, err = h.services.Duty.GetProposerDutiesByValidator(index, limit); err != nil {
+		return nil, err
+	}
+	if profile.AttesterDuties, err = h.services.Duty.GetAttesterDutiesByValidator(index, limit); err != nil {
+		return nil, err
+	}
+	if profile.Attestations, err = h.services.Duty.GetAttestationPerformance(index, 0); err != nil {
+		return nil, err
+	}
+	if profile.History, err = h.services.Validator.GetValidatorHistory(index); err != nil {
+		return nil, err
+	}
+	if profile.SlashingHistory, err = h.services.Validator.GetSlashingHistory(index); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ListValidators return a page of the validators withdrawing to withdrawal_address.
+func (h *Handlers) ListValidators(c *gin.Context) {
+	address := c.Query("withdrawal_address")
+	if !isAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or missing withdrawal_address"})
+		return
+	}
+	page, size, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	vals, total, err := h.services.Validator.GetValidatorsByWithdrawalAddress(address, page, size)
+	if err != nil {
+		h.logger.WithError(err).Error("list validators failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      vals,
+		"page":      page,
+		"page_size": size,
+		"total":     total,
+	})
+}
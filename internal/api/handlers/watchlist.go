@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type watchRequest struct {
+	ValidatorIndex *uint64 `json:"validator_index" binding:"required"`
+	Label          string  `json:"label"`
+}
+
+// ListWatchlist return the watched validators.
+func (h *Handlers) ListWatchlist(c *gin.Context) {
+	watched, err := h.services.Watchlist.List()
+	if err != nil {
+		h.logger.WithError(err).Error("list watchlist failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, watched)
+}
+
+// AddWatchlist watch a validator, or update its label.
+func (h *Handlers) AddWatchlist(c *gin.Context) {
+	var req watchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	watched, err := h.services.Watchlist.Add(*req.ValidatorIndex, req.Label)
+	if err != nil {
+		h.logger.WithError(err).Error("add watchlist failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	c.JSON(http.StatusOK, watched)
+}
+
+// RemoveWatchlist stop watching a validator.
+func (h *Handlers) RemoveWatchlist(c *gin.Context) {
+	index, err := strconv.ParseUint(c.Param("index"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid validator index"})
+		return
+	}
+	removed, err := h.services.Watchlist.Remove(index)
+	if err != nil {
+		h.logger.WithError(err).Error("remove watchlist failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "validator not watched"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -1,16 +1,41 @@
 package middleware
 
 import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/xueqianLu/deep-dive-beacon/internal/ratelimit"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
 )
 
-// CORS middleware
-func CORS() gin.HandlerFunc {
+const apiKeyContextKey = "api_key"
+
+// KeyStore look up API keys, it return nil for unknown or revoked keys.
+type KeyStore interface {
+	GetApiKey(plain string) (*dbmodels.ApiKey, error)
+}
+
+// CORS middleware, credentials are only allowed for the configured origins. Any origin may
+// call the API without credentials if no origin is configured.
+func CORS(origins []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
 	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		if len(allowed) == 0 || allowed["*"] {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else if origin := c.GetHeader("Origin"); allowed[origin] {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		c.Header("Vary", "Origin")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, accept, origin, Cache-Control, X-Requested-With")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -34,3 +59,111 @@ func RequestID() gin.HandlerFunc {
 		c.Next()
 	})
 }
+
+// APIKey middleware, authenticate the key given in the X-API-Key header or as bearer token.
+// Requests without key pass as anonymous, requests with an unknown key are rejected.
+func APIKey(store KeyStore) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		plain := presentedKey(c)
+		if plain == "" {
+			c.Next()
+			return
+		}
+		key, err := store.GetApiKey(plain)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		if key == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+			return
+		}
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	})
+}
+
+// presentedKey return the plain key given in the X-API-Key header or as bearer token.
+func presentedKey(c *gin.Context) string {
+	plain := c.GetHeader("X-API-Key")
+	if token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); plain == "" && found {
+		plain = token
+	}
+	return plain
+}
+
+// GetAPIKey return the key authenticated by the APIKey middleware, or nil for anonymous requests.
+func GetAPIKey(c *gin.Context) *dbmodels.ApiKey {
+	if v, exist := c.Get(apiKeyContextKey); exist {
+		return v.(*dbmodels.ApiKey)
+	}
+	return nil
+}
+
+// RequireScope middleware, reject requests whose API key does not grant scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		key := GetAPIKey(c)
+		if key == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "api key required"})
+			return
+		}
+		if !services.HasScope(key, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("api key lacks the %s scope", scope)})
+			return
+		}
+		c.Next()
+	})
+}
+
+// RateLimitIP middleware, limit the requests per minute of every client ip. It run before the
+// key check so that guessing keys is limited too: anonymous requests are allowed anonymousLimit
+// requests, requests with a key are allowed keyIPLimit requests in a bucket of their own. The
+// limit of the key itself is left to RateLimitKey.
+func RateLimitIP(limiter *ratelimit.Limiter, anonymousLimit, keyIPLimit int) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		bucket, limit := "ip:"+c.ClientIP(), anonymousLimit
+		if presentedKey(c) != "" {
+			bucket, limit = "keyip:"+c.ClientIP(), keyIPLimit
+		}
+		if allowRequest(c, limiter, bucket, limit) {
+			c.Next()
+		}
+	})
+}
+
+// RateLimitKey middleware, limit the requests per minute of every API key, to its own limit or
+// defaultLimit. Anonymous requests are left to RateLimitIP.
+func RateLimitKey(limiter *ratelimit.Limiter, defaultLimit int) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		key := GetAPIKey(c)
+		if key == nil {
+			c.Next()
+			return
+		}
+		limit := defaultLimit
+		if key.RateLimit > 0 {
+			limit = key.RateLimit
+		}
+		if allowRequest(c, limiter, fmt.Sprintf("key:%d", key.ID), limit) {
+			c.Next()
+		}
+	})
+}
+
+// allowRequest take a token from the bucket, or abort the request if it is empty. Requests are
+// allowed if redis is not available.
+func allowRequest(c *gin.Context, limiter *ratelimit.Limiter, bucket string, limit int) bool {
+	res, err := limiter.Allow(c.Request.Context(), bucket, limit)
+	if err != nil {
+		return true
+	}
+	c.Header("X-RateLimit-Limit", fmt.Sprint(limit))
+	c.Header("X-RateLimit-Remaining", fmt.Sprint(res.Remaining))
+	if !res.Allowed {
+		c.Header("Retry-After", fmt.Sprint(int64(math.Ceil(res.RetryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		return false
+	}
+	return true
+}
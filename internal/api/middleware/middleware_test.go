@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/ratelimit"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+type staticKeys map[string]*dbmodels.ApiKey
+
+func (k staticKeys) GetApiKey(plain string) (*dbmodels.ApiKey, error) {
+	return k[plain], nil
+}
+
+var testKeys = staticKeys{
+	"reader": {ID: 1, Scopes: constant.API_SCOPE_READ},
+	"admin":  {ID: 2, Scopes: constant.API_SCOPE_ADMIN, RateLimit: 10},
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(r *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireScope(t *testing.T) {
+	r := gin.New()
+	r.GET("/test", APIKey(testKeys), RequireScope(constant.API_SCOPE_ADMIN), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	for _, tc := range []struct {
+		header string
+		value  string
+		code   int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"X-API-Key", "unknown", http.StatusUnauthorized},
+		{"X-API-Key", "reader", http.StatusForbidden},
+		{"X-API-Key", "admin", http.StatusOK},
+		{"Authorization", "Bearer admin", http.StatusOK},
+		{"Authorization", "admin", http.StatusUnauthorized},
+	} {
+		assert.Equal(t, tc.code, serve(r, tc.header, tc.value).Code, tc.value)
+	}
+}
+
+func TestAnonymousRead(t *testing.T) {
+	r := gin.New()
+	r.GET("/test", APIKey(testKeys), func(c *gin.Context) {
+		assert.Nil(t, GetAPIKey(c))
+		c.Status(http.StatusOK)
+	})
+	assert.Equal(t, http.StatusOK, serve(r, "", "").Code)
+}
+
+func TestRateLimit(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.MatchExpectationsInOrder(true)
+	mock.Regexp().ExpectEvalSha(".*", []string{"ratelimit:keyip:192.0.2.1"}, float64(6000)/60, 6000).
+		SetVal([]interface{}{int64(1), int64(5999), int64(0)})
+	mock.Regexp().ExpectEvalSha(".*", []string{"ratelimit:key:2"}, float64(10)/60, 10).
+		SetVal([]interface{}{int64(0), int64(0), int64(5500)})
+	limiter := ratelimit.NewLimiter(client, "ratelimit:")
+	r := gin.New()
+	r.GET("/test", RateLimitIP(limiter, 60, 6000), APIKey(testKeys), RateLimitKey(limiter, 600), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := serve(r, "X-API-Key", "admin")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "6", w.Header().Get("Retry-After"))
+	assert.Equal(t, "10", w.Header().Get("X-RateLimit-Limit"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// countingKeys count the lookups, which must not happen once the client ip is limited.
+type countingKeys struct {
+	lookups int
+}
+
+func (k *countingKeys) GetApiKey(plain string) (*dbmodels.ApiKey, error) {
+	k.lookups++
+	return nil, nil
+}
+
+func TestRateLimitBeforeKeyCheck(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.Regexp().ExpectEvalSha(".*", []string{"ratelimit:keyip:192.0.2.1"}, float64(6000)/60, 6000).
+		SetVal([]interface{}{int64(0), int64(0), int64(100)})
+	limiter := ratelimit.NewLimiter(client, "ratelimit:")
+	keys := &countingKeys{}
+	r := gin.New()
+	r.GET("/test", RateLimitIP(limiter, 60, 6000), APIKey(keys), RateLimitKey(limiter, 600), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusTooManyRequests, serve(r, "X-API-Key", "guess").Code)
+	assert.Equal(t, 0, keys.lookups)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCORS(t *testing.T) {
+	newRouter := func(origins []string) *gin.Engine {
+		r := gin.New()
+		r.GET("/test", CORS(origins), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+
+	w := serve(newRouter(nil), "Origin", "https://example.com")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	r := newRouter([]string{"https://example.com"})
+	w = serve(r, "Origin", "https://example.com")
+	assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = serve(r, "Origin", "https://evil.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
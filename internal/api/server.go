@@ -7,9 +7,12 @@ import (
 	"time"
 
 	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
 	"github.com/xueqianLu/deep-dive-beacon/internal/api/handlers"
 	"github.com/xueqianLu/deep-dive-beacon/internal/api/middleware"
+	"github.com/xueqianLu/deep-dive-beacon/internal/ratelimit"
 	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -23,6 +26,8 @@ type Server struct {
 	redis    *redis.Client
 	logger   *logrus.Logger
 	services *services.Services
+	keys     middleware.KeyStore
+	limiter  *ratelimit.Limiter
 	router   *gin.Engine
 }
 
@@ -30,7 +35,11 @@ func NewServer(cfg *config.Config, db *gorm.DB, rdb *redis.Client, logger *logru
 	// Initialize services
 	svc := services.NewServices(db, rdb, logger, cfg)
 	// Initialize handlers
-	h := handlers.NewHandlers(svc, logger)
+	var feed *stream.Subscriber
+	if rdb != nil && cfg.Stream.Enabled {
+		feed = stream.NewSubscriber(rdb, cfg.Stream.Prefix)
+	}
+	h := handlers.NewHandlers(svc, cfg, feed, logger)
 
 	// Create server instance
 	server := &Server{
@@ -39,6 +48,8 @@ func NewServer(cfg *config.Config, db *gorm.DB, rdb *redis.Client, logger *logru
 		redis:    rdb,
 		logger:   logger,
 		services: svc,
+		keys:     svc.ApiKey,
+		limiter:  ratelimit.NewLimiter(rdb, "ratelimit:"),
 	}
 
 	// Setup router
@@ -56,20 +67,75 @@ func (s *Server) setupRouter(h *handlers.Handlers) {
 	}
 
 	r := gin.New()
+	// the client ip is taken from the forwarded headers of the configured proxies only.
+	if err := r.SetTrustedProxies(s.config.Server.TrustedProxies); err != nil {
+		s.logger.WithError(err).Error("invalid trusted proxies, forwarded headers are ignored")
+		r.SetTrustedProxies(nil)
+	}
 
 	// Add middleware
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
-	r.Use(middleware.CORS())
+	r.Use(middleware.CORS(s.config.Server.CorsOrigins))
 	r.Use(middleware.RequestID())
 
 	// Health check
 	r.GET("/health", h.Health)
 
+	// the API key, rate limit and read scope checks of the public routes
+	public := []gin.HandlerFunc{
+		middleware.RateLimitIP(s.limiter, s.config.Auth.AnonymousRateLimit, s.config.Auth.KeyIPRateLimit),
+		middleware.APIKey(s.keys),
+		middleware.RateLimitKey(s.limiter, s.config.Auth.DefaultRateLimit),
+	}
+	if s.config.Auth.RequireKey {
+		public = append(public, middleware.RequireScope(constant.API_SCOPE_READ))
+	}
+
 	// API v1 routes
-	v1 := r.Group("/api/v1")
+	v1 := r.Group("/api/v1", public...)
 	{
 		v1.GET("/health", h.Health)
+		v1.GET("/blocks", h.ListBlocks)
+		v1.GET("/blocks/latest", h.GetLatestBlock)
+		v1.GET("/blocks/:id", h.GetBlock)
+		v1.GET("/blocks/:id/execution", h.GetBlockExecution)
+		v1.GET("/blocks/:id/attestations", h.GetBlockAttestations)
+		v1.GET("/attestations", h.ListAttestations)
+		v1.GET("/validators", h.ListValidators)
+		v1.GET("/validators/:id", h.GetValidator)
+		v1.GET("/epochs", h.ListEpochs)
+		v1.GET("/epochs/:epoch", h.GetEpoch)
+		v1.GET("/network/overview", h.GetNetworkOverview)
+		v1.GET("/search", h.Search)
+		v1.GET("/graphql", h.GraphQL)
+		v1.POST("/graphql", h.GraphQL)
+		v1.GET("/ws", h.FeedWebSocket)
+		v1.GET("/events", h.FeedEvents)
+		v1.GET("/watchlist", h.ListWatchlist)
+		v1.POST("/watchlist", middleware.RequireScope(constant.API_SCOPE_ADMIN), h.AddWatchlist)
+		v1.DELETE("/watchlist/:index", middleware.RequireScope(constant.API_SCOPE_ADMIN), h.RemoveWatchlist)
+	}
+
+	// Admin routes
+	admin := v1.Group("/admin", middleware.RequireScope(constant.API_SCOPE_ADMIN))
+	{
+		admin.GET("/tasks", h.ListTasks)
+		admin.POST("/tasks", h.CreateTask)
+		admin.POST("/tasks/:kind/:id/pause", h.PauseTask)
+		admin.POST("/tasks/:kind/:id/resume", h.ResumeTask)
+		admin.POST("/tasks/:kind/:id/reset", h.ResetTask)
+		admin.DELETE("/tasks/:kind/:id", h.CancelTask)
+		admin.POST("/reindex", h.Reindex)
+	}
+
+	// Beacon API routes, finalized data is served from the index and the rest by the beacon node
+	eth := r.Group("/eth", public...)
+	{
+		eth.GET("/v1/beacon/headers", h.BeaconHeaders)
+		eth.GET("/v1/beacon/headers/:block_id", h.BeaconHeader)
+		eth.GET("/v2/beacon/blocks/:block_id", h.BeaconBlock)
+		eth.GET("/v1/beacon/states/:state_id/finality_checkpoints", h.BeaconFinalityCheckpoints)
 	}
 
 	s.router = r
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/api/handlers"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func newTestServer() *Server {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewServer(&config.Config{}, nil, nil, logger)
+}
+
+func TestRoutes(t *testing.T) {
+	s := newTestServer()
+	for target, code := range map[string]int{
+		"/health":                      http.StatusOK,
+		"/api/v1/blocks?page=0":        http.StatusBadRequest,
+		"/api/v1/blocks/not-a-slot":    http.StatusBadRequest,
+		"/api/v1/blocks/abc/execution": http.StatusBadRequest,
+		"/api/v1/blocks?epoch=x":       http.StatusBadRequest,
+		"/api/v1/ws":                   http.StatusServiceUnavailable,
+		"/api/v1/events":               http.StatusServiceUnavailable,
+		"/api/v1/graphql":              http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, code, w.Code, target)
+	}
+}
+
+func TestGraphQLRoute(t *testing.T) {
+	s := newTestServer()
+	w := httptest.NewRecorder()
+	body := `{"query":"query Q($slot: Int) { block(slot: $slot, root: \"0x00\") { slot } }","variables":{"slot":1}}`
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"errors":[{"message":"expect either slot or root","path":["block"]}],"data":{"block":null}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+type staticKeys map[string]*dbmodels.ApiKey
+
+func (k staticKeys) GetApiKey(plain string) (*dbmodels.ApiKey, error) {
+	return k[plain], nil
+}
+
+func TestAdminRoutes(t *testing.T) {
+	s := newTestServer()
+	s.keys = staticKeys{
+		"reader": {ID: 1, Scopes: constant.API_SCOPE_READ},
+		"admin":  {ID: 2, Scopes: constant.API_SCOPE_ADMIN},
+	}
+	s.setupRouter(handlers.NewHandlers(s.services, s.config, nil, s.logger))
+	for _, tc := range []struct {
+		method string
+		target string
+		body   string
+		key    string
+		code   int
+	}{
+		{http.MethodGet, "/api/v1/admin/tasks", "", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/v1/admin/tasks", "", "reader", http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/watchlist/1", "", "reader", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/admin/tasks/other/1/pause", "", "admin", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/tasks/scan/x/pause", "", "admin", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/tasks", `{"kind":"scan","task_type":"unknown","start":0}`, "admin", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/tasks", `{"kind":"other","start":0}`, "admin", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/reindex", `{"start":10,"end":5}`, "admin", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		if tc.key != "" {
+			req.Header.Set("X-API-Key", tc.key)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, tc.target)
+	}
+}
+
+func TestRequireKey(t *testing.T) {
+	s := newTestServer()
+	s.config.Auth.RequireKey = true
+	s.setupRouter(handlers.NewHandlers(s.services, s.config, nil, s.logger))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/blocks?page=0", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// the health check stay public.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -44,11 +44,69 @@ func Migrate(cfg config.DatabaseConfig) error {
 	}
 
 	// Auto migrate all models
-	return db.AutoMigrate(
+	err = db.AutoMigrate(
 		&dbmodels.BeaconBlock{},
 		&dbmodels.BeaconBlock{},
 		&dbmodels.ScanTask{},
 		&dbmodels.DirectlyScanTask{},
+		&dbmodels.ScanCursor{},
 		&dbmodels.BeaconAttestation{},
+		&dbmodels.BeaconDepositRequest{},
+		&dbmodels.BeaconWithdrawalRequest{},
+		&dbmodels.BeaconConsolidationRequest{},
+		&dbmodels.Validator{},
+		&dbmodels.ValidatorHistory{},
+		&dbmodels.ValidatorBalanceDaily{},
+		&dbmodels.ValidatorRewardDaily{},
+		&dbmodels.ProposerDuty{},
+		&dbmodels.AttesterDuty{},
+		&dbmodels.EpochSummary{},
+		&dbmodels.FinalityCheckpoint{},
+		&dbmodels.ChainAlert{},
+		&dbmodels.Eth1BlockHeader{},
+		&dbmodels.Eth1BlockBody{},
+		&dbmodels.Eth1Transaction{},
+		&dbmodels.Eth1Receipt{},
+		&dbmodels.Eth1Log{},
+		&dbmodels.Deposit{},
+		&dbmodels.BeaconBlockDeposit{},
+		&dbmodels.RelayPayload{},
+		&dbmodels.BlockMev{},
+		&dbmodels.WatchedValidator{},
+		&dbmodels.WebhookOutbox{},
+		&dbmodels.WebhookDelivery{},
+		&dbmodels.ApiKey{},
+		&dbmodels.BeaconApiBlock{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateBalanceTable(db); err != nil {
+		return err
+	}
+	return migrateSearchIndexes(db)
+}
+
+// migrateBalanceTable create the validator_balances table partitioned by epoch range,
+// partitions are created on demand by the balance sampler.
+func migrateBalanceTable(db *gorm.DB) error {
+	return db.Exec(`
+CREATE TABLE IF NOT EXISTS validator_balances (
+	epoch bigint NOT NULL,
+	validator_index bigint NOT NULL,
+	balance bigint NOT NULL,
+	effective_balance bigint NOT NULL,
+	PRIMARY KEY (epoch, validator_index)
+) PARTITION BY RANGE (epoch);
+CREATE INDEX IF NOT EXISTS idx_validator_balances_index_epoch ON validator_balances (validator_index, epoch);
+`).Error
+}
+
+// migrateSearchIndexes create the trigram index serving graffiti substring search.
+func migrateSearchIndexes(db *gorm.DB) error {
+	return db.Exec(`
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_beacon_blocks_graffiti_trgm ON beacon_blocks USING gin (graffiti_text gin_trgm_ops);
+`).Error
 }
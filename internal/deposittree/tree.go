@@ -0,0 +1,87 @@
+// Package deposittree implement the incremental merkle tree of the deposit contract,
+// so deposit roots can be recomputed from the indexed DepositEvent logs.
+package deposittree
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Depth is the depth of the deposit contract tree.
+const Depth = 32
+
+var zeroHashes [Depth + 1][32]byte
+
+func init() {
+	for i := 1; i <= Depth; i++ {
+		zeroHashes[i] = hash(zeroHashes[i-1][:], zeroHashes[i-1][:])
+	}
+}
+
+func hash(a, b []byte) [32]byte {
+	h := sha256.New()
+	h.Write(a)
+	h.Write(b)
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// Tree keep only the left branch of the tree like the deposit contract does.
+type Tree struct {
+	branch [Depth][32]byte
+	count  uint64
+}
+
+func New() *Tree {
+	return &Tree{}
+}
+
+func (t *Tree) Count() uint64 {
+	return t.count
+}
+
+// Insert append the deposit data root as the next leaf.
+func (t *Tree) Insert(leaf [32]byte) {
+	t.count++
+	size := t.count
+	node := leaf
+	for height := 0; height < Depth; height++ {
+		if size&1 == 1 {
+			t.branch[height] = node
+			return
+		}
+		node = hash(t.branch[height][:], node[:])
+		size /= 2
+	}
+}
+
+// Root return the deposit root with the deposit count mixed in, as returned by get_deposit_root.
+func (t *Tree) Root() [32]byte {
+	var node [32]byte
+	size := t.count
+	for height := 0; height < Depth; height++ {
+		if size&1 == 1 {
+			node = hash(t.branch[height][:], node[:])
+		} else {
+			node = hash(node[:], zeroHashes[height][:])
+		}
+		size /= 2
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:8], t.count)
+	return hash(node[:], length[:])
+}
+
+// DataRoot return the hash tree root of the deposit data, the leaf of the tree.
+func DataRoot(pubkey phase0.BLSPubKey, withdrawalCredentials []byte, amount phase0.Gwei, signature phase0.BLSSignature) ([32]byte, error) {
+	data := &phase0.DepositData{
+		PublicKey:             pubkey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+		Signature:             signature,
+	}
+	return data.HashTreeRoot()
+}
@@ -0,0 +1,53 @@
+package deposittree
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveRoot compute the root from all leaves of the full tree.
+func naiveRoot(leaves [][32]byte) [32]byte {
+	layer := make([][32]byte, len(leaves))
+	copy(layer, leaves)
+	for height := 0; height < Depth; height++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHashes[height])
+		}
+		next := make([][32]byte, 0, len(layer)/2)
+		for i := 0; i < len(layer); i += 2 {
+			next = append(next, hash(layer[i][:], layer[i+1][:]))
+		}
+		layer = next
+	}
+	var node [32]byte
+	if len(layer) > 0 {
+		node = layer[0]
+	} else {
+		node = zeroHashes[Depth]
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:8], uint64(len(leaves)))
+	return hash(node[:], length[:])
+}
+
+func TestEmptyRoot(t *testing.T) {
+	root := New().Root()
+	assert.Equal(t, "d70a234731285c6804c2a4f56711ddb8c82c99740f207854891028af34e27e5e", hex.EncodeToString(root[:]))
+}
+
+func TestIncrementalRoot(t *testing.T) {
+	tree := New()
+	leaves := make([][32]byte, 0)
+	for i := 0; i < 37; i++ {
+		var leaf [32]byte
+		leaf[0] = byte(i + 1)
+		leaf[31] = byte(i * 7)
+		tree.Insert(leaf)
+		leaves = append(leaves, leaf)
+		assert.Equal(t, naiveRoot(leaves), tree.Root(), "count %d", i+1)
+	}
+	assert.Equal(t, uint64(37), tree.Count())
+}
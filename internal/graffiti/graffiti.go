@@ -0,0 +1,125 @@
+package graffiti
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ClientInfo is the client fingerprint extracted from a block graffiti.
+type ClientInfo struct {
+	ConsensusClient  string
+	ConsensusVersion string
+	ExecutionClient  string
+	ExecutionVersion string
+}
+
+// consensusCodes maps the two-letter client codes used in graffiti to client names.
+var consensusCodes = map[string]string{
+	"LH": "lighthouse",
+	"PM": "prysm",
+	"PR": "prysm",
+	"TK": "teku",
+	"NB": "nimbus",
+	"LS": "lodestar",
+	"GR": "grandine",
+}
+
+// executionCodes follows the ClientVersionV1 codes of the engine api.
+var executionCodes = map[string]string{
+	"GE": "geth",
+	"NM": "nethermind",
+	"BU": "besu",
+	"EG": "erigon",
+	"RH": "reth",
+	"EJ": "ethereumjs",
+	"NB": "nimbus-el",
+	"TE": "trin",
+}
+
+var (
+	// <EL code><EL commit><CL code><CL commit>, commits are 4, 2 or 0 hex characters.
+	codePattern = regexp.MustCompile(`^([A-Z]{2})([0-9a-f]{4}|[0-9a-f]{2})?([A-Z]{2})([0-9a-f]{4}|[0-9a-f]{2})?$`)
+
+	consensusPatterns = []struct {
+		name    string
+		pattern *regexp.Regexp
+	}{
+		{"lighthouse", regexp.MustCompile(`(?i)lighthouse(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"prysm", regexp.MustCompile(`(?i)prysm(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"teku", regexp.MustCompile(`(?i)teku(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"nimbus", regexp.MustCompile(`(?i)nimbus(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"lodestar", regexp.MustCompile(`(?i)lodestar(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"grandine", regexp.MustCompile(`(?i)grandine(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+	}
+	executionPatterns = []struct {
+		name    string
+		pattern *regexp.Regexp
+	}{
+		{"geth", regexp.MustCompile(`(?i)\bgeth(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"nethermind", regexp.MustCompile(`(?i)\bnethermind(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"besu", regexp.MustCompile(`(?i)\bbesu(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"erigon", regexp.MustCompile(`(?i)\berigon(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+		{"reth", regexp.MustCompile(`(?i)\breth(?:[/ -]v?(\d+\.\d+\.\d+\S*))?`)},
+	}
+)
+
+// Decode converts the raw graffiti to printable UTF-8, trailing zero padding is removed
+// and any invalid or non-printable characters are dropped.
+func Decode(raw [32]byte) string {
+	data := bytes.TrimRight(raw[:], "\x00")
+	text := strings.ToValidUTF8(string(data), "")
+	return strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(text))
+}
+
+// Classify extracts the consensus and execution client information from decoded graffiti.
+// The client version codes appended by the clients take precedence over free text names.
+func Classify(text string) ClientInfo {
+	var info ClientInfo
+	fields := strings.Fields(text)
+	// clients append the version code at the end of user graffiti, so search backwards.
+	for i := len(fields) - 1; i >= 0; i-- {
+		if parseCode(fields[i], &info) {
+			return info
+		}
+	}
+	for _, p := range consensusPatterns {
+		if m := p.pattern.FindStringSubmatch(text); m != nil {
+			info.ConsensusClient = p.name
+			info.ConsensusVersion = m[1]
+			break
+		}
+	}
+	for _, p := range executionPatterns {
+		if m := p.pattern.FindStringSubmatch(text); m != nil {
+			info.ExecutionClient = p.name
+			info.ExecutionVersion = m[1]
+			break
+		}
+	}
+	return info
+}
+
+func parseCode(token string, info *ClientInfo) bool {
+	m := codePattern.FindStringSubmatch(token)
+	if m == nil {
+		return false
+	}
+	el, elExist := executionCodes[m[1]]
+	cl, clExist := consensusCodes[m[3]]
+	if !elExist || !clExist {
+		return false
+	}
+	info.ExecutionClient = el
+	info.ExecutionVersion = m[2]
+	info.ConsensusClient = cl
+	info.ConsensusVersion = m[4]
+	return true
+}
@@ -0,0 +1,47 @@
+package graffiti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func toRaw(s string) [32]byte {
+	var raw [32]byte
+	copy(raw[:], s)
+	return raw
+}
+
+func TestDecode(t *testing.T) {
+	assert.Equal(t, "", Decode([32]byte{}))
+	assert.Equal(t, "Lighthouse/v4.5.0-441fc16", Decode(toRaw("Lighthouse/v4.5.0-441fc16")))
+	assert.Equal(t, "hello 🦄", Decode(toRaw("hello 🦄")))
+
+	raw := toRaw("ab")
+	raw[2] = 0xff
+	raw[3] = 0x07
+	raw[4] = 'c'
+	assert.Equal(t, "abc", Decode(raw))
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		text string
+		want ClientInfo
+	}{
+		{"GEabcdLH1234", ClientInfo{"lighthouse", "1234", "geth", "abcd"}},
+		{"my pool NMabTK12", ClientInfo{"teku", "12", "nethermind", "ab"}},
+		{"BUPR", ClientInfo{"prysm", "", "besu", ""}},
+		{"RH1f2eNB0a1b", ClientInfo{"nimbus", "0a1b", "reth", "1f2e"}},
+		{"Lighthouse/v4.5.0-441fc16", ClientInfo{ConsensusClient: "lighthouse", ConsensusVersion: "4.5.0-441fc16"}},
+		{"teku/v23.1.0 geth", ClientInfo{ConsensusClient: "teku", ConsensusVersion: "23.1.0", ExecutionClient: "geth"}},
+		{"Lodestar-v1.12.0", ClientInfo{ConsensusClient: "lodestar", ConsensusVersion: "1.12.0"}},
+		{"XXabcdLH1234", ClientInfo{}},
+		{"hello world", ClientInfo{}},
+		{"stronger together", ClientInfo{}},
+		{"lighthouse-geth", ClientInfo{ConsensusClient: "lighthouse", ExecutionClient: "geth"}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, Classify(c.text), c.text)
+	}
+}
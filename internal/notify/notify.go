@@ -0,0 +1,159 @@
+// Package notify deliver indexer events to the configured notifiers.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+)
+
+// Event is something worth telling an operator about.
+type Event struct {
+	Type           string                 `json:"type"`
+	Epoch          uint64                 `json:"epoch"`
+	Slot           *uint64                `json:"slot,omitempty"`
+	ValidatorIndex *uint64                `json:"validator_index,omitempty"`
+	Root           string                 `json:"root,omitempty"`
+	Message        string                 `json:"message"`
+	Data           map[string]interface{} `json:"data,omitempty"`
+	Time           time.Time              `json:"time"`
+}
+
+// Key identify the event for deduplication, a dispatch retried after a failure does not
+// deliver the event twice. Events about no epoch, slot or validator may repeat, their key is empty.
+// The root tell apart events about different blocks at the same slot, such as reorgs.
+func (e *Event) Key() string {
+	if e.Epoch == 0 && e.Slot == nil && e.ValidatorIndex == nil {
+		return ""
+	}
+	key := fmt.Sprintf("%s:%d", e.Type, e.Epoch)
+	if e.Slot != nil {
+		key += fmt.Sprintf(":s%d", *e.Slot)
+	}
+	if e.ValidatorIndex != nil {
+		key += fmt.Sprintf(":v%d", *e.ValidatorIndex)
+	}
+	if e.Root != "" {
+		key += ":r" + strings.ToLower(e.Root)
+	}
+	return key
+}
+
+// Notifier deliver events to one destination.
+type Notifier interface {
+	Name() string
+	Notify(event *Event) error
+}
+
+// Dispatcher fan out events to every registered notifier.
+type Dispatcher struct {
+	notifiers []Notifier
+	logger    *logrus.Logger
+
+	mux     sync.Mutex
+	failing map[string]bool
+}
+
+func NewDispatcher(logger *logrus.Logger) *Dispatcher {
+	return &Dispatcher{logger: logger, failing: make(map[string]bool)}
+}
+
+// NewDispatcherFromConfig register the notifiers enabled in the config, unknown names are skipped.
+// The outbox persist the events of the webhook notifier.
+func NewDispatcherFromConfig(cfg *config.Config, outbox Outbox, logger *logrus.Logger) *Dispatcher {
+	d := NewDispatcher(logger)
+	for _, name := range cfg.Notify.Notifiers {
+		switch name {
+		case "log":
+			d.Register(NewLogNotifier(logger))
+		case "webhook":
+			d.Register(NewWebhookNotifier(cfg.Notify.Webhooks, outbox))
+		default:
+			logger.WithField("notifier", name).Warn("Unknown notifier, skipping")
+		}
+	}
+	return d
+}
+
+// WithOutbox return a dispatcher with the same notifiers, the webhook notifier persisting the
+// events in outbox, such as an outbox writing through the transaction of the indexed data.
+func (d *Dispatcher) WithOutbox(outbox Outbox) *Dispatcher {
+	res := NewDispatcher(d.logger)
+	for _, n := range d.notifiers {
+		if webhook, ok := n.(*WebhookNotifier); ok {
+			n = NewWebhookNotifier(webhook.webhooks, outbox)
+		}
+		res.Register(n)
+	}
+	return res
+}
+
+func (d *Dispatcher) Register(n Notifier) {
+	d.notifiers = append(d.notifiers, n)
+}
+
+// Dispatch send the event to all notifiers, a failing notifier does not stop the others.
+func (d *Dispatcher) Dispatch(event *Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	var errs []error
+	for _, n := range d.notifiers {
+		if err := n.Notify(event); err != nil {
+			d.logger.WithField("notifier", n.Name()).WithError(err).Error("Failed to notify event")
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TaskResult report a scan task run, a failure is dispatched only when the task was
+// succeeding before, so a long outage raises one event.
+func (d *Dispatcher) TaskResult(taskType string, err error) {
+	d.mux.Lock()
+	failing := d.failing[taskType]
+	d.failing[taskType] = err != nil
+	d.mux.Unlock()
+	if err == nil || failing {
+		return
+	}
+	d.Dispatch(&Event{
+		Type:    constant.EVENT_TASK_FAILED,
+		Message: fmt.Sprintf("scan task %s failed: %v", taskType, err),
+		Data:    map[string]interface{}{"task": taskType, "error": err.Error()},
+	})
+}
+
+// LogNotifier write events to the logger.
+type LogNotifier struct {
+	logger *logrus.Logger
+}
+
+func NewLogNotifier(logger *logrus.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Name() string {
+	return "log"
+}
+
+func (n *LogNotifier) Notify(event *Event) error {
+	fields := logrus.Fields{
+		"event": event.Type,
+		"epoch": event.Epoch,
+	}
+	if event.Slot != nil {
+		fields["slot"] = *event.Slot
+	}
+	if event.ValidatorIndex != nil {
+		fields["validator"] = *event.ValidatorIndex
+	}
+	n.logger.WithFields(fields).Warn(event.Message)
+	return nil
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordNotifier struct {
+	events []*Event
+	err    error
+}
+
+func (n *recordNotifier) Name() string {
+	return "record"
+}
+
+func (n *recordNotifier) Notify(event *Event) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func TestDispatch(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	failing := &recordNotifier{err: errors.New("unreachable")}
+	ok := &recordNotifier{}
+
+	d := NewDispatcher(logger)
+	d.Register(failing)
+	d.Register(ok)
+	err := d.Dispatch(&Event{Type: "missed_proposal", Epoch: 10})
+
+	assert.ErrorContains(t, err, "unreachable")
+	assert.Len(t, failing.events, 1)
+	assert.Len(t, ok.events, 1)
+	assert.False(t, ok.events[0].Time.IsZero())
+}
+
+func TestTaskResult(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	rec := &recordNotifier{}
+	d := NewDispatcher(logger)
+	d.Register(rec)
+
+	d.TaskResult("eth1_block", errors.New("rpc down"))
+	d.TaskResult("eth1_block", errors.New("rpc down"))
+	assert.Len(t, rec.events, 1)
+	assert.Equal(t, "task_failed", rec.events[0].Type)
+
+	d.TaskResult("eth1_block", nil)
+	d.TaskResult("eth1_block", errors.New("rpc down again"))
+	assert.Len(t, rec.events, 2)
+}
+
+func TestEventKey(t *testing.T) {
+	slot, index := uint64(320), uint64(7)
+	assert.Equal(t, "", (&Event{Type: "task_failed"}).Key())
+	assert.Equal(t, "finalized_epoch:10", (&Event{Type: "finalized_epoch", Epoch: 10}).Key())
+	assert.Equal(t, "slashed:10:s320:v7", (&Event{Type: "slashed", Epoch: 10, Slot: &slot, ValidatorIndex: &index}).Key())
+	assert.Equal(t, "balance_decrease:10:v7", (&Event{Type: "balance_decrease", Epoch: 10, ValidatorIndex: &index}).Key())
+	assert.Equal(t, "reorg:10:s320:r0xab", (&Event{Type: "reorg", Epoch: 10, Slot: &slot, Root: "0xAB"}).Key())
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xueqianLu/deep-dive-beacon/config"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	backoffBase = 10 * time.Second
+	backoffMax  = time.Hour
+)
+
+// Outbox persist webhook payloads until they are delivered, a payload with the key of an
+// event already queued for the webhook is skipped.
+type Outbox interface {
+	Enqueue(webhook string, eventType string, key string, payload []byte) error
+}
+
+// WebhookNotifier queue events in the outbox for every webhook subscribed to the event type,
+// the delivery itself is done by the webhook sender so events survive restarts and outages.
+type WebhookNotifier struct {
+	webhooks []config.WebhookConfig
+	outbox   Outbox
+}
+
+func NewWebhookNotifier(webhooks []config.WebhookConfig, outbox Outbox) *WebhookNotifier {
+	return &WebhookNotifier{webhooks: webhooks, outbox: outbox}
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *WebhookNotifier) Notify(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, hook := range n.webhooks {
+		if !Subscribed(hook, event.Type) {
+			continue
+		}
+		if err := n.outbox.Enqueue(hook.Name, event.Type, event.Key(), payload); err != nil {
+			return fmt.Errorf("enqueue for %s: %w", hook.Name, err)
+		}
+	}
+	return nil
+}
+
+// Subscribed return true if the webhook want the event type.
+func Subscribed(hook config.WebhookConfig, eventType string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, t := range hook.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign return the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret. Receivers recompute
+// it from the timestamp header and the raw body, and should reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff return the delay before the next attempt after attempt failed deliveries.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		return 0
+	}
+	delay := backoffBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= backoffMax {
+			return backoffMax
+		}
+	}
+	return delay
+}
+
+// WebhookClient post signed payloads to webhooks.
+type WebhookClient struct {
+	client *http.Client
+}
+
+func NewWebhookClient(timeout time.Duration) *WebhookClient {
+	return &WebhookClient{client: &http.Client{Timeout: timeout}}
+}
+
+// Send post the payload and return the response status code, any non 2xx status is an error.
+func (c *WebhookClient) Send(hook config.WebhookConfig, id uint, eventType string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderID, strconv.FormatUint(uint64(id), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(hook.Secret, timestamp, payload))
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook %s responded %s", hook.Name, resp.Status)
+	}
+	return resp.StatusCode, nil
+}
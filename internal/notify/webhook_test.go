@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+)
+
+type memOutbox struct {
+	entries map[string][]string
+}
+
+func (o *memOutbox) Enqueue(webhook string, eventType string, key string, payload []byte) error {
+	o.entries[webhook] = append(o.entries[webhook], eventType)
+	return nil
+}
+
+func TestWebhookNotifierFilter(t *testing.T) {
+	outbox := &memOutbox{entries: make(map[string][]string)}
+	n := NewWebhookNotifier([]config.WebhookConfig{
+		{Name: "all"},
+		{Name: "ops", Events: []string{"reorg", "task_failed"}},
+	}, outbox)
+
+	require.NoError(t, n.Notify(&Event{Type: "reorg"}))
+	require.NoError(t, n.Notify(&Event{Type: "missed_proposal"}))
+
+	assert.Equal(t, []string{"reorg", "missed_proposal"}, outbox.entries["all"])
+	assert.Equal(t, []string{"reorg"}, outbox.entries["ops"])
+}
+
+func TestWebhookSend(t *testing.T) {
+	secret := "s3cret"
+	var received *Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil || r.Header.Get(HeaderSignature) != "sha256="+Sign(secret, timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "slashed", r.Header.Get(HeaderEvent))
+		assert.Equal(t, "42", r.Header.Get(HeaderID))
+		received = &Event{}
+		json.Unmarshal(body, received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	payload, _ := json.Marshal(&Event{Type: "slashed", Epoch: 7, Message: "validator 1 has been slashed"})
+	client := NewWebhookClient(time.Second)
+
+	code, err := client.Send(config.WebhookConfig{Name: "test", URL: srv.URL, Secret: secret}, 42, "slashed", payload)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+	require.NotNil(t, received)
+	assert.Equal(t, uint64(7), received.Epoch)
+
+	code, err = client.Send(config.WebhookConfig{Name: "test", URL: srv.URL, Secret: "wrong"}, 42, "slashed", payload)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Backoff(0))
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestDispatcherWithOutbox(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	outbox := &memOutbox{entries: make(map[string][]string)}
+	txOutbox := &memOutbox{entries: make(map[string][]string)}
+	d := NewDispatcher(logger)
+	d.Register(NewWebhookNotifier([]config.WebhookConfig{{Name: "all"}}, outbox))
+
+	require.NoError(t, d.WithOutbox(txOutbox).Dispatch(&Event{Type: "slashed"}))
+	assert.Empty(t, outbox.entries)
+	assert.Equal(t, []string{"slashed"}, txOutbox.entries["all"])
+}
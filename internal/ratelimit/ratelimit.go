@@ -0,0 +1,82 @@
+// Package ratelimit implement a token bucket shared by every api instance through Redis.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket take one token from the bucket in KEYS[1], refilled with ARGV[1] tokens per
+// second up to ARGV[2] tokens. The redis clock is used so that every instance agree on time.
+// It return whether the request is allowed, the tokens left and the milliseconds until the
+// next token.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens), retry}
+`
+
+var tokenBucket = redis.NewScript(tokenBucketScript)
+
+// Result is the outcome of one request against a bucket.
+type Result struct {
+	Allowed    bool
+	Remaining  int64
+	RetryAfter time.Duration
+}
+
+// Limiter rate limit requests per key. A limiter without client allow everything.
+type Limiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewLimiter(client *redis.Client, prefix string) *Limiter {
+	return &Limiter{client: client, prefix: prefix}
+}
+
+// Allow take a token from the bucket of key, which allow perMinute requests per minute with
+// bursts of up to perMinute requests.
+func (l *Limiter) Allow(ctx context.Context, key string, perMinute int) (*Result, error) {
+	if l.client == nil || perMinute <= 0 {
+		return &Result{Allowed: true, Remaining: int64(perMinute)}, nil
+	}
+	rate := float64(perMinute) / 60
+	values, err := tokenBucket.Run(ctx, l.client, []string{l.prefix + key}, rate, perMinute).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, errors.New("unexpected token bucket result")
+	}
+	return &Result{
+		Allowed:    values[0] == 1,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
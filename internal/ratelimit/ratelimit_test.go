@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noScriptError struct{}
+
+func (noScriptError) Error() string { return "NOSCRIPT No matching script" }
+
+func (noScriptError) RedisError() {}
+
+func TestAllow(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	l := NewLimiter(client, "ratelimit:")
+
+	mock.ExpectEvalSha(tokenBucket.Hash(), []string{"ratelimit:key:1"}, float64(1), 60).
+		SetVal([]interface{}{int64(1), int64(59), int64(0)})
+	res, err := l.Allow(context.Background(), "key:1", 60)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(59), res.Remaining)
+
+	mock.ExpectEvalSha(tokenBucket.Hash(), []string{"ratelimit:key:1"}, float64(1), 60).
+		SetVal([]interface{}{int64(0), int64(0), int64(400)})
+	res, err = l.Allow(context.Background(), "key:1", 60)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 400*time.Millisecond, res.RetryAfter)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowLoadScript(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	l := NewLimiter(client, "ratelimit:")
+
+	mock.ExpectEvalSha(tokenBucket.Hash(), []string{"ratelimit:ip:1.2.3.4"}, float64(2), 120).SetErr(noScriptError{})
+	mock.ExpectEval(tokenBucketScript, []string{"ratelimit:ip:1.2.3.4"}, float64(2), 120).
+		SetVal([]interface{}{int64(1), int64(119), int64(0)})
+	res, err := l.Allow(context.Background(), "ip:1.2.3.4", 120)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowWithoutClient(t *testing.T) {
+	res, err := NewLimiter(nil, "ratelimit:").Allow(context.Background(), "key:1", 60)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
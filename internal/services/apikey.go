@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix = "dbk_"
+	// apiKeyCacheTTL bound how long a revoked key is still accepted by other api instances.
+	apiKeyCacheTTL = time.Minute
+)
+
+// ErrUnknownScope is returned when creating a key with a scope which does not exist.
+var ErrUnknownScope = errors.New("unknown api key scope")
+
+type ApiKeyService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewApiKeyService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *ApiKeyService {
+	return &ApiKeyService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// HashApiKey return the hex sha256 of the key, the only form a key is stored in.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasScope check whether the key grant scope, the admin scope grant every scope.
+func HasScope(key *dbmodels.ApiKey, scope string) bool {
+	for _, s := range strings.Split(key.Scopes, ",") {
+		if s == scope || s == constant.API_SCOPE_ADMIN {
+			return true
+		}
+	}
+	return false
+}
+
+// Create generate a new key, the plain key is returned only once and never stored.
+func (s *ApiKeyService) Create(name string, scopes []string, rateLimit int) (string, *dbmodels.ApiKey, error) {
+	for _, scope := range scopes {
+		if scope != constant.API_SCOPE_READ && scope != constant.API_SCOPE_ADMIN {
+			return "", nil, ErrUnknownScope
+		}
+	}
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	plain := apiKeyPrefix + hex.EncodeToString(secret)
+	key := &dbmodels.ApiKey{
+		Name:      name,
+		Prefix:    plain[:len(apiKeyPrefix)+8],
+		KeyHash:   HashApiKey(plain),
+		Scopes:    strings.Join(scopes, ","),
+		RateLimit: rateLimit,
+	}
+	if err := s.db.Create(key).Error; err != nil {
+		return "", nil, err
+	}
+	return plain, key, nil
+}
+
+// GetApiKey return the not revoked key matching the plain key, or nil if there is none. Misses
+// are cached too, so that unknown keys do not reach the database on every request.
+func (s *ApiKeyService) GetApiKey(plain string) (*dbmodels.ApiKey, error) {
+	hash := HashApiKey(plain)
+	cacheKey := "apikey:" + hash
+	var key dbmodels.ApiKey
+	if getCache(s.redis, cacheKey, &key) {
+		// unknown keys are cached as a key without id.
+		if key.ID == 0 {
+			return nil, nil
+		}
+		return &key, nil
+	}
+	result := s.db.Where("key_hash = ? AND revoked = ?", hash, false).Limit(1).Find(&key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	setCache(s.redis, cacheKey, &key, apiKeyCacheTTL)
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (s *ApiKeyService) List() ([]*dbmodels.ApiKey, error) {
+	var keys []*dbmodels.ApiKey
+	result := s.db.Order("id").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+// Revoke disable the key, return false if there is no such key.
+func (s *ApiKeyService) Revoke(id uint) (bool, error) {
+	var key dbmodels.ApiKey
+	result := s.db.Where("id = ?", id).Limit(1).Find(&key)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	if err := s.db.Model(&key).Update("revoked", true).Error; err != nil {
+		return false, err
+	}
+	if s.redis != nil {
+		s.redis.Del(context.Background(), "apikey:"+key.KeyHash)
+	}
+	return true, nil
+}
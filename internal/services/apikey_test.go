@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func TestHasScope(t *testing.T) {
+	reader := &dbmodels.ApiKey{Scopes: constant.API_SCOPE_READ}
+	assert.True(t, HasScope(reader, constant.API_SCOPE_READ))
+	assert.False(t, HasScope(reader, constant.API_SCOPE_ADMIN))
+
+	admin := &dbmodels.ApiKey{Scopes: "read,admin"}
+	assert.True(t, HasScope(admin, constant.API_SCOPE_ADMIN))
+	assert.True(t, HasScope(&dbmodels.ApiKey{Scopes: constant.API_SCOPE_ADMIN}, constant.API_SCOPE_READ))
+	assert.False(t, HasScope(&dbmodels.ApiKey{}, constant.API_SCOPE_READ))
+}
+
+func TestHashApiKey(t *testing.T) {
+	assert.Len(t, HashApiKey("dbk_secret"), 64)
+	assert.Equal(t, HashApiKey("dbk_secret"), HashApiKey("dbk_secret"))
+	assert.NotEqual(t, HashApiKey("dbk_secret"), HashApiKey("dbk_other"))
+}
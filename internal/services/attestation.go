@@ -3,7 +3,9 @@ package services
 import (
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
 	"gorm.io/gorm"
+	"strings"
 )
 
 type AttestationService struct {
@@ -19,3 +21,88 @@ func NewAttestationService(db *gorm.DB, redis *redis.Client, logger *logrus.Logg
 		logger: logger,
 	}
 }
+
+// GetAttestationsForSlot return the indexed attestations voting for slot with the given target epoch.
+func (s *AttestationService) GetAttestationsForSlot(slot uint64, targetEpoch uint64) ([]*dbmodels.BeaconAttestation, error) {
+	var atts []*dbmodels.BeaconAttestation
+	result := s.db.Where("attestation_slot = ? AND target_epoch = ?", slot, targetEpoch).Find(&atts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return atts, nil
+}
+
+// AttestationFilter select attestations, nil and empty fields are not filtered.
+type AttestationFilter struct {
+	InclusionSlot   *uint64
+	CommitteeIndex  *uint64
+	TargetEpoch     *uint64
+	BeaconBlockRoot string
+	// ValidatorIndex select the aggregates of the validator's committee included at its first
+	// inclusion, as reconciled by the duty scanner.
+	ValidatorIndex *uint64
+}
+
+// GetAttestations return up to limit attestations matching filter with id below cursor, newest
+// first, and the cursor of the next page, 0 when there is no more.
+func (s *AttestationService) GetAttestations(filter AttestationFilter, cursor uint, limit int) ([]*dbmodels.BeaconAttestation, uint, error) {
+	query := s.db.Model(&dbmodels.BeaconAttestation{})
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	if filter.InclusionSlot != nil {
+		query = query.Where("slot_number = ?", *filter.InclusionSlot)
+	}
+	if filter.CommitteeIndex != nil {
+		// since Electra the committees of an aggregate are the bits set in committee_bits.
+		index := *filter.CommitteeIndex
+		query = query.Where("(COALESCE(committee_bits, '') = '' AND committee_index = ?) OR "+
+			"(length(committee_bits) > ? AND get_byte(decode(committee_bits, 'hex'), ?) & ? <> 0)",
+			index, index/8*2, index/8, 1<<(index%8))
+	}
+	if filter.TargetEpoch != nil {
+		query = query.Where("target_epoch = ?", *filter.TargetEpoch)
+	}
+	if filter.BeaconBlockRoot != "" {
+		query = query.Where("beacon_block_root = ?", strings.ToLower(filter.BeaconBlockRoot))
+	}
+	if filter.ValidatorIndex != nil {
+		// the committee of the duty is matched the same way as the committee index filter.
+		query = query.Where("EXISTS (SELECT 1 FROM attester_duties d WHERE d.validator_index = ? "+
+			"AND d.inclusion_slot = beacon_attestations.slot_number AND d.slot = beacon_attestations.attestation_slot "+
+			"AND ((COALESCE(beacon_attestations.committee_bits, '') = '' AND d.committee_index = beacon_attestations.committee_index) OR "+
+			"(length(beacon_attestations.committee_bits) > d.committee_index / 8 * 2 AND "+
+			"get_byte(decode(beacon_attestations.committee_bits, 'hex'), (d.committee_index / 8)::int) & (1 << (d.committee_index % 8)::int) <> 0)))",
+			*filter.ValidatorIndex)
+	}
+	var atts []*dbmodels.BeaconAttestation
+	result := query.Order("id desc").Limit(limit).Find(&atts)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	next := uint(0)
+	if len(atts) == limit {
+		next = atts[len(atts)-1].ID
+	}
+	return atts, next, nil
+}
+
+// GetAttestationsByBlock return the attestations included in the block at slot, in block order.
+func (s *AttestationService) GetAttestationsByBlock(slot uint64) ([]*dbmodels.BeaconAttestation, error) {
+	var atts []*dbmodels.BeaconAttestation
+	result := s.db.Where("slot_number = ?", slot).Order("attest_index").Find(&atts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return atts, nil
+}
+
+// GetAttestationsBySlots return the attestations included in the blocks at the slots.
+func (s *AttestationService) GetAttestationsBySlots(slots []uint64) ([]*dbmodels.BeaconAttestation, error) {
+	var atts []*dbmodels.BeaconAttestation
+	result := s.db.Where("slot_number IN ?", slots).Order("slot_number, attest_index").Find(&atts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return atts, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
+	"time"
+)
+
+// balancePartitionEpochs is the epoch range covered by one validator_balances partition, about 30 days.
+const balancePartitionEpochs = uint64(6750)
+
+type BalanceService struct {
+	db         *gorm.DB
+	redis      *redis.Client
+	logger     *logrus.Logger
+	mux        sync.Mutex
+	partitions map[uint64]bool
+}
+
+func NewBalanceService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *BalanceService {
+	return &BalanceService{
+		db:         db,
+		redis:      redis,
+		logger:     logger,
+		partitions: make(map[uint64]bool),
+	}
+}
+
+// EnsurePartition create the validator_balances partition holding epoch if it does not exist.
+func (s *BalanceService) EnsurePartition(epoch uint64) error {
+	part := epoch / balancePartitionEpochs
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.partitions[part] {
+		return nil
+	}
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS validator_balances_p%d PARTITION OF validator_balances FOR VALUES FROM (%d) TO (%d)",
+		part, part*balancePartitionEpochs, (part+1)*balancePartitionEpochs)
+	if err := s.db.Exec(sql).Error; err != nil {
+		return err
+	}
+	s.partitions[part] = true
+	return nil
+}
+
+func (s *BalanceService) SaveBalances(epoch uint64, balances []*dbmodels.ValidatorBalance) error {
+	if err := s.EnsurePartition(epoch); err != nil {
+		return err
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(balances, validatorBatchSize).Error
+}
+
+// RollupDay aggregate the samples in [startEpoch, endEpoch) into the daily table for day,
+// startEpoch may be the last sample of the previous day so that the day start from it.
+func (s *BalanceService) RollupDay(day time.Time, startEpoch, endEpoch uint64) error {
+	return s.db.Exec(`
+INSERT INTO validator_balance_dailies (validator_index, day, start_epoch, end_epoch, start_balance, end_balance,
+	min_balance, max_balance, effective_balance, created_at, updated_at)
+SELECT validator_index, ?::date, min(epoch), max(epoch),
+	(array_agg(balance ORDER BY epoch))[1],
+	(array_agg(balance ORDER BY epoch DESC))[1],
+	min(balance), max(balance),
+	(array_agg(effective_balance ORDER BY epoch DESC))[1],
+	now(), now()
+FROM validator_balances
+WHERE epoch >= ? AND epoch < ?
+GROUP BY validator_index
+ON CONFLICT (validator_index, day) DO UPDATE SET
+	start_epoch = excluded.start_epoch,
+	end_epoch = excluded.end_epoch,
+	start_balance = excluded.start_balance,
+	end_balance = excluded.end_balance,
+	min_balance = excluded.min_balance,
+	max_balance = excluded.max_balance,
+	effective_balance = excluded.effective_balance,
+	updated_at = excluded.updated_at`,
+		day.UTC().Format("2006-01-02"), startEpoch, endEpoch).Error
+}
+
+// GetBalanceHistory return the balance samples of the validator for epochs in [start, end].
+func (s *BalanceService) GetBalanceHistory(index uint64, start, end uint64) ([]*dbmodels.ValidatorBalance, error) {
+	var balances []*dbmodels.ValidatorBalance
+	result := s.db.Where("validator_index = ? AND epoch >= ? AND epoch <= ?", index, start, end).
+		Order("epoch").Find(&balances)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return balances, nil
+}
+
+// GetDailyBalances return the daily rollups of the validator for days in [from, to].
+func (s *BalanceService) GetDailyBalances(index uint64, from, to time.Time) ([]*dbmodels.ValidatorBalanceDaily, error) {
+	var dailies []*dbmodels.ValidatorBalanceDaily
+	result := s.db.Where("validator_index = ? AND day >= ? AND day <= ?", index,
+		from.UTC().Format("2006-01-02"), to.UTC().Format("2006-01-02")).
+		Order("day").Find(&dailies)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return dailies, nil
+}
+
+// GetDailyBalancesOfValidators return the daily rollups of the validators for days in [from, to].
+func (s *BalanceService) GetDailyBalancesOfValidators(indices []uint64, from, to time.Time) ([]*dbmodels.ValidatorBalanceDaily, error) {
+	var dailies []*dbmodels.ValidatorBalanceDaily
+	result := s.db.Where("validator_index IN ? AND day >= ? AND day <= ?", indices,
+		from.UTC().Format("2006-01-02"), to.UTC().Format("2006-01-02")).
+		Order("validator_index, day").Find(&dailies)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return dailies, nil
+}
+
+// AddEpochRewards add the rewards of epoch to the daily rewards of day. An epoch already added
+// is skipped, so the epoch can be retried.
+func (s *BalanceService) AddEpochRewards(day time.Time, epoch uint64, rewards []*dbmodels.ValidatorRewardDaily) error {
+	if len(rewards) == 0 {
+		return nil
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	for _, reward := range rewards {
+		reward.Day = day
+		reward.LastEpoch = epoch
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "validator_index"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attestation_rewards": gorm.Expr("validator_reward_dailies.attestation_rewards + excluded.attestation_rewards"),
+			"proposer_rewards":    gorm.Expr("validator_reward_dailies.proposer_rewards + excluded.proposer_rewards"),
+			"last_epoch":          gorm.Expr("excluded.last_epoch"),
+			"updated_at":          gorm.Expr("excluded.updated_at"),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{gorm.Expr("validator_reward_dailies.last_epoch < excluded.last_epoch")}},
+	}).CreateInBatches(rewards, validatorBatchSize).Error
+}
+
+// GetDailyRewards return the daily rewards of the validator for days in [from, to].
+func (s *BalanceService) GetDailyRewards(index uint64, from, to time.Time) ([]*dbmodels.ValidatorRewardDaily, error) {
+	return s.GetDailyRewardsOfValidators([]uint64{index}, from, to)
+}
+
+// GetDailyRewardsOfValidators return the daily rewards of the validators for days in [from, to].
+func (s *BalanceService) GetDailyRewardsOfValidators(indices []uint64, from, to time.Time) ([]*dbmodels.ValidatorRewardDaily, error) {
+	var rewards []*dbmodels.ValidatorRewardDaily
+	result := s.db.Where("validator_index IN ? AND day >= ? AND day <= ?", indices,
+		from.UTC().Format("2006-01-02"), to.UTC().Format("2006-01-02")).
+		Order("validator_index, day").Find(&rewards)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return rewards, nil
+}
+
+// DailyReward is the attestation and proposer rewards of a validator over one day, penalties
+// included. Unlike the balance change, deposits and withdrawals are not.
+type DailyReward struct {
+	Day    time.Time `json:"day"`
+	Reward int64     `json:"reward"`
+}
+
+// DailyRewards return the reward of every day and their sum.
+func DailyRewards(dailies []*dbmodels.ValidatorRewardDaily) ([]*DailyReward, int64) {
+	rewards := make([]*DailyReward, 0, len(dailies))
+	total := int64(0)
+	for _, daily := range dailies {
+		reward := daily.AttestationRewards + daily.ProposerRewards
+		rewards = append(rewards, &DailyReward{Day: daily.Day, Reward: reward})
+		total += reward
+	}
+	return rewards, total
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func TestDailyRewards(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	rewards, total := DailyRewards([]*dbmodels.ValidatorRewardDaily{
+		{Day: day, AttestationRewards: 2000000, ProposerRewards: 500000},
+		{Day: day.AddDate(0, 0, 1), AttestationRewards: -1500000},
+	})
+	assert.Len(t, rewards, 2)
+	assert.Equal(t, int64(2500000), rewards[0].Reward)
+	assert.Equal(t, int64(-1500000), rewards[1].Reward)
+	assert.Equal(t, int64(1000000), total)
+}
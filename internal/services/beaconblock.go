@@ -1,9 +1,22 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+const (
+	// invalidateBatch is the most blocks whose cached copies are dropped by one redis command.
+	invalidateBatch = 500
+	// apiBlockPruneInterval is the number of saved Beacon API blocks between two checks of the cap.
+	apiBlockPruneInterval = 100
 )
 
 type BeaconBlockService struct {
@@ -19,3 +32,399 @@ func NewBeaconBlockService(db *gorm.DB, redis *redis.Client, logger *logrus.Logg
 		logger: logger,
 	}
 }
+
+// ClientDiversity is the number of blocks proposed by a client pair within an epoch.
+type ClientDiversity struct {
+	EpochNumber     uint64 `json:"epoch_number"`
+	ConsensusClient string `json:"consensus_client"`
+	ExecutionClient string `json:"execution_client"`
+	Blocks          int64  `json:"blocks"`
+}
+
+// GetClientDiversity count proposed blocks by consensus and execution client for epochs in [start, end].
+func (s *BeaconBlockService) GetClientDiversity(start, end uint64) ([]*ClientDiversity, error) {
+	var res []*ClientDiversity
+	result := s.db.Model(&dbmodels.BeaconBlock{}).
+		Select("epoch_number, consensus_client, execution_client, count(*) as blocks").
+		Where("epoch_number >= ? AND epoch_number <= ?", start, end).
+		Group("epoch_number, consensus_client, execution_client").
+		Order("epoch_number, blocks desc").
+		Scan(&res)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return res, nil
+}
+
+// GetBlocksBySlotRange return the indexed blocks with slot in [start, end] ordered by slot.
+func (s *BeaconBlockService) GetBlocksBySlotRange(start, end uint64) ([]*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("slot_number >= ? AND slot_number <= ?", start, end).Order("slot_number").Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// GetBlocksBefore return up to limit indexed blocks with slot below slot, highest first.
+func (s *BeaconBlockService) GetBlocksBefore(slot uint64, limit int) ([]*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("slot_number < ?", slot).Order("slot_number desc").Limit(limit).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// ErrReorgTooDeep is returned by FindCommonAncestor when no canonical ancestor is found within the depth.
+var ErrReorgTooDeep = errors.New("reorg deeper than max depth")
+
+// FindCommonAncestor find the highest indexed block below slot which is finalized or whose
+// root is the canonical root of its slot. canonicalRoot return the canonical block root of a
+// slot, empty if the slot has no block. Return the slot of the ancestor and the number of
+// indexed blocks between it and slot, which are orphaned.
+func (s *BeaconBlockService) FindCommonAncestor(slot uint64, maxDepth int, canonicalRoot func(slot uint64) (string, error)) (uint64, int, error) {
+	blocks, err := s.GetBlocksBefore(slot, maxDepth)
+	if err != nil {
+		return 0, 0, err
+	}
+	for depth, blk := range blocks {
+		// a block without recorded root can not be compared, the unwind stop there.
+		if blk.Finalized || blk.BlockRoot == "" {
+			return blk.SlotNumber, depth, nil
+		}
+		root, err := canonicalRoot(blk.SlotNumber)
+		if err != nil {
+			return 0, 0, err
+		}
+		if strings.EqualFold(root, blk.BlockRoot) {
+			return blk.SlotNumber, depth, nil
+		}
+	}
+	return 0, 0, ErrReorgTooDeep
+}
+
+// DeleteBlocksBetween remove the indexed blocks with slot in (after, before) and everything
+// indexed from them, used to unwind a reorg.
+func (s *BeaconBlockService) DeleteBlocksBetween(after, before uint64) error {
+	var slots []uint64
+	if err := s.db.Model(&dbmodels.BeaconBlock{}).
+		Where("slot_number > ? AND slot_number < ?", after, before).
+		Pluck("slot_number", &slots).Error; err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return deleteIndexedSlots(tx, slots)
+	})
+}
+
+// GetPayloadBlocks return up to limit blocks with slot in (afterSlot, maxSlot] ordered by slot.
+func (s *BeaconBlockService) GetPayloadBlocks(afterSlot, maxSlot uint64, limit int) ([]*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("slot_number > ? AND slot_number <= ?", afterSlot, maxSlot).
+		Order("slot_number").Limit(limit).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// UpdatePayloadStatus set the payload status of block and drop its cached copies.
+func (s *BeaconBlockService) UpdatePayloadStatus(block *dbmodels.BeaconBlock, status string) error {
+	if err := s.db.Model(&dbmodels.BeaconBlock{}).Where("id = ?", block.ID).Update("payload_status", status).Error; err != nil {
+		return err
+	}
+	s.InvalidateCache(block)
+	return nil
+}
+
+// InvalidateCache drop the cached copies of block, after the data served with it changed.
+func (s *BeaconBlockService) InvalidateCache(block *dbmodels.BeaconBlock) {
+	if s.redis == nil {
+		return
+	}
+	s.redis.Del(context.Background(), fmt.Sprintf("block:slot:%d", block.SlotNumber),
+		fmt.Sprintf("block:root:%s", strings.ToLower(block.BlockRoot)))
+}
+
+// invalidateBlocks drop the cached copies of the deleted blocks, by batch of keys.
+func (s *BeaconBlockService) invalidateBlocks(blocks []*dbmodels.BeaconBlock) {
+	if s.redis == nil {
+		return
+	}
+	keys := make([]string, 0, 2*invalidateBatch)
+	for i, block := range blocks {
+		keys = append(keys, fmt.Sprintf("block:slot:%d", block.SlotNumber),
+			fmt.Sprintf("block:root:%s", strings.ToLower(block.BlockRoot)))
+		if len(keys) >= 2*invalidateBatch || i == len(blocks)-1 {
+			if err := s.redis.Del(context.Background(), keys...).Err(); err != nil {
+				s.logger.WithError(err).Warn("invalidate cached blocks failed")
+			}
+			keys = keys[:0]
+		}
+	}
+}
+
+// BlockWithExecution is a beacon block joined with the execution block of its payload.
+type BlockWithExecution struct {
+	Beacon    *dbmodels.BeaconBlock     `json:"beacon"`
+	Execution *dbmodels.Eth1BlockHeader `json:"execution"`
+}
+
+// GetBlockWithExecution return the beacon block at slot and its indexed execution block, if any.
+func (s *BeaconBlockService) GetBlockWithExecution(slot uint64) (*BlockWithExecution, error) {
+	var block dbmodels.BeaconBlock
+	result := s.db.Where("slot_number = ?", slot).First(&block)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	res := &BlockWithExecution{Beacon: &block}
+	if block.ExecutionBlockHash == "" {
+		return res, nil
+	}
+	var header dbmodels.Eth1BlockHeader
+	result = s.db.Where("block_hash = ?", block.ExecutionBlockHash).Limit(1).Find(&header)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		res.Execution = &header
+	}
+	return res, nil
+}
+
+// BlockFilter select blocks, nil fields are not filtered.
+type BlockFilter struct {
+	Epoch    *uint64
+	Proposer *uint64
+	FromSlot *uint64
+	ToSlot   *uint64
+	Graffiti string // case insensitive substring of the decoded graffiti
+}
+
+// GetBlocks return a page of blocks matching filter, newest first, with the total count.
+func (s *BeaconBlockService) GetBlocks(filter BlockFilter, page, pageSize int) ([]*dbmodels.BeaconBlock, int64, error) {
+	query := s.db.Model(&dbmodels.BeaconBlock{})
+	if filter.Epoch != nil {
+		query = query.Where("epoch_number = ?", *filter.Epoch)
+	}
+	if filter.Proposer != nil {
+		query = query.Where("proposer_index = ?", *filter.Proposer)
+	}
+	if filter.FromSlot != nil {
+		query = query.Where("slot_number >= ?", *filter.FromSlot)
+	}
+	if filter.ToSlot != nil {
+		query = query.Where("slot_number <= ?", *filter.ToSlot)
+	}
+	if filter.Graffiti != "" {
+		query = query.Where("graffiti_text ILIKE ?", "%"+escapeLike(filter.Graffiti)+"%")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var blocks []*dbmodels.BeaconBlock
+	result := query.Order("slot_number desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&blocks)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return blocks, total, nil
+}
+
+// GetBlockBySlot return the block at slot, finalized blocks are served from the cache.
+func (s *BeaconBlockService) GetBlockBySlot(slot uint64) (*dbmodels.BeaconBlock, error) {
+	key := fmt.Sprintf("block:slot:%d", slot)
+	var block dbmodels.BeaconBlock
+	if getCache(s.redis, key, &block) {
+		return &block, nil
+	}
+	if err := s.db.Where("slot_number = ?", slot).First(&block).Error; err != nil {
+		return nil, err
+	}
+	if block.Finalized {
+		setCache(s.redis, key, &block, finalizedCacheTTL)
+	}
+	return &block, nil
+}
+
+// GetBlockByRoot return the block with the beacon block root, finalized blocks are served from the cache.
+func (s *BeaconBlockService) GetBlockByRoot(root string) (*dbmodels.BeaconBlock, error) {
+	root = strings.ToLower(root)
+	key := fmt.Sprintf("block:root:%s", root)
+	var block dbmodels.BeaconBlock
+	if getCache(s.redis, key, &block) {
+		return &block, nil
+	}
+	if err := s.db.Where("block_root = ?", root).First(&block).Error; err != nil {
+		return nil, err
+	}
+	if block.Finalized {
+		setCache(s.redis, key, &block, finalizedCacheTTL)
+	}
+	return &block, nil
+}
+
+// GetLatestBlock return the indexed block with the highest slot.
+func (s *BeaconBlockService) GetLatestBlock() (*dbmodels.BeaconBlock, error) {
+	var block dbmodels.BeaconBlock
+	if err := s.db.Order("slot_number desc").First(&block).Error; err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// CountBlocksByEpoch return the number of indexed blocks of the epoch.
+func (s *BeaconBlockService) CountBlocksByEpoch(epoch uint64) (int64, error) {
+	var count int64
+	result := s.db.Model(&dbmodels.BeaconBlock{}).Where("epoch_number = ?", epoch).Count(&count)
+	return count, result.Error
+}
+
+// DeleteBlocksInRange remove the blocks in the slot range together with their attestations,
+// deposits, execution requests and cached copies, so that the range can be indexed again.
+func (s *BeaconBlockService) DeleteBlocksInRange(start, end uint64) (int64, error) {
+	var blocks []*dbmodels.BeaconBlock
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("slot_number", "block_root").Where("slot_number >= ? AND slot_number <= ?", start, end).
+			Find(&blocks).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&dbmodels.BeaconAttestation{},
+			&dbmodels.BeaconBlockDeposit{},
+			&dbmodels.BeaconDepositRequest{},
+			&dbmodels.BeaconWithdrawalRequest{},
+			&dbmodels.BeaconConsolidationRequest{},
+		} {
+			if err := tx.Unscoped().Where("slot_number >= ? AND slot_number <= ?", start, end).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		// unscoped, a soft deleted block would still hold the unique slot index.
+		return tx.Unscoped().Where("slot_number >= ? AND slot_number <= ?", start, end).Delete(&dbmodels.BeaconBlock{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.invalidateBlocks(blocks)
+	return int64(len(blocks)), nil
+}
+
+// GetBlocksBySlots return the blocks at the slots, missed slots are skipped.
+func (s *BeaconBlockService) GetBlocksBySlots(slots []uint64) ([]*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("slot_number IN ?", slots).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// GetBlocksByEpochs return the blocks of the epochs ordered by slot.
+func (s *BeaconBlockService) GetBlocksByEpochs(epochs []uint64) ([]*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("epoch_number IN ?", epochs).Order("slot_number").Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// GetFinalizedBlockByStateRoot return the finalized block with the post state root, or nil if none.
+func (s *BeaconBlockService) GetFinalizedBlockByStateRoot(root string) (*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("state_root = ? AND finalized = ?", strings.ToLower(root), true).Limit(1).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	return blocks[0], nil
+}
+
+// GetFinalizedBlockByParentRoot return the finalized child of the block with root, or nil if none.
+func (s *BeaconBlockService) GetFinalizedBlockByParentRoot(root string) (*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("parent_root = ? AND finalized = ?", strings.ToLower(root), true).Limit(1).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	return blocks[0], nil
+}
+
+// GetApiBlock return the saved Beacon API response of the block at slot, or nil if not saved.
+func (s *BeaconBlockService) GetApiBlock(slot uint64) (*dbmodels.BeaconApiBlock, error) {
+	var blocks []*dbmodels.BeaconApiBlock
+	result := s.db.Where("slot_number = ?", slot).Limit(1).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	return blocks[0], nil
+}
+
+// SaveApiBlock save the Beacon API response of a finalized block, kept as first saved. The
+// saved responses are a read-through cache of the beacon node, only the maxSaved latest saved
+// are kept, all of them if maxSaved is 0.
+func (s *BeaconBlockService) SaveApiBlock(block *dbmodels.BeaconApiBlock, maxSaved int) error {
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slot_number"}},
+		DoNothing: true,
+	}).Create(block)
+	if result.Error != nil || result.RowsAffected == 0 || maxSaved <= 0 || block.ID%apiBlockPruneInterval != 0 {
+		return result.Error
+	}
+	return s.db.Exec(`DELETE FROM beacon_api_blocks WHERE id <= (
+	SELECT id FROM beacon_api_blocks ORDER BY id DESC OFFSET ? LIMIT 1)`, maxSaved).Error
+}
+
+// deleteIndexedSlots remove the blocks at slots with everything indexed from them.
+func deleteIndexedSlots(db *gorm.DB, slots []uint64) error {
+	if len(slots) == 0 {
+		return nil
+	}
+	for _, model := range []interface{}{
+		&dbmodels.BeaconAttestation{},
+		&dbmodels.BeaconBlockDeposit{},
+		&dbmodels.BeaconDepositRequest{},
+		&dbmodels.BeaconWithdrawalRequest{},
+		&dbmodels.BeaconConsolidationRequest{},
+		&dbmodels.BeaconBlock{},
+	} {
+		if err := db.Unscoped().Where("slot_number IN ?", slots).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveRequestValidatorIndices fill the validator indices of the execution requests left
+// unresolved when indexed, such as deposits of new validators, from the validator registry.
+// Return the number of requests resolved.
+func (s *BeaconBlockService) ResolveRequestValidatorIndices() (int64, error) {
+	var resolved int64
+	for _, stmt := range []string{
+		"UPDATE beacon_deposit_requests r SET validator_index = v.validator_index FROM validators v " +
+			"WHERE r.validator_index IS NULL AND r.pubkey = v.pubkey",
+		"UPDATE beacon_withdrawal_requests r SET validator_index = v.validator_index FROM validators v " +
+			"WHERE r.validator_index IS NULL AND r.validator_pubkey = v.pubkey",
+		"UPDATE beacon_consolidation_requests r SET source_index = v.validator_index FROM validators v " +
+			"WHERE r.source_index IS NULL AND r.source_pubkey = v.pubkey",
+		"UPDATE beacon_consolidation_requests r SET target_index = v.validator_index FROM validators v " +
+			"WHERE r.target_index IS NULL AND r.target_pubkey = v.pubkey",
+	} {
+		result := s.db.Exec(stmt)
+		if result.Error != nil {
+			return resolved, result.Error
+		}
+		resolved += result.RowsAffected
+	}
+	return resolved, nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func TestInvalidateBlocks(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	s := NewBeaconBlockService(nil, client, logger)
+
+	blocks := make([]*dbmodels.BeaconBlock, 0, invalidateBatch+1)
+	keys := make([]string, 0, 2*invalidateBatch+2)
+	for slot := uint64(0); slot <= invalidateBatch; slot++ {
+		root := fmt.Sprintf("0xAB%02d", slot)
+		blocks = append(blocks, &dbmodels.BeaconBlock{SlotNumber: slot, BlockRoot: root})
+		keys = append(keys, fmt.Sprintf("block:slot:%d", slot), fmt.Sprintf("block:root:0xab%02d", slot))
+	}
+	mock.ExpectDel(keys[:2*invalidateBatch]...).SetVal(2 * invalidateBatch)
+	mock.ExpectDel(keys[2*invalidateBatch:]...).SetVal(2)
+
+	s.invalidateBlocks(blocks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
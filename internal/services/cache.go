@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// finalizedCacheTTL is long as finalized data never change, the ttl only bound memory use.
+const finalizedCacheTTL = 6 * time.Hour
+
+// getCache decode the cached value of key into v, return false on miss or when redis is not available.
+func getCache(rdb *redis.Client, key string, v interface{}) bool {
+	if rdb == nil {
+		return false
+	}
+	data, err := rdb.Get(context.Background(), key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// setCache store v under key, failures are ignored as the database stay the source of truth.
+func setCache(rdb *redis.Client, key string, v interface{}, ttl time.Duration) {
+	if rdb == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	rdb.Set(context.Background(), key, data, ttl)
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DepositService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewDepositService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *DepositService {
+	return &DepositService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// DepositRootVote is a distinct eth1 data vote found in the indexed beacon blocks.
+type DepositRootVote struct {
+	Eth1DepositCount uint64
+	Eth1DepositRoot  string
+	SlotNumber       uint64
+}
+
+func (s *DepositService) SaveDeposits(deposits []*dbmodels.Deposit) error {
+	if len(deposits) == 0 {
+		return nil
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(deposits, eth1BatchSize).Error
+}
+
+// GetLastDeposit return the deposit with the highest index, or nil if none indexed.
+func (s *DepositService) GetLastDeposit() (*dbmodels.Deposit, error) {
+	var deposit dbmodels.Deposit
+	result := s.db.Order("deposit_index desc").First(&deposit)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &deposit, nil
+}
+
+// EachDepositDataRoot call fn with the deposit data roots in index order.
+func (s *DepositService) EachDepositDataRoot(fn func(index uint64, root string) error) error {
+	var batch []*dbmodels.Deposit
+	result := s.db.Select("id, deposit_index, deposit_data_root").Order("deposit_index").
+		FindInBatches(&batch, 10000, func(tx *gorm.DB, _ int) error {
+			for _, deposit := range batch {
+				if err := fn(deposit.DepositIndex, deposit.DepositDataRoot); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	return result.Error
+}
+
+func (s *DepositService) GetDepositByIndex(index uint64) (*dbmodels.Deposit, error) {
+	var deposit dbmodels.Deposit
+	result := s.db.Where("deposit_index = ?", index).First(&deposit)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &deposit, nil
+}
+
+func (s *DepositService) GetDepositsByPubkey(pubkey string) ([]*dbmodels.Deposit, error) {
+	var deposits []*dbmodels.Deposit
+	result := s.db.Where("pubkey = ?", pubkey).Order("deposit_index").Find(&deposits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return deposits, nil
+}
+
+func (s *DepositService) GetDepositsByTransaction(hash string) ([]*dbmodels.Deposit, error) {
+	var deposits []*dbmodels.Deposit
+	result := s.db.Where("transaction_hash = ?", hash).Order("deposit_index").Find(&deposits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return deposits, nil
+}
+
+// LinkDepositRequests set the beacon slot of deposits received through EIP-6110 requests.
+func (s *DepositService) LinkDepositRequests() (int64, error) {
+	result := s.db.Exec(`UPDATE deposits SET beacon_slot = r.slot_number
+		FROM beacon_deposit_requests r
+		WHERE deposits.beacon_slot IS NULL AND r.deleted_at IS NULL AND r.deposit_index = deposits.deposit_index`)
+	return result.RowsAffected, result.Error
+}
+
+// LinkValidators set the validator index of deposits whose pubkey is in the validator registry.
+func (s *DepositService) LinkValidators() (int64, error) {
+	result := s.db.Exec(`UPDATE deposits SET validator_index = v.validator_index
+		FROM validators v
+		WHERE deposits.validator_index IS NULL AND v.pubkey = deposits.pubkey`)
+	return result.RowsAffected, result.Error
+}
+
+// DepositCursor is the position of a block deposit, LinkBlockDeposits continue after it.
+type DepositCursor struct {
+	SlotNumber      uint64
+	DepositPosition int
+}
+
+// LinkBlockDeposits match a page of block deposits after cursor, or from the first if nil, to
+// the indexed deposit logs. The chain process deposits in index order, so identical deposit data
+// is matched to the lowest unlinked index. Return the number linked and the cursor of the next
+// page, nil when there is no more.
+func (s *DepositService) LinkBlockDeposits(cursor *DepositCursor, limit int) (int, *DepositCursor, error) {
+	var pending []*dbmodels.BeaconBlockDeposit
+	query := s.db.Where("deposit_index IS NULL")
+	if cursor != nil {
+		query = query.Where("(slot_number, deposit_position) > (?, ?)", cursor.SlotNumber, cursor.DepositPosition)
+	}
+	result := query.Order("slot_number, deposit_position").Limit(limit).Find(&pending)
+	if result.Error != nil {
+		return 0, nil, result.Error
+	}
+	var next *DepositCursor
+	if len(pending) == limit {
+		last := pending[len(pending)-1]
+		next = &DepositCursor{SlotNumber: last.SlotNumber, DepositPosition: last.DepositPosition}
+	}
+	linked := 0
+	for _, blkDeposit := range pending {
+		var deposit dbmodels.Deposit
+		result := s.db.Where("beacon_slot IS NULL AND pubkey = ? AND withdrawal_credentials = ? AND amount = ? AND signature = ?",
+			blkDeposit.Pubkey, blkDeposit.WithdrawalCredentials, blkDeposit.Amount, blkDeposit.Signature).
+			Order("deposit_index").First(&deposit)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if result.Error != nil {
+			return linked, nil, result.Error
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&deposit).Update("beacon_slot", blkDeposit.SlotNumber).Error; err != nil {
+				return err
+			}
+			return tx.Model(blkDeposit).Update("deposit_index", deposit.DepositIndex).Error
+		})
+		if err != nil {
+			return linked, nil, err
+		}
+		linked++
+	}
+	return linked, next, nil
+}
+
+// GetDepositRootVotes return the distinct eth1 data votes with deposit count in (minCount, maxCount].
+func (s *DepositService) GetDepositRootVotes(minCount, maxCount uint64) ([]*DepositRootVote, error) {
+	var votes []*DepositRootVote
+	result := s.db.Model(&dbmodels.BeaconBlock{}).
+		Select("eth1_deposit_count, eth1_deposit_root, min(slot_number) as slot_number").
+		Where("eth1_deposit_count > ? AND eth1_deposit_count <= ?", minCount, maxCount).
+		Group("eth1_deposit_count, eth1_deposit_root").
+		Order("eth1_deposit_count").
+		Scan(&votes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return votes, nil
+}
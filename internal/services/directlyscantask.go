@@ -21,15 +21,70 @@ func NewDirectlyScanTaskService(db *gorm.DB, redis *redis.Client, logger *logrus
 	}
 }
 
+// GetScanTaskByType return the enabled tasks of the type which are not completed yet.
 func (s *DirectlyScanTaskService) GetScanTaskByType(task string) ([]*dbmodels.DirectlyScanTask, error) {
 	var scanTasks []*dbmodels.DirectlyScanTask
-	result := s.db.Where("task_type = ?", task).Find(&scanTasks)
+	result := s.db.Where("task_type = ? AND enabled = ? AND last_number < \"end\"", task, true).Find(&scanTasks)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return scanTasks, nil
 }
 
+// GetPendingLastNumber return the lowest checkpoint of the tasks of the type not completed yet,
+// paused ones included, the slots after it may not be indexed. Return false if there is none.
+func (s *DirectlyScanTaskService) GetPendingLastNumber(task string) (uint64, bool, error) {
+	var pending []uint64
+	result := s.db.Model(&dbmodels.DirectlyScanTask{}).Where("task_type = ? AND last_number < \"end\"", task).
+		Order("last_number").Limit(1).Pluck("last_number", &pending)
+	if result.Error != nil || len(pending) == 0 {
+		return 0, false, result.Error
+	}
+	return pending[0], true, nil
+}
+
+// UpdateScanTask save the progress of the task, progress of a paused task is dropped.
 func (s *DirectlyScanTaskService) UpdateScanTask(task *dbmodels.DirectlyScanTask) {
-	s.db.Save(task)
+	s.db.Model(&dbmodels.DirectlyScanTask{}).Where("id = ? AND enabled = ?", task.ID, true).
+		Update("last_number", task.LastNumber)
+}
+
+func (s *DirectlyScanTaskService) GetScanTasks() ([]*dbmodels.DirectlyScanTask, error) {
+	var scanTasks []*dbmodels.DirectlyScanTask
+	result := s.db.Order("id").Find(&scanTasks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return scanTasks, nil
+}
+
+func (s *DirectlyScanTaskService) GetScanTaskByID(id uint) (*dbmodels.DirectlyScanTask, error) {
+	var scanTask dbmodels.DirectlyScanTask
+	result := s.db.Where("id = ?", id).First(&scanTask)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &scanTask, nil
+}
+
+func (s *DirectlyScanTaskService) CreateScanTask(task *dbmodels.DirectlyScanTask) error {
+	return s.db.Create(task).Error
+}
+
+// SetScanTaskEnabled pause or resume the task, return false if there is no such task.
+func (s *DirectlyScanTaskService) SetScanTaskEnabled(id uint, enabled bool) (bool, error) {
+	result := s.db.Model(&dbmodels.DirectlyScanTask{}).Where("id = ?", id).Update("enabled", enabled)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ResetScanTask move the checkpoint of a paused task, return false if there is no such paused task.
+func (s *DirectlyScanTaskService) ResetScanTask(id uint, lastNumber uint64) (bool, error) {
+	result := s.db.Model(&dbmodels.DirectlyScanTask{}).Where("id = ? AND enabled = ?", id, false).Update("last_number", lastNumber)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteScanTask cancel the task, return false if there is no such task.
+func (s *DirectlyScanTaskService) DeleteScanTask(id uint) (bool, error) {
+	result := s.db.Where("id = ?", id).Delete(&dbmodels.DirectlyScanTask{})
+	return result.RowsAffected > 0, result.Error
 }
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DutyService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewDutyService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *DutyService {
+	return &DutyService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+func (s *DutyService) SaveProposerDuties(duties []*dbmodels.ProposerDuty) error {
+	if len(duties) == 0 {
+		return nil
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(duties, validatorBatchSize).Error
+}
+
+func (s *DutyService) SaveAttesterDuties(duties []*dbmodels.AttesterDuty) error {
+	if len(duties) == 0 {
+		return nil
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(duties, validatorBatchSize).Error
+}
+
+// GetPendingProposerDuties return the not yet reconciled proposer duties up to slot.
+func (s *DutyService) GetPendingProposerDuties(maxSlot uint64) ([]*dbmodels.ProposerDuty, error) {
+	var duties []*dbmodels.ProposerDuty
+	result := s.db.Where("status = ? AND slot <= ?", constant.DUTY_STATUS_PENDING, maxSlot).Order("slot").Find(&duties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return duties, nil
+}
+
+func (s *DutyService) UpdateProposerDutyStatus(id uint, status string) error {
+	return s.db.Model(&dbmodels.ProposerDuty{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// GetPendingAttesterEpochs return the epochs up to maxEpoch which still have pending attester duties.
+func (s *DutyService) GetPendingAttesterEpochs(maxEpoch uint64) ([]uint64, error) {
+	var epochs []uint64
+	result := s.db.Model(&dbmodels.AttesterDuty{}).
+		Where("status = ? AND epoch <= ?", constant.DUTY_STATUS_PENDING, maxEpoch).
+		Distinct("epoch").Order("epoch").Pluck("epoch", &epochs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return epochs, nil
+}
+
+// GetAttesterDutiesBySlot return every attester duty of slot, regardless of status.
+func (s *DutyService) GetAttesterDutiesBySlot(slot uint64) ([]*dbmodels.AttesterDuty, error) {
+	var duties []*dbmodels.AttesterDuty
+	result := s.db.Where("slot = ?", slot).Find(&duties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return duties, nil
+}
+
+// UpdateAttesterDutyStatus set status and inclusion of the given duties.
+func (s *DutyService) UpdateAttesterDutyStatus(ids []uint, status string, inclusionSlot *uint64, inclusionDelay *uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.Model(&dbmodels.AttesterDuty{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":          status,
+		"inclusion_slot":  inclusionSlot,
+		"inclusion_delay": inclusionDelay,
+	}).Error
+}
+
+func (s *DutyService) GetProposerDutiesByValidator(index uint64, limit int) ([]*dbmodels.ProposerDuty, error) {
+	var duties []*dbmodels.ProposerDuty
+	result := s.db.Where("validator_index = ?", index).Order("slot desc").Limit(limit).Find(&duties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return duties, nil
+}
+
+func (s *DutyService) GetAttesterDutiesByValidator(index uint64, limit int) ([]*dbmodels.AttesterDuty, error) {
+	var duties []*dbmodels.AttesterDuty
+	result := s.db.Where("validator_index = ?", index).Order("epoch desc").Limit(limit).Find(&duties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return duties, nil
+}
+
+// CountPendingDuties return the number of not yet reconciled duties of the validators in epoch.
+func (s *DutyService) CountPendingDuties(epoch uint64, indices []uint64) (int64, error) {
+	var proposers, attesters int64
+	if err := s.db.Model(&dbmodels.ProposerDuty{}).
+		Where("epoch = ? AND status = ? AND validator_index IN ?", epoch, constant.DUTY_STATUS_PENDING, indices).
+		Count(&proposers).Error; err != nil {
+		return 0, err
+	}
+	if err := s.db.Model(&dbmodels.AttesterDuty{}).
+		Where("epoch = ? AND status = ? AND validator_index IN ?", epoch, constant.DUTY_STATUS_PENDING, indices).
+		Count(&attesters).Error; err != nil {
+		return 0, err
+	}
+	return proposers + attesters, nil
+}
+
+func (s *DutyService) GetProposerDutiesByEpoch(epoch uint64, indices []uint64, status string) ([]*dbmodels.ProposerDuty, error) {
+	var duties []*dbmodels.ProposerDuty
+	result := s.db.Where("epoch = ? AND status = ? AND validator_index IN ?", epoch, status, indices).Order("slot").Find(&duties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return duties, nil
+}
+
+func (s *DutyService) GetAttesterDutiesByEpoch(epoch uint64, indices []uint64, status string) ([]*dbmodels.AttesterDuty, error) {
+	var duties []*dbmodels.AttesterDuty
+	result := s.db.Where("epoch = ? AND status = ? AND validator_index IN ?", epoch, status, indices).Order("validator_index").Find(&duties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return duties, nil
+}
+
+// AttestationPerformance summarize the attester duties of a validator.
+type AttestationPerformance struct {
+	Total             int64   `json:"total"`
+	Fulfilled         int64   `json:"fulfilled"`
+	Late              int64   `json:"late"`
+	Missed            int64   `json:"missed"`
+	Pending           int64   `json:"pending"`
+	AvgInclusionDelay float64 `json:"avg_inclusion_delay"`
+}
+
+// GetAttestationPerformance count the attester duties of the validator from fromEpoch by status.
+func (s *DutyService) GetAttestationPerformance(index uint64, fromEpoch uint64) (*AttestationPerformance, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+		Delay  float64
+	}
+	result := s.db.Model(&dbmodels.AttesterDuty{}).
+		Select("status, count(*) as count, COALESCE(avg(inclusion_delay), 0) as delay").
+		Where("validator_index = ? AND epoch >= ?", index, fromEpoch).
+		Group("status").Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	perf := &AttestationPerformance{}
+	var delaySum float64
+	for _, row := range rows {
+		perf.Total += row.Count
+		switch row.Status {
+		case constant.DUTY_STATUS_FULFILLED:
+			perf.Fulfilled = row.Count
+		case constant.DUTY_STATUS_LATE:
+			perf.Late = row.Count
+		case constant.DUTY_STATUS_MISSED:
+			perf.Missed = row.Count
+		case constant.DUTY_STATUS_PENDING:
+			perf.Pending = row.Count
+		}
+		delaySum += row.Delay * float64(row.Count)
+	}
+	if included := perf.Fulfilled + perf.Late; included > 0 {
+		perf.AvgInclusionDelay = delaySum / float64(included)
+	}
+	return perf, nil
+}
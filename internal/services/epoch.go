@@ -0,0 +1,67 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EpochService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewEpochService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *EpochService {
+	return &EpochService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// SaveEpochSummary insert or replace the summary of the epoch.
+func (s *EpochService) SaveEpochSummary(summary *dbmodels.EpochSummary) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "epoch"}},
+		UpdateAll: true,
+	}).Create(summary).Error
+}
+
+func (s *EpochService) GetEpochSummary(epoch uint64) (*dbmodels.EpochSummary, error) {
+	var summary dbmodels.EpochSummary
+	result := s.db.Where("epoch = ?", epoch).First(&summary)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &summary, nil
+}
+
+// GetEpochSummaries return a page of epoch summaries, newest first, with the total count.
+func (s *EpochService) GetEpochSummaries(page, pageSize int) ([]*dbmodels.EpochSummary, int64, error) {
+	var total int64
+	if err := s.db.Model(&dbmodels.EpochSummary{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var summaries []*dbmodels.EpochSummary
+	result := s.db.Order("epoch desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&summaries)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return summaries, total, nil
+}
+
+// GetLatestEpochSummary return the summary of the most recent summarized epoch, or nil if none.
+func (s *EpochService) GetLatestEpochSummary() (*dbmodels.EpochSummary, error) {
+	var summaries []*dbmodels.EpochSummary
+	result := s.db.Order("epoch desc").Limit(1).Find(&summaries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(summaries) == 0 {
+		return nil, nil
+	}
+	return summaries[0], nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const eth1BatchSize = 500
+
+type Eth1Service struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewEth1Service(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *Eth1Service {
+	return &Eth1Service{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Eth1Block is everything indexed for one execution block.
+type Eth1Block struct {
+	Header       *dbmodels.Eth1BlockHeader
+	Body         *dbmodels.Eth1BlockBody
+	Transactions []*dbmodels.Eth1Transaction
+	Receipts     []*dbmodels.Eth1Receipt
+	Logs         []*dbmodels.Eth1Log
+}
+
+// SaveBlock store the block and its transactions, receipts and logs in one transaction.
+func (s *Eth1Service) SaveBlock(block *Eth1Block) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		onConflict := tx.Clauses(clause.OnConflict{DoNothing: true})
+		if err := onConflict.Create(block.Header).Error; err != nil {
+			return err
+		}
+		if err := onConflict.Create(block.Body).Error; err != nil {
+			return err
+		}
+		if len(block.Transactions) > 0 {
+			if err := onConflict.CreateInBatches(block.Transactions, eth1BatchSize).Error; err != nil {
+				return err
+			}
+		}
+		if len(block.Receipts) > 0 {
+			if err := onConflict.CreateInBatches(block.Receipts, eth1BatchSize).Error; err != nil {
+				return err
+			}
+		}
+		if len(block.Logs) > 0 {
+			if err := onConflict.CreateInBatches(block.Logs, eth1BatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetHeaderByNumber return the indexed header at number, or nil if not indexed.
+func (s *Eth1Service) GetHeaderByNumber(number uint64) (*dbmodels.Eth1BlockHeader, error) {
+	var header dbmodels.Eth1BlockHeader
+	result := s.db.Where("number = ?", number).First(&header)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &header, nil
+}
+
+func (s *Eth1Service) GetHeaderByHash(hash string) (*dbmodels.Eth1BlockHeader, error) {
+	var header dbmodels.Eth1BlockHeader
+	result := s.db.Where("block_hash = ?", hash).First(&header)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &header, nil
+}
+
+// DeleteFromNumber remove every indexed block from number on, used to unwind a reorg.
+func (s *Eth1Service) DeleteFromNumber(number uint64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("block_number >= ?", number).Delete(&dbmodels.Eth1Log{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number >= ?", number).Delete(&dbmodels.Eth1Receipt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number >= ?", number).Delete(&dbmodels.Eth1Transaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("number >= ?", number).Delete(&dbmodels.Eth1BlockBody{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("number >= ?", number).Delete(&dbmodels.Eth1BlockHeader{}).Error
+	})
+}
+
+func (s *Eth1Service) GetTransactionByHash(hash string) (*dbmodels.Eth1Transaction, error) {
+	var transaction dbmodels.Eth1Transaction
+	result := s.db.Where("hash = ?", hash).First(&transaction)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &transaction, nil
+}
+
+func (s *Eth1Service) GetTransactionsByBlock(number uint64) ([]*dbmodels.Eth1Transaction, error) {
+	var transactions []*dbmodels.Eth1Transaction
+	result := s.db.Where("block_number = ?", number).Order("transaction_index").Find(&transactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transactions, nil
+}
+
+// GetHeadersByNumbers return the indexed headers keyed by number.
+func (s *Eth1Service) GetHeadersByNumbers(numbers []uint64) (map[uint64]*dbmodels.Eth1BlockHeader, error) {
+	var headers []*dbmodels.Eth1BlockHeader
+	res := make(map[uint64]*dbmodels.Eth1BlockHeader)
+	if len(numbers) == 0 {
+		return res, nil
+	}
+	result := s.db.Where("number IN ?", numbers).Find(&headers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, header := range headers {
+		res[header.Number] = header
+	}
+	return res, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FinalityService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewFinalityService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *FinalityService {
+	return &FinalityService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// SaveCheckpoint insert or replace the checkpoint recorded for the epoch.
+func (s *FinalityService) SaveCheckpoint(checkpoint *dbmodels.FinalityCheckpoint) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "epoch"}},
+		UpdateAll: true,
+	}).Create(checkpoint).Error
+}
+
+// GetLatestCheckpoint return the most recent recorded checkpoint, or nil if none recorded yet.
+func (s *FinalityService) GetLatestCheckpoint() (*dbmodels.FinalityCheckpoint, error) {
+	var checkpoint dbmodels.FinalityCheckpoint
+	result := s.db.Order("epoch desc").First(&checkpoint)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &checkpoint, nil
+}
+
+func (s *FinalityService) GetCheckpoints(limit int) ([]*dbmodels.FinalityCheckpoint, error) {
+	var checkpoints []*dbmodels.FinalityCheckpoint
+	result := s.db.Order("epoch desc").Limit(limit).Find(&checkpoints)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return checkpoints, nil
+}
+
+// markFinalizedSQL walk the parent roots from the finalized checkpoint block down to the first
+// block already finalized, flag the blocks walked as finalized and return the walked range.
+const markFinalizedSQL = `
+WITH RECURSIVE chain AS (
+	SELECT id, slot_number, parent_root FROM beacon_blocks WHERE block_root = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT b.id, b.slot_number, b.parent_root FROM beacon_blocks b JOIN chain c ON b.block_root = c.parent_root
+	WHERE b.finalized = false AND b.deleted_at IS NULL
+), marked AS (
+	UPDATE beacon_blocks SET finalized = true FROM chain
+	WHERE beacon_blocks.id = chain.id AND beacon_blocks.finalized = false
+	RETURNING beacon_blocks.id
+)
+SELECT (SELECT COUNT(*) FROM marked) AS marked,
+	(SELECT COUNT(*) FROM chain) AS walked,
+	(SELECT COALESCE(MAX(slot_number), 0) FROM chain) AS highest,
+	COALESCE((SELECT slot_number FROM chain ORDER BY slot_number LIMIT 1), 0) AS lowest,
+	COALESCE((SELECT parent_root FROM chain ORDER BY slot_number LIMIT 1), '') AS lowest_parent`
+
+// MarkFinalized flag the block with root, the finalized checkpoint, and its indexed ancestors
+// as finalized. The blocks not finalized in the walked slot range are not ancestors of the
+// checkpoint, they are removed as orphaned. Return the number of blocks marked and removed.
+func (s *FinalityService) MarkFinalized(root string) (int64, int64, error) {
+	var marked, orphaned int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var walk struct {
+			Marked       int64
+			Walked       int64
+			Highest      uint64
+			Lowest       uint64
+			LowestParent string
+		}
+		if err := tx.Raw(markFinalizedSQL, strings.ToLower(root)).Scan(&walk).Error; err != nil {
+			return err
+		}
+		marked = walk.Marked
+		if walk.Walked == 0 {
+			return nil
+		}
+		// the walk stop at the first finalized ancestor, the orphans down to it are removed too.
+		// When the walk stop at a gap of the index the blocks below are left as they are.
+		from := walk.Lowest
+		var anchor dbmodels.BeaconBlock
+		result := tx.Where("block_root = ? AND finalized = ?", walk.LowestParent, true).Limit(1).Find(&anchor)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			from = anchor.SlotNumber + 1
+		}
+		var slots []uint64
+		if err := tx.Model(&dbmodels.BeaconBlock{}).
+			Where("finalized = ? AND block_root <> '' AND slot_number BETWEEN ? AND ?", false, from, walk.Highest).
+			Pluck("slot_number", &slots).Error; err != nil {
+			return err
+		}
+		orphaned = int64(len(slots))
+		return deleteIndexedSlots(tx, slots)
+	})
+	return marked, orphaned, err
+}
+
+// GetUnfinalizedBlocks return up to limit blocks above after and at or below slot not finalized yet,
+// lowest first. Blocks indexed before the block root was recorded have an empty root.
+func (s *FinalityService) GetUnfinalizedBlocks(after, slot uint64, limit int) ([]*dbmodels.BeaconBlock, error) {
+	var blocks []*dbmodels.BeaconBlock
+	result := s.db.Where("finalized = ? AND slot_number > ? AND slot_number <= ?", false, after, slot).
+		Order("slot_number asc").Limit(limit).Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// FinalizeBlock flag the block finalized with the canonical root of its slot, it fill the
+// root of blocks indexed before the root was recorded.
+func (s *FinalityService) FinalizeBlock(block *dbmodels.BeaconBlock, root string) error {
+	if err := s.db.Model(&dbmodels.BeaconBlock{}).Where("id = ?", block.ID).
+		Updates(map[string]interface{}{"finalized": true, "block_root": strings.ToLower(root)}).Error; err != nil {
+		return err
+	}
+	if block.BlockRoot == "" && s.redis != nil {
+		// the cached block of the slot still miss the root.
+		if err := s.redis.Del(context.Background(), fmt.Sprintf("block:slot:%d", block.SlotNumber)).Err(); err != nil {
+			s.logger.WithError(err).Warn("invalidate cached block failed")
+		}
+	}
+	return nil
+}
+
+// RemoveOrphan remove the block and the data indexed at its slot.
+func (s *FinalityService) RemoveOrphan(block *dbmodels.BeaconBlock) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return deleteIndexedSlots(tx, []uint64{block.SlotNumber})
+	})
+}
+
+func (s *FinalityService) SaveAlert(alert *dbmodels.ChainAlert) error {
+	return s.db.Create(alert).Error
+}
+
+// GetLatestAlert return the most recent alert of the given types, or nil if there is none.
+func (s *FinalityService) GetLatestAlert(types ...string) (*dbmodels.ChainAlert, error) {
+	var alert dbmodels.ChainAlert
+	result := s.db.Where("alert_type IN ?", types).Order("id desc").First(&alert)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &alert, nil
+}
+
+func (s *FinalityService) GetAlerts(limit int) ([]*dbmodels.ChainAlert, error) {
+	var alerts []*dbmodels.ChainAlert
+	result := s.db.Order("id desc").Limit(limit).Find(&alerts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return alerts, nil
+}
+
+// GetCheckpointByEpoch return the checkpoint recorded for the epoch, or nil if none recorded.
+func (s *FinalityService) GetCheckpointByEpoch(epoch uint64) (*dbmodels.FinalityCheckpoint, error) {
+	var checkpoint dbmodels.FinalityCheckpoint
+	result := s.db.Where("epoch = ?", epoch).First(&checkpoint)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &checkpoint, nil
+}
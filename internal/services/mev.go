@@ -0,0 +1,71 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MevService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewMevService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *MevService {
+	return &MevService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+func (s *MevService) SaveRelayPayloads(payloads []*dbmodels.RelayPayload) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(payloads, eth1BatchSize).Error
+}
+
+// GetRelayPayloadsBySlotRange return the delivered payloads with slot in [start, end] grouped by slot.
+func (s *MevService) GetRelayPayloadsBySlotRange(start, end uint64) (map[uint64][]*dbmodels.RelayPayload, error) {
+	var payloads []*dbmodels.RelayPayload
+	result := s.db.Where("slot_number >= ? AND slot_number <= ?", start, end).Order("slot_number, relay").Find(&payloads)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	res := make(map[uint64][]*dbmodels.RelayPayload)
+	for _, payload := range payloads {
+		res[payload.SlotNumber] = append(res[payload.SlotNumber], payload)
+	}
+	return res, nil
+}
+
+// SaveBlockMev insert or replace the attribution of the slot.
+func (s *MevService) SaveBlockMev(mev *dbmodels.BlockMev) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slot_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_hash", "locally_built", "builder_pubkey", "relays", "value", "updated_at"}),
+	}).Create(mev).Error
+}
+
+func (s *MevService) GetBlockMev(slot uint64) (*dbmodels.BlockMev, error) {
+	var mev dbmodels.BlockMev
+	result := s.db.Where("slot_number = ?", slot).First(&mev)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &mev, nil
+}
+
+// GetBlockMevs return the mev attribution of the blocks at the slots.
+func (s *MevService) GetBlockMevs(slots []uint64) ([]*dbmodels.BlockMev, error) {
+	var mevs []*dbmodels.BlockMev
+	result := s.db.Where("slot_number IN ?", slots).Find(&mevs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return mevs, nil
+}
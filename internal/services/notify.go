@@ -0,0 +1,82 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type NotifyService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewNotifyService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *NotifyService {
+	return &NotifyService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// WithTx return a notify service writing through tx, events are queued with the data they describe.
+func (s *NotifyService) WithTx(tx *gorm.DB) *NotifyService {
+	return NewNotifyService(tx, s.redis, s.logger)
+}
+
+// Enqueue persist an event for the webhook, it is delivered by the webhook sender. An event
+// with the key of one already queued for the webhook is skipped.
+func (s *NotifyService) Enqueue(webhook string, eventType string, key string, payload []byte) error {
+	entry := &dbmodels.WebhookOutbox{
+		Webhook:       webhook,
+		EventType:     eventType,
+		Payload:       string(payload),
+		Status:        constant.OUTBOX_STATUS_PENDING,
+		NextAttemptAt: time.Now(),
+	}
+	if key != "" {
+		entry.EventKey = &key
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error
+}
+
+// GetDueOutbox return the pending events whose next attempt is not after now, oldest first.
+func (s *NotifyService) GetDueOutbox(now time.Time, limit int) ([]*dbmodels.WebhookOutbox, error) {
+	var entries []*dbmodels.WebhookOutbox
+	result := s.db.Where("status = ? AND next_attempt_at <= ?", constant.OUTBOX_STATUS_PENDING, now).
+		Order("id").Limit(limit).Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+// SaveDeliveryResult store the delivery log and the updated state of the outbox entry together.
+func (s *NotifyService) SaveDeliveryResult(entry *dbmodels.WebhookOutbox, delivery *dbmodels.WebhookDelivery) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		return tx.Model(&dbmodels.WebhookOutbox{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+			"status":          entry.Status,
+			"attempts":        entry.Attempts,
+			"next_attempt_at": entry.NextAttemptAt,
+			"last_error":      entry.LastError,
+			"delivered_at":    entry.DeliveredAt,
+		}).Error
+	})
+}
+
+func (s *NotifyService) GetDeliveries(outboxID uint) ([]*dbmodels.WebhookDelivery, error) {
+	var deliveries []*dbmodels.WebhookDelivery
+	result := s.db.Where("outbox_id = ?", outboxID).Order("attempt").Find(&deliveries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return deliveries, nil
+}
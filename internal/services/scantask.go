@@ -1,12 +1,17 @@
 package services
 
 import (
+	"errors"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrTaskExists is returned when creating a second task of the same type.
+var ErrTaskExists = errors.New("scan task of this type already exists")
+
 type ScanTaskService struct {
 	db     *gorm.DB
 	redis  *redis.Client
@@ -30,6 +35,94 @@ func (s *ScanTaskService) GetScanTaskByType(task string) (*dbmodels.ScanTask, er
 	return &scanTask, nil
 }
 
+// GetEnabledScanTaskByType return the task of the type if it is not paused.
+func (s *ScanTaskService) GetEnabledScanTaskByType(task string) (*dbmodels.ScanTask, error) {
+	var scanTask dbmodels.ScanTask
+	result := s.db.Where("task_type = ? AND enabled = ?", task, true).First(&scanTask)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &scanTask, nil
+}
+
+// UpdateScanTask save the progress of the task. Progress of a paused task is dropped, so a
+// scanner still running when the task is paused can not overwrite a reset checkpoint.
 func (s *ScanTaskService) UpdateScanTask(task *dbmodels.ScanTask) {
-	s.db.Save(task)
+	s.db.Model(&dbmodels.ScanTask{}).Where("id = ? AND enabled = ?", task.ID, true).
+		Update("last_number", task.LastNumber)
+}
+
+// GetScanTasks return every scan task ordered by id.
+func (s *ScanTaskService) GetScanTasks() ([]*dbmodels.ScanTask, error) {
+	var tasks []*dbmodels.ScanTask
+	result := s.db.Order("id").Find(&tasks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tasks, nil
+}
+
+func (s *ScanTaskService) GetScanTaskByID(id uint) (*dbmodels.ScanTask, error) {
+	var scanTask dbmodels.ScanTask
+	result := s.db.Where("id = ?", id).First(&scanTask)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &scanTask, nil
+}
+
+// CreateScanTask add a task, there is at most one task per type.
+func (s *ScanTaskService) CreateScanTask(task *dbmodels.ScanTask) error {
+	var count int64
+	if err := s.db.Model(&dbmodels.ScanTask{}).Where("task_type = ?", task.TaskType).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTaskExists
+	}
+	return s.db.Create(task).Error
+}
+
+// SetScanTaskEnabled pause or resume the task, return false if there is no such task.
+func (s *ScanTaskService) SetScanTaskEnabled(id uint, enabled bool) (bool, error) {
+	result := s.db.Model(&dbmodels.ScanTask{}).Where("id = ?", id).Update("enabled", enabled)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ResetScanTask move the checkpoint of a paused task, return false if there is no such paused task.
+func (s *ScanTaskService) ResetScanTask(id uint, lastNumber uint64) (bool, error) {
+	result := s.db.Model(&dbmodels.ScanTask{}).Where("id = ? AND enabled = ?", id, false).Update("last_number", lastNumber)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RewindScanTask move the task of the type back to lastNumber if it is past it, return false
+// if the task is missing or not past lastNumber.
+func (s *ScanTaskService) RewindScanTask(taskType string, lastNumber uint64) (bool, error) {
+	result := s.db.Model(&dbmodels.ScanTask{}).Where("task_type = ? AND last_number > ?", taskType, lastNumber).
+		Update("last_number", lastNumber)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteScanTask cancel the task, return false if there is no such task.
+func (s *ScanTaskService) DeleteScanTask(id uint) (bool, error) {
+	result := s.db.Where("id = ?", id).Delete(&dbmodels.ScanTask{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetCursor return the value of the named cursor, false if it was never saved.
+func (s *ScanTaskService) GetCursor(name string) (uint64, bool, error) {
+	var cursor dbmodels.ScanCursor
+	result := s.db.Where("name = ?", name).Limit(1).Find(&cursor)
+	if result.Error != nil {
+		return 0, false, result.Error
+	}
+	return cursor.Value, result.RowsAffected > 0, nil
+}
+
+// SetCursor insert or update the value of the named cursor.
+func (s *ScanTaskService) SetCursor(name string, value uint64) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&dbmodels.ScanCursor{Name: name, Value: value}).Error
 }
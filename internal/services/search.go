@@ -0,0 +1,194 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+const (
+	SearchTypeSlot           = "slot"
+	SearchTypeEpoch          = "epoch"
+	SearchTypeBlockRoot      = "block_root"
+	SearchTypeStateRoot      = "state_root"
+	SearchTypeValidator      = "validator"
+	SearchTypeExecutionBlock = "execution_block"
+	SearchTypeGraffiti       = "graffiti"
+
+	// minGraffitiQuery avoid scanning for one or two characters.
+	minGraffitiQuery = 3
+)
+
+// SearchResult is one typed match, Id identify the matched entity within its type, such as a
+// slot or a validator index, and Slot is set when the match resolve to a beacon block.
+type SearchResult struct {
+	Type  string  `json:"type"`
+	Id    string  `json:"id"`
+	Slot  *uint64 `json:"slot,omitempty"`
+	Label string  `json:"label,omitempty"`
+}
+
+type SearchService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewSearchService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *SearchService {
+	return &SearchService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+type queryKind int
+
+const (
+	queryText queryKind = iota
+	queryNumber
+	queryHash
+	queryPubkey
+)
+
+// classify tell what the query may be, a number, a 32 bytes hash, a 48 bytes pubkey or free text.
+func classify(q string) queryKind {
+	if _, err := strconv.ParseUint(q, 10, 64); err == nil {
+		return queryNumber
+	}
+	lower := strings.ToLower(q)
+	if strings.HasPrefix(lower, "0x") && isHexString(lower[2:]) {
+		switch len(lower) {
+		case 66:
+			return queryHash
+		case 98:
+			return queryPubkey
+		}
+	}
+	return queryText
+}
+
+func isHexString(s string) bool {
+	for _, ch := range s {
+		if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Search return the entities matching q, graffiti matches are bounded by limit.
+func (s *SearchService) Search(q string, limit int) ([]*SearchResult, error) {
+	q = strings.TrimSpace(q)
+	switch classify(q) {
+	case queryNumber:
+		n, _ := strconv.ParseUint(q, 10, 64)
+		return s.searchNumber(n)
+	case queryHash:
+		return s.searchHash(strings.ToLower(q))
+	case queryPubkey:
+		return s.searchPubkey(strings.ToLower(q))
+	default:
+		return s.searchGraffiti(q, limit)
+	}
+}
+
+func (s *SearchService) searchNumber(n uint64) ([]*SearchResult, error) {
+	results := make([]*SearchResult, 0)
+	var blocks []*dbmodels.BeaconBlock
+	if err := s.db.Select("slot_number, block_root").Where("slot_number = ?", n).Limit(1).Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	for _, blk := range blocks {
+		slot := blk.SlotNumber
+		results = append(results, &SearchResult{Type: SearchTypeSlot, Id: strconv.FormatUint(slot, 10), Slot: &slot, Label: blk.BlockRoot})
+	}
+	var epochBlocks int64
+	if err := s.db.Model(&dbmodels.BeaconBlock{}).Where("epoch_number = ?", n).Limit(1).Count(&epochBlocks).Error; err != nil {
+		return nil, err
+	}
+	if epochBlocks > 0 {
+		results = append(results, &SearchResult{Type: SearchTypeEpoch, Id: strconv.FormatUint(n, 10)})
+	}
+	var vals []*dbmodels.Validator
+	if err := s.db.Select("validator_index, pubkey").Where("validator_index = ?", n).Limit(1).Find(&vals).Error; err != nil {
+		return nil, err
+	}
+	for _, val := range vals {
+		results = append(results, &SearchResult{Type: SearchTypeValidator, Id: strconv.FormatUint(val.ValidatorIndex, 10), Label: val.Pubkey})
+	}
+	return results, nil
+}
+
+// searchHash look for a block with the hash as block root, state root or execution block hash.
+func (s *SearchService) searchHash(hash string) ([]*SearchResult, error) {
+	results := make([]*SearchResult, 0)
+	for _, match := range []struct {
+		column string
+		typ    string
+	}{
+		{"block_root", SearchTypeBlockRoot},
+		{"state_root", SearchTypeStateRoot},
+		{"execution_block_hash", SearchTypeExecutionBlock},
+	} {
+		var blocks []*dbmodels.BeaconBlock
+		if err := s.db.Select("slot_number").Where(match.column+" = ?", hash).Limit(1).Find(&blocks).Error; err != nil {
+			return nil, err
+		}
+		for _, blk := range blocks {
+			slot := blk.SlotNumber
+			results = append(results, &SearchResult{Type: match.typ, Id: hash, Slot: &slot})
+		}
+	}
+	if len(results) == 0 {
+		// execution blocks indexed by the eth1 scanner without a beacon block, such as before the merge.
+		var headers []*dbmodels.Eth1BlockHeader
+		if err := s.db.Select("number").Where("block_hash = ?", hash).Limit(1).Find(&headers).Error; err != nil {
+			return nil, err
+		}
+		for _, header := range headers {
+			results = append(results, &SearchResult{Type: SearchTypeExecutionBlock, Id: hash, Label: strconv.FormatUint(header.Number, 10)})
+		}
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchPubkey(pubkey string) ([]*SearchResult, error) {
+	results := make([]*SearchResult, 0)
+	var vals []*dbmodels.Validator
+	if err := s.db.Select("validator_index, pubkey").Where("pubkey = ?", pubkey).Limit(1).Find(&vals).Error; err != nil {
+		return nil, err
+	}
+	for _, val := range vals {
+		results = append(results, &SearchResult{Type: SearchTypeValidator, Id: strconv.FormatUint(val.ValidatorIndex, 10), Label: val.Pubkey})
+	}
+	return results, nil
+}
+
+// searchGraffiti return the newest blocks whose decoded graffiti contain q, served by the
+// trigram index on graffiti_text.
+func (s *SearchService) searchGraffiti(q string, limit int) ([]*SearchResult, error) {
+	results := make([]*SearchResult, 0)
+	if len(q) < minGraffitiQuery {
+		return results, nil
+	}
+	pattern := "%" + escapeLike(q) + "%"
+	var blocks []*dbmodels.BeaconBlock
+	if err := s.db.Select("slot_number, graffiti_text").Where("graffiti_text ILIKE ?", pattern).
+		Order("slot_number desc").Limit(limit).Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	for _, blk := range blocks {
+		slot := blk.SlotNumber
+		results = append(results, &SearchResult{Type: SearchTypeGraffiti, Id: strconv.FormatUint(slot, 10), Slot: &slot, Label: blk.GraffitiText})
+	}
+	return results, nil
+}
+
+// escapeLike escape the LIKE wildcards of s so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
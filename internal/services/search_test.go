@@ -0,0 +1,22 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, queryNumber, classify("123456"))
+	assert.Equal(t, queryHash, classify("0x"+strings.Repeat("ab", 32)))
+	assert.Equal(t, queryHash, classify("0x"+strings.Repeat("AB", 32)))
+	assert.Equal(t, queryPubkey, classify("0x"+strings.Repeat("cd", 48)))
+	assert.Equal(t, queryText, classify("0x"+strings.Repeat("zz", 32)))
+	assert.Equal(t, queryText, classify("lighthouse"))
+	assert.Equal(t, queryText, classify("-1"))
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `100\% \_ok\\`, escapeLike(`100% _ok\`))
+}
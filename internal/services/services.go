@@ -12,6 +12,18 @@ type Services struct {
 	Attest       *AttestationService
 	ScanTask     *ScanTaskService
 	DirectlyScan *DirectlyScanTaskService
+	Validator    *ValidatorService
+	Balance      *BalanceService
+	Duty         *DutyService
+	Epoch        *EpochService
+	Finality     *FinalityService
+	Eth1         *Eth1Service
+	Deposit      *DepositService
+	Mev          *MevService
+	Watchlist    *WatchlistService
+	Notify       *NotifyService
+	Search       *SearchService
+	ApiKey       *ApiKeyService
 }
 
 func NewServices(db *gorm.DB, redis *redis.Client, logger *logrus.Logger, cfg *config.Config) *Services {
@@ -20,5 +32,17 @@ func NewServices(db *gorm.DB, redis *redis.Client, logger *logrus.Logger, cfg *c
 		Attest:       NewAttestationService(db, redis, logger),
 		ScanTask:     NewScanTaskService(db, redis, logger),
 		DirectlyScan: NewDirectlyScanTaskService(db, redis, logger),
+		Validator:    NewValidatorService(db, redis, logger),
+		Balance:      NewBalanceService(db, redis, logger),
+		Duty:         NewDutyService(db, redis, logger),
+		Epoch:        NewEpochService(db, redis, logger),
+		Finality:     NewFinalityService(db, redis, logger),
+		Eth1:         NewEth1Service(db, redis, logger),
+		Deposit:      NewDepositService(db, redis, logger),
+		Mev:          NewMevService(db, redis, logger),
+		Watchlist:    NewWatchlistService(db, redis, logger),
+		Notify:       NewNotifyService(db, redis, logger),
+		Search:       NewSearchService(db, redis, logger),
+		ApiKey:       NewApiKeyService(db, redis, logger),
 	}
 }
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+const validatorBatchSize = 1000
+
+type ValidatorService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewValidatorService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *ValidatorService {
+	return &ValidatorService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// SaveSnapshot store the validator registry at epoch, only validators whose state changed
+// since the previous snapshot are updated and recorded in the history table.
+func (s *ValidatorService) SaveSnapshot(epoch uint64, vals []*dbmodels.Validator) (int, error) {
+	existing := make(map[uint64]*dbmodels.Validator)
+	var batch []*dbmodels.Validator
+	result := s.db.Model(&dbmodels.Validator{}).FindInBatches(&batch, validatorBatchSize*10, func(tx *gorm.DB, _ int) error {
+		for _, v := range batch {
+			existing[v.ValidatorIndex] = v
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	changed := make([]*dbmodels.Validator, 0)
+	histories := make([]*dbmodels.ValidatorHistory, 0)
+	for _, val := range vals {
+		if old, exist := existing[val.ValidatorIndex]; exist && sameValidatorState(old, val) {
+			continue
+		}
+		val.UpdatedEpoch = epoch
+		changed = append(changed, val)
+		histories = append(histories, &dbmodels.ValidatorHistory{
+			ValidatorIndex:             val.ValidatorIndex,
+			Epoch:                      epoch,
+			WithdrawalCredentials:      val.WithdrawalCredentials,
+			EffectiveBalance:           val.EffectiveBalance,
+			Slashed:                    val.Slashed,
+			ActivationEligibilityEpoch: val.ActivationEligibilityEpoch,
+			ActivationEpoch:            val.ActivationEpoch,
+			ExitEpoch:                  val.ExitEpoch,
+			WithdrawableEpoch:          val.WithdrawableEpoch,
+			Status:                     val.Status,
+		})
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "validator_index"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"withdrawal_credentials", "effective_balance", "slashed",
+				"activation_eligibility_epoch", "activation_epoch", "exit_epoch", "withdrawable_epoch",
+				"status", "updated_epoch", "updated_at",
+			}),
+		}).CreateInBatches(changed, validatorBatchSize).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(histories, validatorBatchSize).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+func sameValidatorState(a, b *dbmodels.Validator) bool {
+	return a.WithdrawalCredentials == b.WithdrawalCredentials &&
+		a.EffectiveBalance == b.EffectiveBalance &&
+		a.Slashed == b.Slashed &&
+		a.Status == b.Status &&
+		sameEpoch(a.ActivationEligibilityEpoch, b.ActivationEligibilityEpoch) &&
+		sameEpoch(a.ActivationEpoch, b.ActivationEpoch) &&
+		sameEpoch(a.ExitEpoch, b.ExitEpoch) &&
+		sameEpoch(a.WithdrawableEpoch, b.WithdrawableEpoch)
+}
+
+func sameEpoch(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (s *ValidatorService) GetValidatorByIndex(index uint64) (*dbmodels.Validator, error) {
+	var val dbmodels.Validator
+	result := s.db.Where("validator_index = ?", index).First(&val)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &val, nil
+}
+
+func (s *ValidatorService) GetValidatorByPubkey(pubkey string) (*dbmodels.Validator, error) {
+	var val dbmodels.Validator
+	result := s.db.Where("pubkey = ?", pubkey).First(&val)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &val, nil
+}
+
+// GetValidatorHistory return all recorded state changes of the validator ordered by epoch.
+func (s *ValidatorService) GetValidatorHistory(index uint64) ([]*dbmodels.ValidatorHistory, error) {
+	var histories []*dbmodels.ValidatorHistory
+	result := s.db.Where("validator_index = ?", index).Order("epoch").Find(&histories)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return histories, nil
+}
+
+// GetValidatorAtEpoch return the validator state as of the latest snapshot not after epoch.
+func (s *ValidatorService) GetValidatorAtEpoch(index uint64, epoch uint64) (*dbmodels.ValidatorHistory, error) {
+	var history dbmodels.ValidatorHistory
+	result := s.db.Where("validator_index = ? AND epoch <= ?", index, epoch).Order("epoch desc").First(&history)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &history, nil
+}
+
+// GetValidatorsByWithdrawalAddress return a page of the validators withdrawing to the execution
+// address, through 0x01 or 0x02 withdrawal credentials, ordered by index.
+func (s *ValidatorService) GetValidatorsByWithdrawalAddress(address string, page, pageSize int) ([]*dbmodels.Validator, int64, error) {
+	suffix := strings.Repeat("00", 11) + strings.TrimPrefix(strings.ToLower(address), "0x")
+	query := s.db.Model(&dbmodels.Validator{}).Where("withdrawal_credentials IN ?", []string{"0x01" + suffix, "0x02" + suffix})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var vals []*dbmodels.Validator
+	result := query.Order("validator_index").Offset((page - 1) * pageSize).Limit(pageSize).Find(&vals)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return vals, total, nil
+}
+
+// GetSlashingHistory return the recorded states of the validator since it was slashed.
+func (s *ValidatorService) GetSlashingHistory(index uint64) ([]*dbmodels.ValidatorHistory, error) {
+	var histories []*dbmodels.ValidatorHistory
+	result := s.db.Where("validator_index = ? AND slashed = ?", index, true).Order("epoch").Find(&histories)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return histories, nil
+}
+
+// GetValidatorsByIndices return the validators with the indices, unknown indices are skipped.
+func (s *ValidatorService) GetValidatorsByIndices(indices []uint64) ([]*dbmodels.Validator, error) {
+	var vals []*dbmodels.Validator
+	result := s.db.Where("validator_index IN ?", indices).Find(&vals)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return vals, nil
+}
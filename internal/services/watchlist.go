@@ -0,0 +1,55 @@
+package services
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WatchlistService struct {
+	db     *gorm.DB
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+func NewWatchlistService(db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *WatchlistService {
+	return &WatchlistService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Add watch the validator, updating the label if it is already watched.
+func (s *WatchlistService) Add(index uint64, label string) (*dbmodels.WatchedValidator, error) {
+	watched := &dbmodels.WatchedValidator{ValidatorIndex: index, Label: label}
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "validator_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "updated_at"}),
+	}).Create(watched)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watched, nil
+}
+
+// Remove stop watching the validator, return false if it was not watched.
+func (s *WatchlistService) Remove(index uint64) (bool, error) {
+	result := s.db.Where("validator_index = ?", index).Delete(&dbmodels.WatchedValidator{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (s *WatchlistService) List() ([]*dbmodels.WatchedValidator, error) {
+	var watched []*dbmodels.WatchedValidator
+	result := s.db.Order("validator_index").Find(&watched)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watched, nil
+}
+
+func (s *WatchlistService) MarkSlashed(index uint64) error {
+	return s.db.Model(&dbmodels.WatchedValidator{}).Where("validator_index = ?", index).Update("slashed", true).Error
+}
@@ -0,0 +1,65 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+const (
+	TypeBlock        = "block"
+	TypeAttestations = "attestations"
+	TypeEpochSummary = "epoch_summary"
+	TypeReorg        = "reorg"
+	TypeFinalized    = "finalized_checkpoint"
+	TypeValidator    = "validator_event"
+)
+
+// AttestationBatch hold the attestations included in one block.
+type AttestationBatch struct {
+	Slot         uint64                        `json:"slot"`
+	Attestations []*dbmodels.BeaconAttestation `json:"attestations"`
+}
+
+// Reorg describe blocks of the indexed chain replaced by another fork.
+type Reorg struct {
+	Layer    string `json:"layer"` // "execution" or "consensus"
+	Ancestor uint64 `json:"ancestor"`
+	Depth    uint64 `json:"depth"`
+}
+
+func (p *Publisher) PublishBlock(ctx context.Context, block *dbmodels.BeaconBlock) error {
+	_, err := p.Publish(ctx, TopicBlocks, TypeBlock, block)
+	return err
+}
+
+func (p *Publisher) PublishAttestations(ctx context.Context, slot uint64, atts []*dbmodels.BeaconAttestation) error {
+	if len(atts) == 0 {
+		return nil
+	}
+	_, err := p.Publish(ctx, TopicAttestations, TypeAttestations, &AttestationBatch{Slot: slot, Attestations: atts})
+	return err
+}
+
+func (p *Publisher) PublishEpochSummary(ctx context.Context, summary *dbmodels.EpochSummary) error {
+	_, err := p.Publish(ctx, TopicEpochs, TypeEpochSummary, summary)
+	return err
+}
+
+func (p *Publisher) PublishReorg(ctx context.Context, reorg *Reorg) error {
+	_, err := p.Publish(ctx, TopicReorgs, TypeReorg, reorg)
+	return err
+}
+
+// PublishFinalized publish the checkpoint which advanced the finalized epoch.
+func (p *Publisher) PublishFinalized(ctx context.Context, checkpoint *dbmodels.FinalityCheckpoint) error {
+	_, err := p.Publish(ctx, TopicFinality, TypeFinalized, checkpoint)
+	return err
+}
+
+// PublishValidatorEvent publish an event about a watched validator.
+func (p *Publisher) PublishValidatorEvent(ctx context.Context, event *notify.Event) error {
+	_, err := p.Publish(ctx, TopicValidators, TypeValidator, event)
+	return err
+}
@@ -0,0 +1,423 @@
+// Package stream publish indexed chain data to Redis Streams, downstream services read
+// them with consumer groups instead of polling Postgres.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+)
+
+const (
+	TopicBlocks       = "blocks"
+	TopicAttestations = "attestations"
+	TopicEpochs       = "epochs"
+	TopicReorgs       = "reorgs"
+	TopicFinality     = "finality"
+	TopicValidators   = "validators"
+
+	fieldType = "type"
+	fieldData = "data"
+
+	// tailBuffer is the most entries queued for a tailing client, a client further behind is dropped.
+	tailBuffer = 256
+	tailCount  = 100
+	tailBlock  = 5 * time.Second
+)
+
+var (
+	// ErrTrimmed is returned when entries after the position to resume a feed were trimmed.
+	ErrTrimmed = errors.New("feed position is older than the retained entries")
+	// ErrTooSlow end the feed of a client falling too far behind.
+	ErrTooSlow = errors.New("client too slow for the feed")
+)
+
+// Topics are every stream the indexer publish to.
+var Topics = []string{TopicBlocks, TopicAttestations, TopicEpochs, TopicReorgs, TopicFinality, TopicValidators}
+
+// StreamKey return the redis key of the topic stream.
+func StreamKey(prefix string, topic string) string {
+	return fmt.Sprintf("%s:%s", prefix, topic)
+}
+
+// Message is one entry read from a stream.
+type Message struct {
+	ID    string          `json:"id"`
+	Topic string          `json:"topic,omitempty"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Decode unmarshal the message data into v.
+func (m *Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Data, v)
+}
+
+func toMessage(x redis.XMessage) Message {
+	msg := Message{ID: x.ID}
+	if t, ok := x.Values[fieldType].(string); ok {
+		msg.Type = t
+	}
+	if d, ok := x.Values[fieldData].(string); ok {
+		msg.Data = json.RawMessage(d)
+	}
+	return msg
+}
+
+// Publisher append json encoded entries to the topic streams, capped to about MaxLen entries.
+// A publisher without client or disabled by config drop everything, so callers need no checks.
+type Publisher struct {
+	client *redis.Client
+	prefix string
+	maxLen int64
+}
+
+func NewPublisher(client *redis.Client, cfg config.StreamConfig) *Publisher {
+	if !cfg.Enabled {
+		client = nil
+	}
+	return &Publisher{client: client, prefix: cfg.Prefix, maxLen: cfg.MaxLen}
+}
+
+// Publish add one entry to the topic stream and return its id.
+func (p *Publisher) Publish(ctx context.Context, topic string, msgType string, data interface{}) (string, error) {
+	if p.client == nil {
+		return "", nil
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: StreamKey(p.prefix, topic),
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: []interface{}{fieldType, msgType, fieldData, string(payload)},
+	}).Result()
+}
+
+// Consumer read a topic stream as a member of a consumer group, every entry is delivered to
+// one consumer of the group and stays pending until acknowledged.
+type Consumer struct {
+	client *redis.Client
+	stream string
+	group  string
+	name   string
+}
+
+func NewConsumer(client *redis.Client, prefix string, topic string, group string, name string) *Consumer {
+	return &Consumer{client: client, stream: StreamKey(prefix, topic), group: group, name: name}
+}
+
+// EnsureGroup create the consumer group, and the stream if missing. The group start at
+// start, "$" for new entries only or "0" for the whole stream; an existing group is kept.
+func (c *Consumer) EnsureGroup(ctx context.Context, start string) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.stream, c.group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// Read return up to count entries never delivered to the group, waiting at most block for them.
+func (c *Consumer) Read(ctx context.Context, count int64, block time.Duration) ([]Message, error) {
+	return c.read(ctx, ">", count, block)
+}
+
+// ReadPending return the entries delivered to this consumer but not acknowledged, such as
+// after a crash, so they can be processed again.
+func (c *Consumer) ReadPending(ctx context.Context, count int64) ([]Message, error) {
+	return c.read(ctx, "0", count, -1)
+}
+
+func (c *Consumer) read(ctx context.Context, id string, count int64, block time.Duration) ([]Message, error) {
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.name,
+		Streams:  []string{c.stream, id},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]Message, 0)
+	for _, s := range streams {
+		for _, x := range s.Messages {
+			msgs = append(msgs, toMessage(x))
+		}
+	}
+	return msgs, nil
+}
+
+// Ack acknowledge processed entries, they are no longer pending.
+func (c *Consumer) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.client.XAck(ctx, c.stream, c.group, ids...).Err()
+}
+
+// Subscriber tail topic streams without consumer group, every subscriber see every entry.
+// It serve the live feeds of the api: one reader tail the streams for every client, so that
+// clients hold no redis connection, and each client keep its own position.
+type Subscriber struct {
+	client  *redis.Client
+	prefix  string
+	mux     sync.Mutex
+	running bool
+	topics  []string          // topics read, in the order they were first tailed
+	ids     map[string]string // last entry read of every topic
+	tails   map[*tail]bool
+}
+
+// tail is one client of the reader, the entries of its topics are queued until delivered.
+type tail struct {
+	topics map[string]bool
+	msgs   chan Message
+	done   chan struct{}
+	err    error
+}
+
+func NewSubscriber(client *redis.Client, prefix string) *Subscriber {
+	return &Subscriber{client: client, prefix: prefix, ids: make(map[string]string), tails: make(map[*tail]bool)}
+}
+
+// Check return ErrTrimmed if entries of the topics added after the entry id after were trimmed
+// from their streams, a feed resumed there would miss them. Trimming is only known since Redis 7.
+func (s *Subscriber) Check(ctx context.Context, topics []string, after string) error {
+	if s.client == nil {
+		return errors.New("redis is not available")
+	}
+	for _, topic := range topics {
+		info, err := s.client.XInfoStream(ctx, StreamKey(s.prefix, topic)).Result()
+		if err != nil && strings.Contains(err.Error(), "no such key") {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.MaxDeletedEntryID != "" && idAfter(info.MaxDeletedEntryID, after) {
+			return ErrTrimmed
+		}
+	}
+	return nil
+}
+
+// Tail call fn with the entries of the topics added after the entry id after, "$" for new
+// entries only, until ctx is done or fn fail. Entry ids are millisecond timestamps shared
+// by every stream, so one id is a position in all of them. The entries before the live ones
+// are read topic by topic, callers resuming a feed verify the position with Check first.
+func (s *Subscriber) Tail(ctx context.Context, topics []string, after string, fn func(Message) error) error {
+	if s.client == nil {
+		return errors.New("redis is not available")
+	}
+	t := &tail{topics: make(map[string]bool), msgs: make(chan Message, tailBuffer), done: make(chan struct{})}
+	for _, topic := range topics {
+		t.topics[topic] = true
+	}
+	// join before reading the older entries, the entries read twice are skipped below.
+	if err := s.join(ctx, topics, t); err != nil {
+		return err
+	}
+	defer s.leave(t)
+	last := make(map[string]string, len(topics))
+	if after != "$" {
+		for _, topic := range topics {
+			id, err := s.replay(ctx, topic, after, fn)
+			if err != nil {
+				return err
+			}
+			last[topic] = id
+		}
+	}
+	deliver := func(msg Message) error {
+		if id, exist := last[msg.Topic]; exist && !idAfter(msg.ID, id) {
+			return nil
+		}
+		return fn(msg)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-t.msgs:
+			if err := deliver(msg); err != nil {
+				return err
+			}
+		case <-t.done:
+			// deliver the entries queued before the client was dropped.
+			for {
+				select {
+				case msg := <-t.msgs:
+					if err := deliver(msg); err != nil {
+						return err
+					}
+				default:
+					return t.err
+				}
+			}
+		}
+	}
+}
+
+// replay call fn with the entries of topic after the entry id after, up to the last one, and
+// return the id of the last entry delivered.
+func (s *Subscriber) replay(ctx context.Context, topic string, after string, fn func(Message) error) (string, error) {
+	last := after
+	for {
+		streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{StreamKey(s.prefix, topic), last},
+			Count:   tailCount,
+			Block:   -1,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			return last, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		read := 0
+		for _, st := range streams {
+			for _, x := range st.Messages {
+				msg := toMessage(x)
+				msg.Topic = topic
+				if err := fn(msg); err != nil {
+					return "", err
+				}
+				last = x.ID
+				read++
+			}
+		}
+		if read < tailCount {
+			return last, nil
+		}
+	}
+}
+
+// join add t to the clients of the reader, which is started if not running. Topics read for
+// the first time start at their last entry.
+func (s *Subscriber) join(ctx context.Context, topics []string, t *tail) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, topic := range topics {
+		if _, exist := s.ids[topic]; exist {
+			continue
+		}
+		// resolve "$" once, reading again with "$" would skip entries added between reads.
+		last, err := s.client.XRevRangeN(ctx, StreamKey(s.prefix, topic), "+", "-", 1).Result()
+		if err != nil {
+			return err
+		}
+		s.ids[topic] = "0-0"
+		if len(last) > 0 {
+			s.ids[topic] = last[0].ID
+		}
+		s.topics = append(s.topics, topic)
+	}
+	s.tails[t] = true
+	if !s.running {
+		s.running = true
+		go s.read()
+	}
+	return nil
+}
+
+func (s *Subscriber) leave(t *tail) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.drop(t, nil)
+}
+
+// drop remove t from the clients and end its feed with err, the lock must be held.
+func (s *Subscriber) drop(t *tail, err error) {
+	if !s.tails[t] {
+		return
+	}
+	delete(s.tails, t)
+	t.err = err
+	close(t.done)
+}
+
+// read tail the topics of the clients and queue the entries to them, until there is no client
+// left. A client whose queue is full is dropped rather than delaying the others.
+func (s *Subscriber) read() {
+	for {
+		s.mux.Lock()
+		if len(s.tails) == 0 {
+			s.running = false
+			s.topics, s.ids = nil, make(map[string]string)
+			s.mux.Unlock()
+			return
+		}
+		args := make([]string, 0, 2*len(s.topics))
+		for _, topic := range s.topics {
+			args = append(args, StreamKey(s.prefix, topic))
+		}
+		for _, topic := range s.topics {
+			args = append(args, s.ids[topic])
+		}
+		topics := s.topics
+		s.mux.Unlock()
+
+		streams, err := s.client.XRead(context.Background(), &redis.XReadArgs{
+			Streams: args,
+			Count:   tailCount,
+			Block:   tailBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		s.mux.Lock()
+		if err != nil {
+			for t := range s.tails {
+				s.drop(t, err)
+			}
+		}
+		for _, st := range streams {
+			topic := topics[slices.Index(args[:len(topics)], st.Stream)]
+			for _, x := range st.Messages {
+				msg := toMessage(x)
+				msg.Topic = topic
+				s.ids[topic] = x.ID
+				for t := range s.tails {
+					if !t.topics[topic] {
+						continue
+					}
+					select {
+					case t.msgs <- msg:
+					default:
+						s.drop(t, ErrTooSlow)
+					}
+				}
+			}
+		}
+		s.mux.Unlock()
+	}
+}
+
+// idAfter return true if the entry id a is after the entry id b.
+func idAfter(a, b string) bool {
+	ams, aseq := parseID(a)
+	bms, bseq := parseID(b)
+	return ams > bms || (ams == bms && aseq > bseq)
+}
+
+func parseID(id string) (uint64, uint64) {
+	ms, seq, _ := strings.Cut(id, "-")
+	msv, _ := strconv.ParseUint(ms, 10, 64)
+	seqv, _ := strconv.ParseUint(seq, 10, 64)
+	return msv, seqv
+}
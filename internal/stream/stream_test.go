@@ -0,0 +1,186 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+)
+
+func TestPublishReorg(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	p := NewPublisher(client, config.StreamConfig{Enabled: true, Prefix: "beacon", MaxLen: 1000})
+
+	mock.ExpectXAdd(&redis.XAddArgs{
+		Stream: "beacon:reorgs",
+		MaxLen: 1000,
+		Approx: true,
+		Values: []interface{}{"type", "reorg", "data", `{"layer":"execution","ancestor":100,"depth":2}`},
+	}).SetVal("1-0")
+
+	require.NoError(t, p.PublishReorg(context.Background(), &Reorg{Layer: "execution", Ancestor: 100, Depth: 2}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublishDisabled(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	p := NewPublisher(client, config.StreamConfig{Enabled: false, Prefix: "beacon"})
+
+	require.NoError(t, p.PublishReorg(context.Background(), &Reorg{Layer: "execution"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsumer(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	ctx := context.Background()
+	c := NewConsumer(client, "beacon", TopicReorgs, "alerts", "alerts-1")
+
+	mock.ExpectXGroupCreateMkStream("beacon:reorgs", "alerts", "0").SetErr(errors.New("BUSYGROUP Consumer Group name already exists"))
+	require.NoError(t, c.EnsureGroup(ctx, "0"))
+
+	mock.ExpectXReadGroup(&redis.XReadGroupArgs{
+		Group:    "alerts",
+		Consumer: "alerts-1",
+		Streams:  []string{"beacon:reorgs", ">"},
+		Count:    10,
+		Block:    time.Second,
+	}).SetVal([]redis.XStream{{
+		Stream: "beacon:reorgs",
+		Messages: []redis.XMessage{{
+			ID:     "1-0",
+			Values: map[string]interface{}{"type": "reorg", "data": `{"layer":"execution","ancestor":100,"depth":2}`},
+		}},
+	}})
+	msgs, err := c.Read(ctx, 10, time.Second)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "1-0", msgs[0].ID)
+	assert.Equal(t, TypeReorg, msgs[0].Type)
+	var reorg Reorg
+	require.NoError(t, msgs[0].Decode(&reorg))
+	assert.Equal(t, Reorg{Layer: "execution", Ancestor: 100, Depth: 2}, reorg)
+
+	mock.ExpectXReadGroup(&redis.XReadGroupArgs{
+		Group:    "alerts",
+		Consumer: "alerts-1",
+		Streams:  []string{"beacon:reorgs", ">"},
+		Count:    10,
+		Block:    time.Second,
+	}).RedisNil()
+	msgs, err = c.Read(ctx, 10, time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	mock.ExpectXAck("beacon:reorgs", "alerts", "1-0").SetVal(1)
+	require.NoError(t, c.Ack(ctx, "1-0"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriberTail(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	s := NewSubscriber(client, "beacon")
+	topics := []string{TopicBlocks, TopicReorgs}
+
+	mock.ExpectXRevRangeN("beacon:blocks", "+", "-", 1).SetVal([]redis.XMessage{{ID: "5-0"}})
+	mock.ExpectXRevRangeN("beacon:reorgs", "+", "-", 1).SetVal(nil)
+	mock.ExpectXRead(&redis.XReadArgs{
+		Streams: []string{"beacon:blocks", "beacon:reorgs", "5-0", "0-0"},
+		Count:   100,
+		Block:   5 * time.Second,
+	}).SetVal([]redis.XStream{{
+		Stream:   "beacon:blocks",
+		Messages: []redis.XMessage{{ID: "6-0", Values: map[string]interface{}{"type": "block", "data": `{}`}}},
+	}})
+	mock.ExpectXRead(&redis.XReadArgs{
+		Streams: []string{"beacon:blocks", "beacon:reorgs", "6-0", "0-0"},
+		Count:   100,
+		Block:   5 * time.Second,
+	}).RedisNil()
+	mock.ExpectXRead(&redis.XReadArgs{
+		Streams: []string{"beacon:blocks", "beacon:reorgs", "6-0", "0-0"},
+		Count:   100,
+		Block:   5 * time.Second,
+	}).SetVal([]redis.XStream{{
+		Stream:   "beacon:reorgs",
+		Messages: []redis.XMessage{{ID: "7-0", Values: map[string]interface{}{"type": "reorg", "data": `{}`}}},
+	}})
+
+	errStop := errors.New("stop")
+	var got []Message
+	err := s.Tail(context.Background(), topics, "$", func(msg Message) error {
+		got = append(got, msg)
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	require.Len(t, got, 2)
+	assert.Equal(t, Message{ID: "6-0", Topic: TopicBlocks, Type: TypeBlock, Data: []byte(`{}`)}, got[0])
+	assert.Equal(t, TopicReorgs, got[1].Topic)
+	waitReader(t, s)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// waitReader wait for the reader of s to stop once its clients left.
+func waitReader(t *testing.T, s *Subscriber) {
+	assert.Eventually(t, func() bool {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		return !s.running
+	}, time.Second, time.Millisecond)
+}
+
+func TestSubscriberReplay(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	s := NewSubscriber(client, "beacon")
+
+	mock.ExpectXRead(&redis.XReadArgs{
+		Streams: []string{"beacon:blocks", "5-0"},
+		Count:   tailCount,
+		Block:   -1,
+	}).SetVal([]redis.XStream{{
+		Stream:   "beacon:blocks",
+		Messages: []redis.XMessage{{ID: "6-0", Values: map[string]interface{}{"type": "block", "data": `{}`}}},
+	}})
+
+	var got []Message
+	last, err := s.replay(context.Background(), TopicBlocks, "5-0", func(msg Message) error {
+		got = append(got, msg)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "6-0", last)
+	require.Len(t, got, 1)
+	assert.Equal(t, TopicBlocks, got[0].Topic)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubscriberCheck(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	s := NewSubscriber(client, "beacon")
+	ctx := context.Background()
+
+	mock.ExpectXInfoStream("beacon:blocks").SetVal(&redis.XInfoStream{MaxDeletedEntryID: "5-0"})
+	assert.NoError(t, s.Check(ctx, []string{TopicBlocks}, "5-0"))
+
+	mock.ExpectXInfoStream("beacon:blocks").SetVal(&redis.XInfoStream{MaxDeletedEntryID: "5-1"})
+	assert.ErrorIs(t, s.Check(ctx, []string{TopicBlocks}, "5-0"), ErrTrimmed)
+
+	mock.ExpectXInfoStream("beacon:reorgs").SetErr(errors.New("ERR no such key"))
+	assert.NoError(t, s.Check(ctx, []string{TopicReorgs}, "5-0"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIDAfter(t *testing.T) {
+	assert.True(t, idAfter("10-0", "9-5"))
+	assert.True(t, idAfter("10-1", "10-0"))
+	assert.False(t, idAfter("10-0", "10-0"))
+	assert.False(t, idAfter("9-9", "10-0"))
+}
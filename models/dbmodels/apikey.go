@@ -0,0 +1,16 @@
+package dbmodels
+
+import "time"
+
+// ApiKey API 访问密钥，只保存密钥的哈希
+type ApiKey struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(64)" json:"name"`                   // 密钥名称
+	Prefix    string    `gorm:"type:varchar(16)" json:"prefix"`                 // 密钥前缀，用于识别密钥
+	KeyHash   string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"` // 密钥的 SHA256 哈希
+	Scopes    string    `gorm:"type:varchar(128)" json:"scopes"`                // 权限范围，逗号分隔
+	RateLimit int       `gorm:"default:0" json:"rate_limit"`                    // 每分钟请求数上限，0 表示使用默认值
+	Revoked   bool      `gorm:"default:false" json:"revoked"`                   // 是否已吊销
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package dbmodels
+
+import "time"
+
+// ValidatorBalance 验证者每个采样epoch的余额, 表按 epoch 范围分区, 由 database.Migrate 创建
+type ValidatorBalance struct {
+	Epoch            uint64 `gorm:"primaryKey;autoIncrement:false" json:"epoch"`           // 采样epoch
+	ValidatorIndex   uint64 `gorm:"primaryKey;autoIncrement:false" json:"validator_index"` // 验证者索引
+	Balance          uint64 `gorm:"not null" json:"balance"`                               // 余额(Gwei)
+	EffectiveBalance uint64 `gorm:"not null" json:"effective_balance"`                     // 有效余额(Gwei)
+}
+
+// ValidatorBalanceDaily 验证者余额的按天(UTC)汇总
+type ValidatorBalanceDaily struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	ValidatorIndex   uint64    `gorm:"uniqueIndex:idx_balance_daily_day,priority:1;not null" json:"validator_index"` // 验证者索引
+	Day              time.Time `gorm:"type:date;uniqueIndex:idx_balance_daily_day,priority:2;not null" json:"day"`   // 日期
+	StartEpoch       uint64    `gorm:"not null" json:"start_epoch"`                                                  // 当天开始时的采样epoch, 采样间隔大于一天时为前一天的最后一个采样
+	EndEpoch         uint64    `gorm:"not null" json:"end_epoch"`                                                    // 当天最后一个采样epoch
+	StartBalance     uint64    `gorm:"not null" json:"start_balance"`                                                // 当天开始余额
+	EndBalance       uint64    `gorm:"not null" json:"end_balance"`                                                  // 当天结束余额
+	MinBalance       uint64    `gorm:"not null" json:"min_balance"`                                                  // 当天最低余额
+	MaxBalance       uint64    `gorm:"not null" json:"max_balance"`                                                  // 当天最高余额
+	EffectiveBalance uint64    `gorm:"not null" json:"effective_balance"`                                            // 当天结束时的有效余额
+	CreatedAt        time.Time `json:"-"`
+	UpdatedAt        time.Time `json:"-"`
+}
+
+// ValidatorRewardDaily 验证者奖励的按天(UTC)累计, 由epoch扫描按epoch累加
+type ValidatorRewardDaily struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	ValidatorIndex     uint64    `gorm:"uniqueIndex:idx_reward_daily_day,priority:1;not null" json:"validator_index"` // 验证者索引
+	Day                time.Time `gorm:"type:date;uniqueIndex:idx_reward_daily_day,priority:2;not null" json:"day"`   // 日期
+	AttestationRewards int64     `gorm:"not null" json:"attestation_rewards"`                                         // 当天证明奖励(含惩罚, Gwei)
+	ProposerRewards    int64     `gorm:"not null" json:"proposer_rewards"`                                            // 当天出块奖励(Gwei)
+	LastEpoch          uint64    `gorm:"not null" json:"last_epoch"`                                                  // 已累加的最后一个epoch, 防止重复累加
+	CreatedAt          time.Time `json:"-"`
+	UpdatedAt          time.Time `json:"-"`
+}
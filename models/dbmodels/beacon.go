@@ -6,43 +6,68 @@ import (
 
 type BeaconBlock struct {
 	gorm.Model
-	SlotNumber  uint64 `gorm:"uniqueIndex;not null" json:"slot_number"` // 槽位号
-	EpochNumber uint64 `gorm:"index;not null" json:"epoch_number"`      // Epoch号
+	SlotNumber  uint64 `gorm:"uniqueIndex;not null" json:"slot_number"`  // 槽位号
+	EpochNumber uint64 `gorm:"index;not null" json:"epoch_number"`       // Epoch号
+	Finalized   bool   `gorm:"index;default:false" json:"finalized"`     // 是否已最终确定
+	BlockRoot   string `gorm:"type:varchar(66);index" json:"block_root"` // 区块根哈希
 
 	// 验证者信息
-	ProposerIndex uint64 `gorm:"not null" json:"proposer_index"`               // 验证者索引
-	ParentRoot    string `gorm:"type:varchar(66);not null" json:"parent_root"` // 父区块根哈希
-	StateRoot     string `gorm:"type:varchar(66);not null" json:"state_root"`  // 状态根哈希
+	ProposerIndex uint64 `gorm:"not null" json:"proposer_index"`                    // 验证者索引
+	ParentRoot    string `gorm:"type:varchar(66);not null" json:"parent_root"`      // 父区块根哈希
+	StateRoot     string `gorm:"type:varchar(66);index;not null" json:"state_root"` // 状态根哈希
+	BodyRoot      string `gorm:"type:varchar(66)" json:"body_root"`                 // 区块体根哈希, 早期索引的区块为空
 
 	// RANDAO相关
 	RandaoReveal string `gorm:"type:varchar(194);not null" json:"randao_reveal"` // RANDAO揭示
 
 	// Graffiti
-	Graffiti string `gorm:"type:varchar(66);not null" json:"graffiti"` // Graffiti数据
+	Graffiti         string `gorm:"type:varchar(66);not null" json:"graffiti"`      // Graffiti数据
+	GraffitiText     string `gorm:"type:varchar(64)" json:"graffiti_text"`          // 解码后的Graffiti文本
+	ConsensusClient  string `gorm:"type:varchar(32);index" json:"consensus_client"` // 共识层客户端
+	ConsensusVersion string `gorm:"type:varchar(32)" json:"consensus_version"`      // 共识层客户端版本
+	ExecutionClient  string `gorm:"type:varchar(32);index" json:"execution_client"` // 执行层客户端
+	ExecutionVersion string `gorm:"type:varchar(32)" json:"execution_version"`      // 执行层客户端版本
 
 	// Eth1相关信息
 	Eth1BlockHash    string `gorm:"type:varchar(66)" json:"eth1_block_hash"`   // Eth1区块哈希
 	Eth1DepositRoot  string `gorm:"type:varchar(66)" json:"eth1_deposit_root"` // Eth1存款根
 	Eth1DepositCount uint64 `json:"eth1_deposit_count"`                        // Eth1存款计数
 
+	// 执行层负载
+	ExecutionBlockHash   string  `gorm:"type:varchar(66);index" json:"execution_block_hash"` // 执行层区块哈希, 合并前为空
+	ExecutionBlockNumber *uint64 `gorm:"index" json:"execution_block_number"`                // 执行层区块号
+	PayloadStatus        string  `gorm:"type:varchar(16);index" json:"payload_status"`       // 与执行层区块索引的关联状态
+	FeeRecipient         string  `gorm:"type:varchar(42);index" json:"fee_recipient"`        // 执行层手续费接收地址
+	ExtraData            string  `gorm:"type:varchar(66)" json:"extra_data"`                 // 执行层额外数据
+
+	// 同步委员会
+	SyncAggregateBits string `gorm:"type:varchar(130)" json:"sync_aggregate_bits"` // 同步聚合位图, altair之前为空
+
 	// 签名
 	Signature string `gorm:"type:varchar(194);not null" json:"signature"` // 区块签名
 
 	// Slashing信息
 	ProposerSlashed uint `gorm:"default:0" json:"proposer_slashed"` // 提议者被slash数量
 	AttesterSlashed uint `gorm:"default:0" json:"attester_slashed"` // 证明者被slash数量
+
+	// 执行层请求(Electra)
+	DepositRequests       uint `gorm:"default:0" json:"deposit_requests"`       // 存款请求数量
+	WithdrawalRequests    uint `gorm:"default:0" json:"withdrawal_requests"`    // 提款请求数量
+	ConsolidationRequests uint `gorm:"default:0" json:"consolidation_requests"` // 合并请求数量
 }
 
 type BeaconAttestation struct {
 	gorm.Model
-	SlotNumber      uint64 `gorm:"index;not null" json:"slot_number"`                  // 槽位号
-	AttestIndex     int    `gorm:"not null" json:"attest_index"`                       // 在该slot中的证明索引
-	AggregationBits string `gorm:"type:text;not null" json:"aggregation_bits"`         // 聚合位图
-	BeaconBlockRoot string `gorm:"type:varchar(66);not null" json:"beacon_block_root"` // 关联的区块根哈希
+	SlotNumber      uint64 `gorm:"index;not null" json:"slot_number"`                        // 槽位号
+	AttestIndex     int    `gorm:"not null" json:"attest_index"`                             // 在该slot中的证明索引
+	AttestationSlot uint64 `gorm:"index" json:"attestation_slot"`                            // 证明所针对的槽位号
+	CommitteeBits   string `gorm:"type:varchar(16)" json:"committee_bits"`                   // 委员会位图(Electra)
+	AggregationBits string `gorm:"type:text;not null" json:"aggregation_bits"`               // 聚合位图
+	BeaconBlockRoot string `gorm:"type:varchar(66);index;not null" json:"beacon_block_root"` // 关联的区块根哈希
 	CommitteeIndex  uint64 `gorm:"not null" json:"committee_index"`
 	SourceEpoch     uint64 `gorm:"not null" json:"source_epoch"`
 	SourceRoot      string `gorm:"type:varchar(66);not null" json:"source_root"`
-	TargetEpoch     uint64 `gorm:"not null" json:"target_epoch"`
+	TargetEpoch     uint64 `gorm:"index;not null" json:"target_epoch"`
 	TargetRoot      string `gorm:"type:varchar(66);not null" json:"target_root"`
 	Signature       string `gorm:"type:varchar(194);not null" json:"signature"`
 }
@@ -0,0 +1,12 @@
+package dbmodels
+
+import "time"
+
+// BeaconApiBlock 已最终确定区块的 Beacon API JSON 响应, 由 Beacon API 代理从节点读取后保存, 作为读穿缓存只保留最近保存的部分
+type BeaconApiBlock struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	SlotNumber uint64    `gorm:"uniqueIndex;not null" json:"slot_number"`  // 槽位号
+	Version    string    `gorm:"type:varchar(16);not null" json:"version"` // 区块的分叉版本
+	Body       string    `gorm:"type:text;not null" json:"body"`           // /eth/v2/beacon/blocks 的响应
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package dbmodels
+
+import (
+	"gorm.io/gorm"
+)
+
+// Deposit 存款合约 DepositEvent 日志
+type Deposit struct {
+	gorm.Model
+	DepositIndex          uint64  `gorm:"uniqueIndex;not null" json:"deposit_index"`               // 存款合约中的存款序号
+	Pubkey                string  `gorm:"type:varchar(98);index;not null" json:"pubkey"`           // 验证者公钥
+	WithdrawalCredentials string  `gorm:"type:varchar(66);not null" json:"withdrawal_credentials"` // 提款凭证
+	Amount                uint64  `gorm:"not null" json:"amount"`                                  // 存款金额(Gwei)
+	Signature             string  `gorm:"type:varchar(194);not null" json:"signature"`             // 存款签名
+	DepositDataRoot       string  `gorm:"type:varchar(66);not null" json:"deposit_data_root"`      // DepositData的哈希树根
+	DepositRoot           string  `gorm:"type:varchar(66);not null" json:"deposit_root"`           // 包含该存款后的存款树根
+	BlockNumber           uint64  `gorm:"index;not null" json:"block_number"`                      // 执行层区块号
+	BlockHash             string  `gorm:"type:varchar(66);not null" json:"block_hash"`             // 执行层区块哈希
+	TransactionHash       string  `gorm:"type:varchar(66);index;not null" json:"transaction_hash"` // 存款交易哈希
+	LogIndex              uint    `json:"log_index"`                                               // 区块内日志序号
+	BeaconSlot            *uint64 `gorm:"index" json:"beacon_slot"`                                // 处理该存款的信标区块槽位号
+	ValidatorIndex        *uint64 `gorm:"index" json:"validator_index"`                            // 对应的验证者索引
+}
+
+// BeaconBlockDeposit 信标区块体中包含的存款(EIP-6110之前的存款桥)
+type BeaconBlockDeposit struct {
+	gorm.Model
+	SlotNumber            uint64  `gorm:"uniqueIndex:idx_block_deposit_slot;not null" json:"slot_number"`      // 槽位号
+	DepositPosition       int     `gorm:"uniqueIndex:idx_block_deposit_slot;not null" json:"deposit_position"` // 在该区块中的存款序号
+	Pubkey                string  `gorm:"type:varchar(98);index;not null" json:"pubkey"`                       // 验证者公钥
+	WithdrawalCredentials string  `gorm:"type:varchar(66);not null" json:"withdrawal_credentials"`             // 提款凭证
+	Amount                uint64  `gorm:"not null" json:"amount"`                                              // 存款金额(Gwei)
+	Signature             string  `gorm:"type:varchar(194);not null" json:"signature"`                         // 存款签名
+	DepositIndex          *uint64 `gorm:"index" json:"deposit_index"`                                          // 关联的存款合约序号, 未关联时为空
+}
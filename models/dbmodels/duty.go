@@ -0,0 +1,31 @@
+package dbmodels
+
+import "time"
+
+// ProposerDuty 区块提议职责
+type ProposerDuty struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	Epoch          uint64    `gorm:"index;not null" json:"epoch"`                   // Epoch号
+	Slot           uint64    `gorm:"uniqueIndex;not null" json:"slot"`              // 需要提议区块的槽位号
+	ValidatorIndex uint64    `gorm:"index;not null" json:"validator_index"`         // 验证者索引
+	Status         string    `gorm:"type:varchar(16);index;not null" json:"status"` // 职责完成状态
+	CreatedAt      time.Time `json:"-"`
+	UpdatedAt      time.Time `json:"-"`
+}
+
+// AttesterDuty 证明职责, 与已索引的证明对账后记录包含情况
+type AttesterDuty struct {
+	ID                      uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	Epoch                   uint64    `gorm:"uniqueIndex:idx_attester_duty_validator,priority:1;index:idx_attester_duty_status,priority:1;not null" json:"epoch"`
+	ValidatorIndex          uint64    `gorm:"uniqueIndex:idx_attester_duty_validator,priority:2;index;not null" json:"validator_index"` // 验证者索引
+	Slot                    uint64    `gorm:"index:idx_attester_duty_committee,priority:1;not null" json:"slot"`                        // 需要证明的槽位号
+	CommitteeIndex          uint64    `gorm:"index:idx_attester_duty_committee,priority:2;not null" json:"committee_index"`             // 委员会索引
+	CommitteeLength         uint64    `gorm:"not null" json:"committee_length"`                                                         // 委员会大小
+	CommitteesAtSlot        uint64    `gorm:"not null" json:"committees_at_slot"`                                                       // 该slot的委员会数量
+	ValidatorCommitteeIndex uint64    `gorm:"not null" json:"validator_committee_index"`                                                // 验证者在委员会中的位置
+	Status                  string    `gorm:"type:varchar(16);index:idx_attester_duty_status,priority:2;not null" json:"status"`        // 职责完成状态
+	InclusionSlot           *uint64   `json:"inclusion_slot"`                                                                           // 证明被打包的槽位号
+	InclusionDelay          *uint64   `json:"inclusion_delay"`                                                                          // 打包延迟
+	CreatedAt               time.Time `json:"-"`
+	UpdatedAt               time.Time `json:"-"`
+}
@@ -0,0 +1,41 @@
+package dbmodels
+
+import "time"
+
+// EpochSummary 每个已最终确定的epoch的汇总数据
+type EpochSummary struct {
+	ID    uint   `gorm:"primaryKey;autoIncrement" json:"-"`
+	Epoch uint64 `gorm:"uniqueIndex;not null" json:"epoch"` // Epoch号
+
+	// 区块
+	ProposedBlocks uint `gorm:"not null" json:"proposed_blocks"` // 已提议区块数
+	MissedBlocks   uint `gorm:"not null" json:"missed_blocks"`   // 缺失区块数
+
+	// 证明参与率(按有效余额加权)
+	SourceParticipation float64 `json:"source_participation"`
+	TargetParticipation float64 `json:"target_participation"`
+	HeadParticipation   float64 `json:"head_participation"`
+
+	// 检查点
+	JustifiedEpoch uint64 `json:"justified_epoch"`
+	JustifiedRoot  string `gorm:"type:varchar(66)" json:"justified_root"`
+	FinalizedEpoch uint64 `json:"finalized_epoch"`
+	FinalizedRoot  string `gorm:"type:varchar(66)" json:"finalized_root"`
+
+	// 验证者
+	TotalActiveBalance   uint64 `json:"total_active_balance"` // 活跃验证者有效余额总和(Gwei)
+	ActiveValidators     uint64 `json:"active_validators"`
+	PendingValidators    uint64 `json:"pending_validators"`
+	ExitingValidators    uint64 `json:"exiting_validators"`
+	SlashedValidators    uint64 `json:"slashed_validators"`
+	ExitedValidators     uint64 `json:"exited_validators"`
+	WithdrawalValidators uint64 `json:"withdrawal_validators"`
+
+	// 奖励(Gwei)
+	AttestationRewards int64 `json:"attestation_rewards"`
+	ProposerRewards    int64 `json:"proposer_rewards"`
+	TotalRewards       int64 `json:"total_rewards"`
+
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
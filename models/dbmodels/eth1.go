@@ -7,23 +7,74 @@ type Eth1BlockHeader struct {
 	BlockHash  string `gorm:"type:varchar(66);uniqueIndex"`
 	ParentHash string `gorm:"type:varchar(66);index"`
 	Number     uint64 `gorm:"index"`
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-}
 
-type Eth1BlockBody struct {
+	Miner            string  `gorm:"type:varchar(42);index" json:"miner"`              // 出块者(fee recipient)
+	Timestamp        uint64  `gorm:"not null" json:"timestamp"`                        // 区块时间戳
+	GasLimit         uint64  `json:"gas_limit"`                                        // Gas上限
+	GasUsed          uint64  `json:"gas_used"`                                         // 已使用Gas
+	BaseFeePerGas    string  `gorm:"type:numeric" json:"base_fee_per_gas"`             // 基础费用(wei)
+	BlobGasUsed      *uint64 `json:"blob_gas_used"`                                    // Blob Gas使用量
+	ExcessBlobGas    *uint64 `json:"excess_blob_gas"`                                  // 超额Blob Gas
+	StateRoot        string  `gorm:"type:varchar(66)" json:"state_root"`               // 状态根
+	TransactionsRoot string  `gorm:"type:varchar(66)" json:"transactions_root"`        // 交易根
+	ReceiptsRoot     string  `gorm:"type:varchar(66)" json:"receipts_root"`            // 收据根
+	ExtraData        string  `gorm:"type:varchar(130)" json:"extra_data"`              // 额外数据
+	ParentBeaconRoot string  `gorm:"type:varchar(66);index" json:"parent_beacon_root"` // 父信标区块根
+	Size             uint64  `json:"size"`                                             // 区块大小(字节)
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+type Eth1BlockBody struct {
+	ID               uint   `gorm:"primaryKey;autoIncrement"`
+	BlockHash        string `gorm:"type:varchar(66);uniqueIndex" json:"block_hash"` // 区块哈希
+	Number           uint64 `gorm:"index" json:"number"`                            // 区块号
+	TransactionCount uint   `json:"transaction_count"`                              // 交易数量
+	WithdrawalCount  uint   `json:"withdrawal_count"`                               // 提款数量
+	WithdrawalsRoot  string `gorm:"type:varchar(66)" json:"withdrawals_root"`       // 提款根
+	Withdrawals      string `gorm:"type:jsonb" json:"withdrawals"`                  // 提款列表
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
 type Eth1Transaction struct {
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                   uint    `gorm:"primaryKey;autoIncrement"`
+	Hash                 string  `gorm:"type:varchar(66);uniqueIndex" json:"hash"` // 交易哈希
+	BlockHash            string  `gorm:"type:varchar(66);index" json:"block_hash"` // 区块哈希
+	BlockNumber          uint64  `gorm:"index" json:"block_number"`                // 区块号
+	TransactionIndex     uint    `json:"transaction_index"`                        // 区块内交易序号
+	Type                 uint8   `json:"type"`                                     // 交易类型
+	From                 string  `gorm:"type:varchar(42);index" json:"from"`       // 发送方
+	To                   string  `gorm:"type:varchar(42);index" json:"to"`         // 接收方,合约创建时为空
+	Nonce                uint64  `json:"nonce"`
+	Value                string  `gorm:"type:numeric" json:"value"`                    // 转账金额(wei)
+	Gas                  uint64  `json:"gas"`                                          // Gas上限
+	GasPrice             *string `gorm:"type:numeric" json:"gas_price"`                // Gas价格(wei)
+	MaxFeePerGas         *string `gorm:"type:numeric" json:"max_fee_per_gas"`          // EIP-1559最大费用
+	MaxPriorityFeePerGas *string `gorm:"type:numeric" json:"max_priority_fee_per_gas"` // EIP-1559小费上限
+	MaxFeePerBlobGas     *string `gorm:"type:numeric" json:"max_fee_per_blob_gas"`     // Blob最大费用
+	BlobHashes           uint    `json:"blob_hashes"`                                  // Blob数量
+	Input                string  `gorm:"type:text" json:"input"`                       // 调用数据
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
 }
 
 type Eth1Receipt struct {
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                uint    `gorm:"primaryKey;autoIncrement"`
+	TransactionHash   string  `gorm:"type:varchar(66);uniqueIndex" json:"transaction_hash"` // 交易哈希
+	BlockHash         string  `gorm:"type:varchar(66);index" json:"block_hash"`             // 区块哈希
+	BlockNumber       uint64  `gorm:"index" json:"block_number"`                            // 区块号
+	Status            uint8   `json:"status"`                                               // 执行状态,1成功0失败
+	GasUsed           uint64  `json:"gas_used"`                                             // 实际使用Gas
+	CumulativeGasUsed uint64  `json:"cumulative_gas_used"`                                  // 区块内累计Gas
+	EffectiveGasPrice string  `gorm:"type:numeric" json:"effective_gas_price"`              // 实际Gas价格(wei)
+	ContractAddress   string  `gorm:"type:varchar(42)" json:"contract_address"`             // 创建的合约地址
+	BlobGasUsed       *uint64 `json:"blob_gas_used"`                                        // Blob Gas使用量
+	BlobGasPrice      *string `gorm:"type:numeric" json:"blob_gas_price"`                   // Blob Gas价格
+	LogCount          uint    `json:"log_count"`                                            // 日志数量
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
 
 type Eth1Contract struct {
@@ -37,6 +88,17 @@ type Eth1ContractTransaction struct {
 }
 
 type Eth1Log struct {
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              uint   `gorm:"primaryKey;autoIncrement"`
+	BlockHash       string `gorm:"type:varchar(66);uniqueIndex:idx_eth1_log_block_index" json:"block_hash"` // 区块哈希
+	LogIndex        uint   `gorm:"uniqueIndex:idx_eth1_log_block_index" json:"log_index"`                   // 区块内日志序号
+	BlockNumber     uint64 `gorm:"index" json:"block_number"`                                               // 区块号
+	TransactionHash string `gorm:"type:varchar(66);index" json:"transaction_hash"`                          // 交易哈希
+	Address         string `gorm:"type:varchar(42);index" json:"address"`                                   // 合约地址
+	Topic0          string `gorm:"type:varchar(66);index" json:"topic0"`                                    // 事件签名
+	Topic1          string `gorm:"type:varchar(66)" json:"topic1"`
+	Topic2          string `gorm:"type:varchar(66)" json:"topic2"`
+	Topic3          string `gorm:"type:varchar(66)" json:"topic3"`
+	Data            string `gorm:"type:text" json:"data"` // 日志数据
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
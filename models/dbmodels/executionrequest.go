@@ -0,0 +1,42 @@
+package dbmodels
+
+import (
+	"gorm.io/gorm"
+)
+
+// BeaconDepositRequest EIP-6110 存款请求
+type BeaconDepositRequest struct {
+	gorm.Model
+	SlotNumber            uint64  `gorm:"uniqueIndex:idx_deposit_request_slot;not null" json:"slot_number"`   // 槽位号
+	RequestIndex          int     `gorm:"uniqueIndex:idx_deposit_request_slot;not null" json:"request_index"` // 在该slot中的请求索引
+	Pubkey                string  `gorm:"type:varchar(98);index;not null" json:"pubkey"`                      // 验证者公钥
+	WithdrawalCredentials string  `gorm:"type:varchar(66);not null" json:"withdrawal_credentials"`            // 提款凭证
+	Amount                uint64  `gorm:"not null" json:"amount"`                                             // 存款金额(Gwei)
+	Signature             string  `gorm:"type:varchar(194);not null" json:"signature"`                        // 存款签名
+	DepositIndex          uint64  `gorm:"index;not null" json:"deposit_index"`                                // 存款合约中的存款序号
+	ValidatorIndex        *uint64 `gorm:"index" json:"validator_index"`                                       // 对应的验证者索引, 尚未入列时为空
+}
+
+// BeaconWithdrawalRequest EIP-7002 执行层触发的提款/退出请求
+type BeaconWithdrawalRequest struct {
+	gorm.Model
+	SlotNumber      uint64  `gorm:"uniqueIndex:idx_withdrawal_request_slot;not null" json:"slot_number"`   // 槽位号
+	RequestIndex    int     `gorm:"uniqueIndex:idx_withdrawal_request_slot;not null" json:"request_index"` // 在该slot中的请求索引
+	SourceAddress   string  `gorm:"type:varchar(42);index;not null" json:"source_address"`                 // 发起请求的执行层地址
+	ValidatorPubkey string  `gorm:"type:varchar(98);index;not null" json:"validator_pubkey"`               // 验证者公钥
+	Amount          uint64  `gorm:"not null" json:"amount"`                                                // 提款金额(Gwei), 0 表示完全退出
+	FullExit        bool    `gorm:"default:false" json:"full_exit"`                                        // 是否为完全退出
+	ValidatorIndex  *uint64 `gorm:"index" json:"validator_index"`                                          // 对应的验证者索引
+}
+
+// BeaconConsolidationRequest EIP-7251 验证者合并请求
+type BeaconConsolidationRequest struct {
+	gorm.Model
+	SlotNumber    uint64  `gorm:"uniqueIndex:idx_consolidation_request_slot;not null" json:"slot_number"`   // 槽位号
+	RequestIndex  int     `gorm:"uniqueIndex:idx_consolidation_request_slot;not null" json:"request_index"` // 在该slot中的请求索引
+	SourceAddress string  `gorm:"type:varchar(42);index;not null" json:"source_address"`                    // 发起请求的执行层地址
+	SourcePubkey  string  `gorm:"type:varchar(98);index;not null" json:"source_pubkey"`                     // 源验证者公钥
+	TargetPubkey  string  `gorm:"type:varchar(98);index;not null" json:"target_pubkey"`                     // 目标验证者公钥
+	SourceIndex   *uint64 `gorm:"index" json:"source_index"`                                                // 源验证者索引
+	TargetIndex   *uint64 `gorm:"index" json:"target_index"`                                                // 目标验证者索引
+}
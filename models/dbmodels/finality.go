@@ -0,0 +1,27 @@
+package dbmodels
+
+import "time"
+
+// FinalityCheckpoint 每个epoch记录的head检查点信息
+type FinalityCheckpoint struct {
+	ID                     uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	Epoch                  uint64    `gorm:"uniqueIndex;not null" json:"epoch"` // head所在epoch
+	HeadSlot               uint64    `gorm:"not null" json:"head_slot"`         // 记录时的head槽位号
+	PreviousJustifiedEpoch uint64    `json:"previous_justified_epoch"`          // 上一个justified检查点
+	PreviousJustifiedRoot  string    `gorm:"type:varchar(66)" json:"previous_justified_root"`
+	JustifiedEpoch         uint64    `json:"justified_epoch"` // 当前justified检查点
+	JustifiedRoot          string    `gorm:"type:varchar(66)" json:"justified_root"`
+	FinalizedEpoch         uint64    `gorm:"index" json:"finalized_epoch"` // finalized检查点
+	FinalizedRoot          string    `gorm:"type:varchar(66)" json:"finalized_root"`
+	FinalityDistance       uint64    `json:"finality_distance"` // head与finalized之间的epoch数
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+// ChainAlert 链状态告警
+type ChainAlert struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	AlertType string    `gorm:"type:varchar(50);index;not null" json:"alert_type"` // 告警类型
+	Epoch     uint64    `gorm:"index" json:"epoch"`                                // 触发告警时的epoch
+	Message   string    `gorm:"type:text" json:"message"`                          // 告警内容
+	CreatedAt time.Time `json:"created_at"`
+}
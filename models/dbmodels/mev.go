@@ -0,0 +1,32 @@
+package dbmodels
+
+import (
+	"gorm.io/gorm"
+)
+
+// RelayPayload relay 数据接口返回的已交付 payload 记录
+type RelayPayload struct {
+	gorm.Model
+	Relay                string `gorm:"type:varchar(64);uniqueIndex:idx_relay_payload_slot;not null" json:"relay"` // relay 名称
+	SlotNumber           uint64 `gorm:"uniqueIndex:idx_relay_payload_slot;not null" json:"slot_number"`            // 槽位号
+	BlockHash            string `gorm:"type:varchar(66);index;not null" json:"block_hash"`                         // 执行层区块哈希
+	BlockNumber          uint64 `json:"block_number"`                                                              // 执行层区块号
+	BuilderPubkey        string `gorm:"type:varchar(98);index;not null" json:"builder_pubkey"`                     // 构建者公钥
+	ProposerPubkey       string `gorm:"type:varchar(98)" json:"proposer_pubkey"`                                   // 提议者公钥
+	ProposerFeeRecipient string `gorm:"type:varchar(42)" json:"proposer_fee_recipient"`                            // 提议者手续费接收地址
+	Value                string `gorm:"type:numeric;not null" json:"value"`                                        // 出价金额(wei)
+	GasUsed              uint64 `json:"gas_used"`
+	GasLimit             uint64 `json:"gas_limit"`
+	NumTx                uint   `json:"num_tx"` // 交易数量
+}
+
+// BlockMev 信标区块的 MEV-boost 归属信息
+type BlockMev struct {
+	gorm.Model
+	SlotNumber    uint64 `gorm:"uniqueIndex;not null" json:"slot_number"`      // 槽位号
+	BlockHash     string `gorm:"type:varchar(66);not null" json:"block_hash"`  // 执行层区块哈希
+	LocallyBuilt  bool   `gorm:"index;default:false" json:"locally_built"`     // 是否为本地构建(未经relay交付)
+	BuilderPubkey string `gorm:"type:varchar(98);index" json:"builder_pubkey"` // 构建者公钥
+	Relays        string `gorm:"type:varchar(255)" json:"relays"`              // 交付该payload的relay, 逗号分隔
+	Value         string `gorm:"type:numeric" json:"value"`                    // 出价金额(wei), 多个relay时取最大值
+}
@@ -0,0 +1,31 @@
+package dbmodels
+
+import "time"
+
+// WebhookOutbox 待投递的 webhook 事件
+type WebhookOutbox struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Webhook       string     `gorm:"type:varchar(64);index;uniqueIndex:idx_outbox_event,priority:1;not null" json:"webhook"` // webhook 名称
+	EventType     string     `gorm:"type:varchar(32);index;not null" json:"event_type"`                                      // 事件类型
+	EventKey      *string    `gorm:"type:varchar(128);uniqueIndex:idx_outbox_event,priority:2" json:"event_key"`             // 事件去重键(类型、epoch、槽位、验证者), 可重复的事件为空
+	Payload       string     `gorm:"type:text;not null" json:"payload"`                                                      // 事件 JSON
+	Status        string     `gorm:"type:varchar(16);index:idx_outbox_due,priority:1" json:"status"`                         // pending/delivered/failed
+	Attempts      int        `gorm:"default:0" json:"attempts"`                                                              // 已投递次数
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_due,priority:2" json:"next_attempt_at"`                                 // 下次投递时间
+	LastError     string     `gorm:"type:text" json:"last_error"`                                                            // 最近一次错误
+	DeliveredAt   *time.Time `json:"delivered_at"`                                                                           // 投递成功时间
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery webhook 投递记录
+type WebhookDelivery struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OutboxID   uint      `gorm:"index;not null" json:"outbox_id"` // 对应的 outbox 事件
+	Webhook    string    `gorm:"type:varchar(64)" json:"webhook"` // webhook 名称
+	Attempt    int       `json:"attempt"`                         // 第几次投递
+	StatusCode int       `json:"status_code"`                     // HTTP 状态码，请求失败时为 0
+	Error      string    `gorm:"type:text" json:"error"`          // 错误信息
+	DurationMs int64     `json:"duration_ms"`                     // 请求耗时
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -21,3 +21,11 @@ type DirectlyScanTask struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+// ScanCursor 扫描任务之外需要持久化的进度
+type ScanCursor struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"type:varchar(100);uniqueIndex;not null"` // 游标名称
+	Value     uint64 // 游标位置
+	UpdatedAt time.Time
+}
@@ -0,0 +1,37 @@
+package dbmodels
+
+import "time"
+
+// Validator 验证者注册表的最新快照, epoch 字段为空表示 FAR_FUTURE_EPOCH
+type Validator struct {
+	ID                         uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	ValidatorIndex             uint64    `gorm:"uniqueIndex;not null" json:"validator_index"`                   // 验证者索引
+	Pubkey                     string    `gorm:"type:varchar(98);uniqueIndex;not null" json:"pubkey"`           // 验证者公钥
+	WithdrawalCredentials      string    `gorm:"type:varchar(66);index;not null" json:"withdrawal_credentials"` // 提款凭证
+	EffectiveBalance           uint64    `gorm:"not null" json:"effective_balance"`                             // 有效余额(Gwei)
+	Slashed                    bool      `gorm:"default:false" json:"slashed"`                                  // 是否被罚没
+	ActivationEligibilityEpoch *uint64   `json:"activation_eligibility_epoch"`                                  // 具备激活资格的epoch
+	ActivationEpoch            *uint64   `json:"activation_epoch"`                                              // 激活epoch
+	ExitEpoch                  *uint64   `json:"exit_epoch"`                                                    // 退出epoch
+	WithdrawableEpoch          *uint64   `json:"withdrawable_epoch"`                                            // 可提款epoch
+	Status                     string    `gorm:"type:varchar(32);index" json:"status"`                          // 验证者状态
+	UpdatedEpoch               uint64    `gorm:"not null" json:"updated_epoch"`                                 // 最近一次发生变化的快照epoch
+	CreatedAt                  time.Time `json:"-"`
+	UpdatedAt                  time.Time `json:"-"`
+}
+
+// ValidatorHistory 验证者状态变更历史, 每次快照中发生变化时记录一行
+type ValidatorHistory struct {
+	ID                         uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	ValidatorIndex             uint64    `gorm:"index:idx_validator_history_epoch,priority:1;not null" json:"validator_index"`
+	Epoch                      uint64    `gorm:"index:idx_validator_history_epoch,priority:2;not null" json:"epoch"` // 快照epoch
+	WithdrawalCredentials      string    `gorm:"type:varchar(66);not null" json:"withdrawal_credentials"`
+	EffectiveBalance           uint64    `gorm:"not null" json:"effective_balance"`
+	Slashed                    bool      `gorm:"default:false" json:"slashed"`
+	ActivationEligibilityEpoch *uint64   `json:"activation_eligibility_epoch"`
+	ActivationEpoch            *uint64   `json:"activation_epoch"`
+	ExitEpoch                  *uint64   `json:"exit_epoch"`
+	WithdrawableEpoch          *uint64   `json:"withdrawable_epoch"`
+	Status                     string    `gorm:"type:varchar(32)" json:"status"`
+	CreatedAt                  time.Time `json:"-"`
+}
@@ -0,0 +1,13 @@
+package dbmodels
+
+import "time"
+
+// WatchedValidator 关注列表中的验证者
+type WatchedValidator struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ValidatorIndex uint64    `gorm:"uniqueIndex;not null" json:"validator_index"` // 验证者索引
+	Label          string    `gorm:"type:varchar(64)" json:"label"`               // 备注名称
+	Slashed        bool      `gorm:"default:false" json:"slashed"`                // 是否已通知过罚没
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
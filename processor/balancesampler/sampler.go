@@ -0,0 +1,201 @@
+package balancesampler
+
+import (
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	slotsPerEpoch = uint64(32)
+	// defaultSampleInterval is about one day of epochs.
+	defaultSampleInterval = uint64(225)
+)
+
+// BalanceSampler store the balance of every validator each configured number of epochs,
+// and roll the samples of each finished UTC day up into the daily table.
+type BalanceSampler struct {
+	config       *config.Config
+	db           *gorm.DB
+	rdb          *redis.Client
+	logger       *logrus.Logger
+	services     *services.Services
+	notifier     *notify.Dispatcher
+	quit         chan struct{}
+	beaconClient *beaconapi.BeaconClient
+	interval     uint64
+	genesis      time.Time
+	epochSeconds uint64
+	running      bool
+}
+
+func NewBalanceSampler(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *BalanceSampler {
+	interval := cfg.Indexer.BalanceSampleInterval
+	if interval == 0 {
+		interval = defaultSampleInterval
+	}
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &BalanceSampler{
+		config:       cfg,
+		db:           db,
+		rdb:          redis,
+		logger:       logger,
+		services:     svc,
+		quit:         make(chan struct{}),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
+		interval:     interval,
+	}
+}
+
+func (s *BalanceSampler) Start() error {
+	s.logger.Info("Starting balance sampler service")
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Balance sampler service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_VALIDATOR_BALANCE)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Balance sample task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 10)
+		}
+	}
+}
+
+func (s *BalanceSampler) Stop() {
+	close(s.quit)
+}
+
+// loadChainTime fetch genesis time and epoch duration used to map epochs to days.
+func (s *BalanceSampler) loadChainTime() error {
+	if s.epochSeconds != 0 {
+		return nil
+	}
+	genesis, err := s.beaconClient.GetGenesis()
+	if err != nil {
+		return err
+	}
+	secondsPerSlot, err := s.beaconClient.GetIntConfig(beaconapi.SECONDS_PER_SLOT)
+	if err != nil {
+		return err
+	}
+	if secondsPerSlot == 0 {
+		secondsPerSlot = 12
+	}
+	s.genesis = genesis.GenesisTime
+	s.epochSeconds = uint64(secondsPerSlot) * slotsPerEpoch
+	return nil
+}
+
+// dayOf return the UTC day the epoch starts in.
+func (s *BalanceSampler) dayOf(epoch uint64) time.Time {
+	start := s.genesis.Add(time.Duration(epoch*s.epochSeconds) * time.Second).UTC()
+	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// epochAt return the first epoch starting at or after t.
+func (s *BalanceSampler) epochAt(t time.Time) uint64 {
+	if !t.After(s.genesis) {
+		return 0
+	}
+	elapsed := uint64(t.Sub(s.genesis) / time.Second)
+	return (elapsed + s.epochSeconds - 1) / s.epochSeconds
+}
+
+// sampleBefore return the last sampled epoch at or before epoch, the balance a day start with
+// when the samples are sparser than the day.
+func (s *BalanceSampler) sampleBefore(epoch uint64) uint64 {
+	return epoch / s.interval * s.interval
+}
+
+func (s *BalanceSampler) nextEpoch(last uint64) uint64 {
+	return (last/s.interval + 1) * s.interval
+}
+
+func (s *BalanceSampler) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "balance-sampler")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	if err := s.loadChainTime(); err != nil {
+		logger.WithError(err).Error("Failed to load chain genesis")
+		return err
+	}
+	latest, err := s.beaconClient.GetLatestBeaconHeader()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest beacon header")
+		return err
+	}
+	headEpoch := uint64(latest.Header.Message.Slot) / slotsPerEpoch
+
+	for epoch := s.nextEpoch(task.LastNumber); epoch <= headEpoch; epoch = s.nextEpoch(epoch) {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		if err := s.sample(epoch); err != nil {
+			logger.WithField("epoch", epoch).WithError(err).Error("Failed to sample validator balances")
+			return err
+		}
+		if prev := task.LastNumber; prev > 0 && !s.dayOf(prev).Equal(s.dayOf(epoch)) {
+			day := s.dayOf(prev)
+			if err := s.services.Balance.RollupDay(day, s.sampleBefore(s.epochAt(day)), s.epochAt(day.AddDate(0, 0, 1))); err != nil {
+				logger.WithField("day", day).WithError(err).Error("Failed to roll up daily balances")
+				return err
+			}
+			logger.WithField("day", day.Format("2006-01-02")).Info("Rolled up daily balances")
+		}
+		task.LastNumber = epoch
+		s.services.ScanTask.UpdateScanTask(task)
+	}
+	return nil
+}
+
+func (s *BalanceSampler) sample(epoch uint64) error {
+	vals, err := s.beaconClient.GetValidators(fmt.Sprintf("%d", epoch*slotsPerEpoch))
+	if err != nil {
+		return err
+	}
+	balances := make([]*dbmodels.ValidatorBalance, 0, len(vals))
+	for index, val := range vals {
+		balances = append(balances, &dbmodels.ValidatorBalance{
+			Epoch:            epoch,
+			ValidatorIndex:   uint64(index),
+			Balance:          uint64(val.Balance),
+			EffectiveBalance: uint64(val.Validator.EffectiveBalance),
+		})
+	}
+	if err := s.services.Balance.SaveBalances(epoch, balances); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"epoch":      epoch,
+		"validators": len(balances),
+	}).Debug("Saved validator balances")
+	return nil
+}
@@ -18,6 +18,7 @@ func (s *BeaconBlockScanner) getPhase0Attestations(blk *phase0.SignedBeaconBlock
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -39,6 +40,7 @@ func (s *BeaconBlockScanner) getAltairAttestations(blk *altair.SignedBeaconBlock
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -60,6 +62,7 @@ func (s *BeaconBlockScanner) getBellatrixAttestations(blk *bellatrix.SignedBeaco
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -81,6 +84,7 @@ func (s *BeaconBlockScanner) getCapellaAttestations(blk *capella.SignedBeaconBlo
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -102,6 +106,7 @@ func (s *BeaconBlockScanner) getDenebAttestations(blk *deneb.SignedBeaconBlock)
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -123,6 +128,7 @@ func (s *BeaconBlockScanner) getElectraAttestations(blk *electra.SignedBeaconBlo
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -130,6 +136,7 @@ func (s *BeaconBlockScanner) getElectraAttestations(blk *electra.SignedBeaconBlo
 			SourceRoot:      att.Data.Source.Root.String(),
 			TargetEpoch:     uint64(att.Data.Target.Epoch),
 			TargetRoot:      att.Data.Target.Root.String(),
+			CommitteeBits:   hex.EncodeToString(att.CommitteeBits.Bytes()),
 			Signature:       att.Signature.String(),
 		}
 		res = append(res, dbAtt)
@@ -144,6 +151,7 @@ func (s *BeaconBlockScanner) getFuluAttestations(blk *electra.SignedBeaconBlock)
 		dbAtt := &dbmodels.BeaconAttestation{
 			SlotNumber:      uint64(slot),
 			AttestIndex:     i,
+			AttestationSlot: uint64(att.Data.Slot),
 			AggregationBits: hex.EncodeToString(att.AggregationBits.Bytes()),
 			BeaconBlockRoot: att.Data.BeaconBlockRoot.String(),
 			CommitteeIndex:  uint64(att.Data.Index),
@@ -151,6 +159,7 @@ func (s *BeaconBlockScanner) getFuluAttestations(blk *electra.SignedBeaconBlock)
 			SourceRoot:      att.Data.Source.Root.String(),
 			TargetEpoch:     uint64(att.Data.Target.Epoch),
 			TargetRoot:      att.Data.Target.Root.String(),
+			CommitteeBits:   hex.EncodeToString(att.CommitteeBits.Bytes()),
 			Signature:       att.Signature.String(),
 		}
 		res = append(res, dbAtt)
@@ -11,16 +11,21 @@ import (
 	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
 	"github.com/xueqianLu/deep-dive-beacon/config"
 	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
 	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
 	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
 	"gorm.io/gorm"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 )
 
 var (
 	big0 = big.NewInt(0)
+	// maxReorgDepth bound how far back the scanner look for the common ancestor.
+	maxReorgDepth = 64
 )
 
 type BeaconBlockScanner struct {
@@ -29,6 +34,8 @@ type BeaconBlockScanner struct {
 	rdb          *redis.Client
 	logger       *logrus.Logger
 	services     *services.Services
+	notifier     *notify.Dispatcher
+	publisher    *stream.Publisher
 	rwmux        sync.RWMutex
 	quit         chan struct{}
 	cache        *lru.Cache
@@ -50,6 +57,8 @@ func NewBeaconBlockScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client,
 		logger:       logger,
 		services:     svc,
 		quit:         make(chan struct{}),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		publisher:    stream.NewPublisher(redis, cfg.Stream),
 		running:      false,
 		cache:        cache,
 		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
@@ -71,13 +80,15 @@ func (s *BeaconBlockScanner) Start() error {
 
 		case <-ticker.C:
 
-			task, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
 			if task == nil || err != nil {
 				s.logger.WithError(err).Info("Beacon block scan task is not enabled, skipping...")
 				continue
 			}
 			if !s.running {
-				go s.doScanTask(task)
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
 			}
 			ticker.Reset(time.Second * 10)
 		}
@@ -169,12 +180,22 @@ func (s *BeaconBlockScanner) doScanTask(task *dbmodels.ScanTask) error {
 				}
 				continue
 			}
+			ancestor, reorged, err := s.checkReorg(ctx, task, block, height)
+			if err != nil {
+				logger.WithField("height", height).WithError(err).Error("Failed to check reorg")
+				return err
+			}
+			if reorged {
+				height = ancestor + 1
+				continue
+			}
 			tx := s.db.Begin()
 			if tx.Error != nil {
 				logger.WithError(tx.Error).Error("Failed to begin db transaction")
 				return tx.Error
 			}
-			if err = s.processBeaconBlock(tx, block); err != nil {
+			dbblk, atts, err := s.processBeaconBlock(tx, block)
+			if err != nil {
 				tx.Rollback()
 				logger.WithError(err).Error("processor beacon block failed")
 				return err
@@ -184,6 +205,7 @@ func (s *BeaconBlockScanner) doScanTask(task *dbmodels.ScanTask) error {
 					return err
 				}
 			}
+			s.publish(ctx, dbblk, atts)
 			// update task last processed height
 			task.LastNumber = height
 			s.services.ScanTask.UpdateScanTask(task)
@@ -195,17 +217,127 @@ func (s *BeaconBlockScanner) doScanTask(task *dbmodels.ScanTask) error {
 	}
 }
 
-func (s *BeaconBlockScanner) processBeaconBlock(db *gorm.DB, blk *spec.VersionedSignedBeaconBlock) error {
+// checkReorg compare the parent root of block with the indexed block before height. When the
+// indexed blocks after their common ancestor are orphaned, they are unwound, the reorg is
+// notified and published, and it return true with the slot of the ancestor to continue from.
+func (s *BeaconBlockScanner) checkReorg(ctx context.Context, task *dbmodels.ScanTask, block *spec.VersionedSignedBeaconBlock, height uint64) (uint64, bool, error) {
+	parentRoot, err := block.ParentRoot()
+	if err != nil {
+		return 0, false, err
+	}
+	prev, err := s.services.BeaconBlock.GetBlocksBefore(height, 1)
+	// blocks indexed before the root was recorded have nothing to compare, the finality
+	// scanner backfill their root.
+	if err != nil || len(prev) == 0 || prev[0].BlockRoot == "" || strings.EqualFold(prev[0].BlockRoot, parentRoot.String()) {
+		return 0, false, err
+	}
+	// the parent may also be a block the scanner skipped, nothing is orphaned then.
+	ancestor, depth, err := s.services.BeaconBlock.FindCommonAncestor(height, maxReorgDepth, s.beaconClient.GetCanonicalRoot)
+	if err != nil || depth == 0 {
+		return 0, false, err
+	}
+	// the event is dispatched before unwinding, the reorg is not detected again after. The
+	// orphaned head tell apart reorgs back to the same ancestor.
+	err = s.notifier.Dispatch(&notify.Event{
+		Type:    constant.EVENT_REORG,
+		Epoch:   ancestor / slotsPerEpoch,
+		Slot:    &ancestor,
+		Root:    prev[0].BlockRoot,
+		Message: fmt.Sprintf("consensus chain reorg after slot %d, depth %d", ancestor, depth),
+		Data: map[string]interface{}{
+			"layer":    "consensus",
+			"ancestor": ancestor,
+			"depth":    depth,
+		},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if err := s.services.BeaconBlock.DeleteBlocksBetween(ancestor, height); err != nil {
+		return 0, false, err
+	}
+	task.LastNumber = ancestor
+	s.services.ScanTask.UpdateScanTask(task)
+	s.logger.WithFields(logrus.Fields{
+		"ancestor": ancestor,
+		"depth":    depth,
+	}).Warn("Consensus chain reorg detected, unwound indexed blocks")
+	reorg := &stream.Reorg{Layer: "consensus", Ancestor: ancestor, Depth: uint64(depth)}
+	if err := s.publisher.PublishReorg(ctx, reorg); err != nil {
+		s.logger.WithError(err).Warn("Failed to publish reorg")
+	}
+	return ancestor, true, nil
+}
+
+// publish send the committed block and its attestations to the streams, a failure is logged
+// only as the block is already indexed.
+func (s *BeaconBlockScanner) publish(ctx context.Context, block *dbmodels.BeaconBlock, atts []*dbmodels.BeaconAttestation) {
+	if err := s.publisher.PublishBlock(ctx, block); err != nil {
+		s.logger.WithField("slot", block.SlotNumber).WithError(err).Warn("Failed to publish block")
+	}
+	if err := s.publisher.PublishAttestations(ctx, block.SlotNumber, atts); err != nil {
+		s.logger.WithField("slot", block.SlotNumber).WithError(err).Warn("Failed to publish attestations")
+	}
+}
+
+// notifySlashings dispatch the slashings of the block through notifier.
+func notifySlashings(notifier *notify.Dispatcher, slot uint64, slashed []uint64) error {
+	for _, index := range slashed {
+		err := notifier.Dispatch(&notify.Event{
+			Type:           constant.EVENT_SLASHED,
+			Epoch:          slot / slotsPerEpoch,
+			Slot:           &slot,
+			ValidatorIndex: &index,
+			Message:        fmt.Sprintf("validator %d slashed in block %d", index, slot),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processBeaconBlock save the block and its content, and return the saved block and attestations.
+func (s *BeaconBlockScanner) processBeaconBlock(db *gorm.DB, blk *spec.VersionedSignedBeaconBlock) (*dbmodels.BeaconBlock, []*dbmodels.BeaconAttestation, error) {
 	dbblk, err := s.ToDBBlock(blk)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	fillExecutionPayload(dbblk, blk)
+	fillSyncAggregate(dbblk, blk)
 	db.Model(&dbmodels.BeaconBlock{}).Save(dbblk)
 	atts := s.GetBlkAtts(blk)
 	for _, att := range atts {
 		db.Model(&dbmodels.BeaconAttestation{}).Save(att)
 	}
-	return nil
+	for _, deposit := range s.GetBlkDeposits(blk) {
+		db.Model(&dbmodels.BeaconBlockDeposit{}).Save(deposit)
+	}
+	if dbblk.ProposerSlashed+dbblk.AttesterSlashed > 0 {
+		// the events are queued in the transaction of the block, so they are neither lost nor
+		// queued for a block rolled back.
+		notifier := s.notifier.WithOutbox(s.services.Notify.WithTx(db))
+		if err := notifySlashings(notifier, dbblk.SlotNumber, s.GetSlashedValidators(blk)); err != nil {
+			return nil, nil, err
+		}
+	}
+	reqs := s.GetBlkExecutionRequests(blk)
+	if reqs.empty() {
+		return dbblk, atts, nil
+	}
+	if err := s.fillRequestValidatorIndex(reqs); err != nil {
+		s.logger.WithField("slot", dbblk.SlotNumber).WithError(err).Warn("resolve execution request validator index failed")
+	}
+	for _, req := range reqs.deposits {
+		db.Model(&dbmodels.BeaconDepositRequest{}).Save(req)
+	}
+	for _, req := range reqs.withdrawals {
+		db.Model(&dbmodels.BeaconWithdrawalRequest{}).Save(req)
+	}
+	for _, req := range reqs.consolidations {
+		db.Model(&dbmodels.BeaconConsolidationRequest{}).Save(req)
+	}
+	return dbblk, atts, nil
 }
 
 var (
@@ -0,0 +1,78 @@
+package depositscanner
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"math/big"
+	"strings"
+)
+
+// depositEventTopic is keccak256("DepositEvent(bytes,bytes,bytes,bytes,bytes)").
+const depositEventTopic = "0x649bbc62d0e31342afea4e5cd82d4049e7e1ee912fc0889aa790803be39038c5"
+
+var errInvalidDepositLog = errors.New("invalid deposit event data")
+
+type depositEvent struct {
+	Pubkey                phase0.BLSPubKey
+	WithdrawalCredentials []byte
+	Amount                phase0.Gwei
+	Signature             phase0.BLSSignature
+	Index                 uint64
+}
+
+// decodeDepositEvent decode the ABI encoded DepositEvent data, made of five dynamic byte
+// arrays, amount and index being little endian uint64.
+func decodeDepositEvent(data string) (*depositEvent, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	fields := make([][]byte, 5)
+	for i := range fields {
+		if fields[i], err = abiBytes(raw, i); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields[0]) != 48 || len(fields[1]) != 32 || len(fields[2]) != 8 || len(fields[3]) != 96 || len(fields[4]) != 8 {
+		return nil, errInvalidDepositLog
+	}
+	ev := &depositEvent{
+		WithdrawalCredentials: fields[1],
+		Amount:                phase0.Gwei(binary.LittleEndian.Uint64(fields[2])),
+		Index:                 binary.LittleEndian.Uint64(fields[4]),
+	}
+	copy(ev.Pubkey[:], fields[0])
+	copy(ev.Signature[:], fields[3])
+	return ev, nil
+}
+
+// abiBytes return the i-th dynamic bytes argument of the encoded data.
+func abiBytes(raw []byte, i int) ([]byte, error) {
+	offset, err := abiWord(raw, uint64(i*32))
+	if err != nil {
+		return nil, err
+	}
+	length, err := abiWord(raw, offset)
+	if err != nil {
+		return nil, err
+	}
+	start := offset + 32
+	if start+length > uint64(len(raw)) {
+		return nil, errInvalidDepositLog
+	}
+	return raw[start : start+length], nil
+}
+
+func abiWord(raw []byte, pos uint64) (uint64, error) {
+	if pos+32 > uint64(len(raw)) {
+		return 0, errInvalidDepositLog
+	}
+	word := new(big.Int).SetBytes(raw[pos : pos+32])
+	if !word.IsUint64() || word.Uint64() > uint64(len(raw)) {
+		return 0, fmt.Errorf("%w: word %s out of range", errInvalidDepositLog, word)
+	}
+	return word.Uint64(), nil
+}
@@ -0,0 +1,59 @@
+package depositscanner
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeDepositEvent build the log data the way the deposit contract emits it.
+func encodeDepositEvent(fields ...[]byte) string {
+	word := func(v uint64) []byte {
+		w := make([]byte, 32)
+		binary.BigEndian.PutUint64(w[24:], v)
+		return w
+	}
+	head := make([]byte, 0)
+	tail := make([]byte, 0)
+	for _, field := range fields {
+		head = append(head, word(uint64(len(fields)*32+len(tail)))...)
+		padded := make([]byte, (len(field)+31)/32*32)
+		copy(padded, field)
+		tail = append(tail, word(uint64(len(field)))...)
+		tail = append(tail, padded...)
+	}
+	return "0x" + hex.EncodeToString(append(head, tail...))
+}
+
+func TestDecodeDepositEvent(t *testing.T) {
+	pubkey := make([]byte, 48)
+	pubkey[0] = 0xaa
+	credentials := make([]byte, 32)
+	credentials[0] = 0x01
+	amount := make([]byte, 8)
+	binary.LittleEndian.PutUint64(amount, 32000000000)
+	signature := make([]byte, 96)
+	signature[95] = 0xbb
+	index := make([]byte, 8)
+	binary.LittleEndian.PutUint64(index, 1234)
+
+	ev, err := decodeDepositEvent(encodeDepositEvent(pubkey, credentials, amount, signature, index))
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xaa), ev.Pubkey[0])
+	assert.Equal(t, credentials, ev.WithdrawalCredentials)
+	assert.Equal(t, uint64(32000000000), uint64(ev.Amount))
+	assert.Equal(t, byte(0xbb), ev.Signature[95])
+	assert.Equal(t, uint64(1234), ev.Index)
+}
+
+func TestDecodeDepositEventInvalid(t *testing.T) {
+	_, err := decodeDepositEvent("0x1234")
+	assert.ErrorIs(t, err, errInvalidDepositLog)
+
+	short := encodeDepositEvent(make([]byte, 47), make([]byte, 32), make([]byte, 8), make([]byte, 96), make([]byte, 8))
+	_, err = decodeDepositEvent(short)
+	assert.ErrorIs(t, err, errInvalidDepositLog)
+}
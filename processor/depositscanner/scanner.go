@@ -0,0 +1,293 @@
+package depositscanner
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	execapi "github.com/xueqianLu/deep-dive-beacon/execution"
+	"github.com/xueqianLu/deep-dive-beacon/internal/deposittree"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	// followDistance keep the scanner away from the head so deposit logs are not reorged.
+	followDistance = uint64(64)
+	// logRange is the number of blocks queried by one eth_getLogs call.
+	logRange = uint64(1000)
+	// linkBatch is the number of block deposits matched per page.
+	linkBatch = 1000
+	// verifiedCursor persist the deposit count verified, so a restart does not alert again.
+	verifiedCursor = "deposit:verified_count"
+)
+
+// DepositScanner index the deposit contract DepositEvent logs, rebuild the deposit tree to
+// verify the eth1 data votes, and link every deposit to its beacon block and validator.
+// The task must start before the first deposit, since the tree is rebuilt from all deposits.
+type DepositScanner struct {
+	config        *config.Config
+	db            *gorm.DB
+	rdb           *redis.Client
+	logger        *logrus.Logger
+	services      *services.Services
+	notifier      *notify.Dispatcher
+	quit          chan struct{}
+	execClient    *execapi.ExecutionClient
+	running       bool
+	tree          *deposittree.Tree
+	verifiedCount uint64
+}
+
+func NewDepositScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *DepositScanner {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &DepositScanner{
+		config:     cfg,
+		db:         db,
+		rdb:        redis,
+		logger:     logger,
+		services:   svc,
+		quit:       make(chan struct{}),
+		notifier:   notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		execClient: execapi.NewExecutionClient(cfg.Chain.GethUrl),
+	}
+}
+
+func (s *DepositScanner) Start() error {
+	s.logger.Info("Starting deposit scanner service")
+
+	ticker := time.NewTicker(12 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Deposit scanner service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_DEPOSIT)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Deposit scan task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 12)
+		}
+	}
+}
+
+func (s *DepositScanner) Stop() {
+	close(s.quit)
+}
+
+func (s *DepositScanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "deposit-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	if s.tree == nil {
+		tree, err := s.loadTree()
+		if err != nil {
+			logger.WithError(err).Error("Failed to rebuild deposit tree")
+			return err
+		}
+		s.tree = tree
+		logger.WithField("deposits", tree.Count()).Info("Rebuilt deposit tree")
+		verified, _, err := s.services.ScanTask.GetCursor(verifiedCursor)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get verified deposit count")
+			return err
+		}
+		s.verifiedCount = verified
+	}
+
+	latest, err := s.execClient.BlockNumber()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest block number")
+		return err
+	}
+	if latest > followDistance {
+		for from := task.LastNumber + 1; from+followDistance <= latest; from = task.LastNumber + 1 {
+			select {
+			case <-s.quit:
+				return nil
+			default:
+			}
+			to := from + logRange - 1
+			if to+followDistance > latest {
+				to = latest - followDistance
+			}
+			if err := s.scanRange(from, to); err != nil {
+				// the tree may be ahead of the database now, rebuild it on next round.
+				s.tree = nil
+				logger.WithFields(logrus.Fields{"from": from, "to": to}).WithError(err).Error("Failed to index deposit logs")
+				return err
+			}
+			task.LastNumber = to
+			s.services.ScanTask.UpdateScanTask(task)
+		}
+	}
+
+	s.link(logger)
+	if err := s.verifyRoots(); err != nil {
+		logger.WithError(err).Error("Failed to verify deposit roots")
+		return err
+	}
+	return nil
+}
+
+// loadTree rebuild the deposit tree from the indexed deposits.
+func (s *DepositScanner) loadTree() (*deposittree.Tree, error) {
+	tree := deposittree.New()
+	err := s.services.Deposit.EachDepositDataRoot(func(index uint64, root string) error {
+		if index != tree.Count() {
+			return fmt.Errorf("deposit index gap, expect %d got %d", tree.Count(), index)
+		}
+		leaf, err := parseRoot(root)
+		if err != nil {
+			return err
+		}
+		tree.Insert(leaf)
+		return nil
+	})
+	return tree, err
+}
+
+func (s *DepositScanner) scanRange(from, to uint64) error {
+	logs, err := s.execClient.GetLogs(execapi.LogFilter{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: []string{s.config.Chain.DepositContract},
+		Topics:    [][]string{{depositEventTopic}},
+	})
+	if err != nil {
+		return err
+	}
+	deposits := make([]*dbmodels.Deposit, 0, len(logs))
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		ev, err := decodeDepositEvent(log.Data)
+		if err != nil {
+			return err
+		}
+		if ev.Index != s.tree.Count() {
+			return fmt.Errorf("deposit index gap, expect %d got %d", s.tree.Count(), ev.Index)
+		}
+		leaf, err := deposittree.DataRoot(ev.Pubkey, ev.WithdrawalCredentials, ev.Amount, ev.Signature)
+		if err != nil {
+			return err
+		}
+		s.tree.Insert(leaf)
+		root := s.tree.Root()
+		deposits = append(deposits, &dbmodels.Deposit{
+			DepositIndex:          ev.Index,
+			Pubkey:                ev.Pubkey.String(),
+			WithdrawalCredentials: fmt.Sprintf("%#x", ev.WithdrawalCredentials),
+			Amount:                uint64(ev.Amount),
+			Signature:             ev.Signature.String(),
+			DepositDataRoot:       fmt.Sprintf("%#x", leaf),
+			DepositRoot:           fmt.Sprintf("%#x", root),
+			BlockNumber:           log.BlockNumber.Uint64(),
+			BlockHash:             log.BlockHash,
+			TransactionHash:       log.TransactionHash,
+			LogIndex:              uint(log.LogIndex),
+		})
+	}
+	if err := s.services.Deposit.SaveDeposits(deposits); err != nil {
+		return err
+	}
+	if len(deposits) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"from":     from,
+			"to":       to,
+			"deposits": len(deposits),
+			"count":    s.tree.Count(),
+		}).Info("Indexed deposit logs")
+	}
+	return nil
+}
+
+// link connect the deposits to the beacon block that processed them and to their validator,
+// failures are retried next round.
+func (s *DepositScanner) link(logger *logrus.Entry) {
+	if _, err := s.services.Deposit.LinkDepositRequests(); err != nil {
+		logger.WithError(err).Warn("Failed to link deposit requests")
+	}
+	var cursor *services.DepositCursor
+	for {
+		_, next, err := s.services.Deposit.LinkBlockDeposits(cursor, linkBatch)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to link block deposits")
+			break
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+	if _, err := s.services.Deposit.LinkValidators(); err != nil {
+		logger.WithError(err).Warn("Failed to link deposit validators")
+	}
+}
+
+// verifyRoots compare the eth1 data votes of indexed beacon blocks with the recomputed deposit root.
+func (s *DepositScanner) verifyRoots() error {
+	votes, err := s.services.Deposit.GetDepositRootVotes(s.verifiedCount, s.tree.Count())
+	if err != nil {
+		return err
+	}
+	for _, vote := range votes {
+		deposit, err := s.services.Deposit.GetDepositByIndex(vote.Eth1DepositCount - 1)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(deposit.DepositRoot, vote.Eth1DepositRoot) {
+			alert := &dbmodels.ChainAlert{
+				AlertType: constant.ALERT_DEPOSIT_ROOT,
+				Epoch:     vote.SlotNumber / 32,
+				Message: fmt.Sprintf("deposit root mismatch at slot %d: count %d voted root %s, computed %s",
+					vote.SlotNumber, vote.Eth1DepositCount, vote.Eth1DepositRoot, deposit.DepositRoot),
+			}
+			s.logger.WithFields(logrus.Fields{
+				"slot":  vote.SlotNumber,
+				"count": vote.Eth1DepositCount,
+			}).Error(alert.Message)
+			if err := s.services.Finality.SaveAlert(alert); err != nil {
+				return err
+			}
+		}
+		if err := s.services.ScanTask.SetCursor(verifiedCursor, vote.Eth1DepositCount); err != nil {
+			return err
+		}
+		s.verifiedCount = vote.Eth1DepositCount
+	}
+	return nil
+}
+
+func parseRoot(root string) ([32]byte, error) {
+	var res [32]byte
+	data, err := hex.DecodeString(strings.TrimPrefix(root, "0x"))
+	if err != nil {
+		return res, err
+	}
+	if len(data) != len(res) {
+		return res, fmt.Errorf("invalid root length %d", len(data))
+	}
+	copy(res[:], data)
+	return res, nil
+}
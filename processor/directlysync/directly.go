@@ -1,6 +1,7 @@
 package directlysync
 
 import (
+	"context"
 	"fmt"
 	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
@@ -10,16 +11,21 @@ import (
 	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
 	"github.com/xueqianLu/deep-dive-beacon/config"
 	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
 	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
 	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
 	"gorm.io/gorm"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 )
 
 var (
 	big0 = big.NewInt(0)
+	// maxReorgDepth bound how far back the scanner look for the common ancestor.
+	maxReorgDepth = 64
 )
 
 type DirectlyBlockScanner struct {
@@ -28,6 +34,8 @@ type DirectlyBlockScanner struct {
 	rdb          *redis.Client
 	logger       *logrus.Logger
 	services     *services.Services
+	notifier     *notify.Dispatcher
+	publisher    *stream.Publisher
 	rwmux        sync.RWMutex
 	quit         chan struct{}
 	cache        *lru.Cache
@@ -48,6 +56,8 @@ func NewDirectlyBlockScanner(cfg *config.Config, db *gorm.DB, redis *redis.Clien
 		rdb:          redis,
 		logger:       logger,
 		services:     svc,
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		publisher:    stream.NewPublisher(redis, cfg.Stream),
 		quit:         make(chan struct{}),
 		running:      make(map[uint]bool),
 		cache:        cache,
@@ -160,6 +170,15 @@ func (s *DirectlyBlockScanner) doScanTask(task *dbmodels.DirectlyScanTask) error
 
 	var running bool = true
 	for running {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		if height > task.End {
+			logger.Info("Scan task completed")
+			return nil
+		}
 		if latest == nil {
 			refreshLatest()
 			continue
@@ -183,12 +202,22 @@ func (s *DirectlyBlockScanner) doScanTask(task *dbmodels.DirectlyScanTask) error
 			}
 			continue
 		}
+		ancestor, reorged, err := s.checkReorg(task, block, height)
+		if err != nil {
+			logger.WithField("height", height).WithError(err).Error("Failed to check reorg")
+			return err
+		}
+		if reorged {
+			height = ancestor + 1
+			continue
+		}
 		tx := s.db.Begin()
 		if tx.Error != nil {
 			logger.WithError(tx.Error).Error("Failed to begin db transaction")
 			return tx.Error
 		}
-		if err = s.processBeaconBlock(tx, block); err != nil {
+		dbblk, atts, err := s.processBeaconBlock(tx, block)
+		if err != nil {
 			tx.Rollback()
 			logger.WithError(err).Error("processor beacon block failed")
 			return err
@@ -198,6 +227,7 @@ func (s *DirectlyBlockScanner) doScanTask(task *dbmodels.DirectlyScanTask) error
 				return err
 			}
 		}
+		s.publish(context.Background(), dbblk, atts)
 		// update task last processed height
 		task.LastNumber = height
 		s.services.DirectlyScan.UpdateScanTask(task)
@@ -206,23 +236,159 @@ func (s *DirectlyBlockScanner) doScanTask(task *dbmodels.DirectlyScanTask) error
 				"remain": task.End - task.LastNumber,
 				"height": height,
 			}).Info("Processed beacon blocks")
+			// stop if the task was paused or cancelled meanwhile.
+			if current, err := s.services.DirectlyScan.GetScanTaskByID(task.ID); err != nil || !current.Enabled {
+				logger.Info("Scan task is paused or cancelled, stop")
+				return nil
+			}
 		}
 		height++
 	}
 	return nil
 }
 
-func (s *DirectlyBlockScanner) processBeaconBlock(db *gorm.DB, blk *spec.VersionedSignedBeaconBlock) error {
+// checkReorg compare the parent root of block with the indexed block before height. When the
+// indexed blocks after their common ancestor are orphaned, they are unwound, the reorg is
+// notified and published, and it return true with the slot of the ancestor to continue from,
+// or the slot before the task start when the ancestor is below it.
+func (s *DirectlyBlockScanner) checkReorg(task *dbmodels.DirectlyScanTask, block *spec.VersionedSignedBeaconBlock, height uint64) (uint64, bool, error) {
+	parentRoot, err := block.ParentRoot()
+	if err != nil {
+		return 0, false, err
+	}
+	prev, err := s.services.BeaconBlock.GetBlocksBefore(height, 1)
+	// blocks indexed before the root was recorded have nothing to compare, the finality
+	// scanner backfill their root.
+	if err != nil || len(prev) == 0 || prev[0].BlockRoot == "" || strings.EqualFold(prev[0].BlockRoot, parentRoot.String()) {
+		return 0, false, err
+	}
+	// the parent may also be a block not indexed yet, nothing is orphaned then.
+	ancestor, depth, err := s.services.BeaconBlock.FindCommonAncestor(height, maxReorgDepth, s.beaconClient.GetCanonicalRoot)
+	if err != nil || depth == 0 {
+		return 0, false, err
+	}
+	// the event is dispatched before unwinding, the reorg is not detected again after. The
+	// orphaned head tell apart reorgs back to the same ancestor.
+	err = s.notifier.Dispatch(&notify.Event{
+		Type:    constant.EVENT_REORG,
+		Epoch:   ancestor / slotsPerEpoch,
+		Slot:    &ancestor,
+		Root:    prev[0].BlockRoot,
+		Message: fmt.Sprintf("consensus chain reorg after slot %d, depth %d", ancestor, depth),
+		Data: map[string]interface{}{
+			"layer":    "consensus",
+			"ancestor": ancestor,
+			"depth":    depth,
+		},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if err := s.services.BeaconBlock.DeleteBlocksBetween(ancestor, height); err != nil {
+		return 0, false, err
+	}
+	// the task does not scan below its start, the unwound blocks there go to a task of their own.
+	if ancestor+1 < task.Start {
+		gap := &dbmodels.DirectlyScanTask{
+			TaskType:   task.TaskType,
+			Start:      ancestor + 1,
+			End:        task.Start - 1,
+			LastNumber: ancestor,
+			Enabled:    true,
+		}
+		if err := s.services.DirectlyScan.CreateScanTask(gap); err != nil {
+			return 0, false, err
+		}
+		s.logger.WithFields(logrus.Fields{
+			"task":  gap.ID,
+			"start": gap.Start,
+			"end":   gap.End,
+		}).Info("Created scan task for the unwound blocks below the task start")
+		ancestor = task.Start - 1
+	}
+	task.LastNumber = ancestor
+	s.services.DirectlyScan.UpdateScanTask(task)
+	s.logger.WithFields(logrus.Fields{
+		"task":     task.ID,
+		"ancestor": ancestor,
+		"depth":    depth,
+	}).Warn("Consensus chain reorg detected, unwound indexed blocks")
+	reorg := &stream.Reorg{Layer: "consensus", Ancestor: ancestor, Depth: uint64(depth)}
+	if err := s.publisher.PublishReorg(context.Background(), reorg); err != nil {
+		s.logger.WithError(err).Warn("Failed to publish reorg")
+	}
+	return ancestor, true, nil
+}
+
+// publish send the committed block and its attestations to the streams, a failure is logged
+// only as the block is already indexed.
+func (s *DirectlyBlockScanner) publish(ctx context.Context, block *dbmodels.BeaconBlock, atts []*dbmodels.BeaconAttestation) {
+	if err := s.publisher.PublishBlock(ctx, block); err != nil {
+		s.logger.WithField("slot", block.SlotNumber).WithError(err).Warn("Failed to publish block")
+	}
+	if err := s.publisher.PublishAttestations(ctx, block.SlotNumber, atts); err != nil {
+		s.logger.WithField("slot", block.SlotNumber).WithError(err).Warn("Failed to publish attestations")
+	}
+}
+
+// notifySlashings dispatch the slashings of the block through notifier.
+func notifySlashings(notifier *notify.Dispatcher, slot uint64, slashed []uint64) error {
+	for _, index := range slashed {
+		err := notifier.Dispatch(&notify.Event{
+			Type:           constant.EVENT_SLASHED,
+			Epoch:          slot / slotsPerEpoch,
+			Slot:           &slot,
+			ValidatorIndex: &index,
+			Message:        fmt.Sprintf("validator %d slashed in block %d", index, slot),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processBeaconBlock save the block and its content, and return the saved block and attestations.
+func (s *DirectlyBlockScanner) processBeaconBlock(db *gorm.DB, blk *spec.VersionedSignedBeaconBlock) (*dbmodels.BeaconBlock, []*dbmodels.BeaconAttestation, error) {
 	dbblk, err := s.ToDBBlock(blk)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	fillExecutionPayload(dbblk, blk)
+	fillSyncAggregate(dbblk, blk)
 	db.Model(&dbmodels.BeaconBlock{}).Save(dbblk)
 	atts := s.GetBlkAtts(blk)
 	for _, att := range atts {
 		db.Model(&dbmodels.BeaconAttestation{}).Save(att)
 	}
-	return nil
+	for _, deposit := range s.GetBlkDeposits(blk) {
+		db.Model(&dbmodels.BeaconBlockDeposit{}).Save(deposit)
+	}
+	if dbblk.ProposerSlashed+dbblk.AttesterSlashed > 0 {
+		// the events are queued in the transaction of the block, so they are neither lost nor
+		// queued for a block rolled back.
+		notifier := s.notifier.WithOutbox(s.services.Notify.WithTx(db))
+		if err := notifySlashings(notifier, dbblk.SlotNumber, s.GetSlashedValidators(blk)); err != nil {
+			return nil, nil, err
+		}
+	}
+	reqs := s.GetBlkExecutionRequests(blk)
+	if reqs.empty() {
+		return dbblk, atts, nil
+	}
+	if err := s.fillRequestValidatorIndex(reqs); err != nil {
+		s.logger.WithField("slot", dbblk.SlotNumber).WithError(err).Warn("resolve execution request validator index failed")
+	}
+	for _, req := range reqs.deposits {
+		db.Model(&dbmodels.BeaconDepositRequest{}).Save(req)
+	}
+	for _, req := range reqs.withdrawals {
+		db.Model(&dbmodels.BeaconWithdrawalRequest{}).Save(req)
+	}
+	for _, req := range reqs.consolidations {
+		db.Model(&dbmodels.BeaconConsolidationRequest{}).Save(req)
+	}
+	return dbblk, atts, nil
 }
 
 var (
@@ -2,15 +2,66 @@ package directlysync
 
 import (
 	"encoding/hex"
+	"fmt"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/altair"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/xueqianLu/deep-dive-beacon/internal/graffiti"
 	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"strings"
 )
 
+func fillGraffiti(dbinfo *dbmodels.BeaconBlock, raw [32]byte) {
+	dbinfo.GraffitiText = graffiti.Decode(raw)
+	info := graffiti.Classify(dbinfo.GraffitiText)
+	dbinfo.ConsensusClient = info.ConsensusClient
+	dbinfo.ConsensusVersion = info.ConsensusVersion
+	dbinfo.ExecutionClient = info.ExecutionClient
+	dbinfo.ExecutionVersion = info.ExecutionVersion
+}
+
+// fillExecutionPayload set the block and body roots and the execution payload reference, blocks
+// before the merge carry no payload or a zero payload hash and keep them empty.
+func fillExecutionPayload(dbinfo *dbmodels.BeaconBlock, blk *spec.VersionedSignedBeaconBlock) {
+	if root, err := blk.Root(); err == nil {
+		dbinfo.BlockRoot = root.String()
+	}
+	if root, err := blk.BodyRoot(); err == nil {
+		dbinfo.BodyRoot = root.String()
+	}
+	hash, err := blk.ExecutionBlockHash()
+	if err != nil || hash == (phase0.Hash32{}) {
+		return
+	}
+	dbinfo.ExecutionBlockHash = hash.String()
+	if number, err := blk.ExecutionBlockNumber(); err == nil {
+		dbinfo.ExecutionBlockNumber = &number
+	}
+	payload, err := blk.ExecutionPayload()
+	if err != nil {
+		return
+	}
+	if recipient, err := payload.FeeRecipient(); err == nil {
+		dbinfo.FeeRecipient = strings.ToLower(recipient.String())
+	}
+	if extra, err := payload.ExtraData(); err == nil {
+		dbinfo.ExtraData = fmt.Sprintf("%#x", extra)
+	}
+}
+
+// fillSyncAggregate set the sync committee bits of the block, blocks before altair have none.
+func fillSyncAggregate(dbinfo *dbmodels.BeaconBlock, blk *spec.VersionedSignedBeaconBlock) {
+	aggregate, err := blk.SyncAggregate()
+	if err != nil || aggregate == nil {
+		return
+	}
+	dbinfo.SyncAggregateBits = fmt.Sprintf("%#x", []byte(aggregate.SyncCommitteeBits))
+}
+
 func (s *DirectlyBlockScanner) phase0ToDBBlock(blk *phase0.SignedBeaconBlock, dbinfo *dbmodels.BeaconBlock) (*dbmodels.BeaconBlock, error) {
 	dbinfo.Signature = blk.Signature.String()
 	dbinfo.StateRoot = blk.Message.StateRoot.String()
@@ -20,6 +71,7 @@ func (s *DirectlyBlockScanner) phase0ToDBBlock(blk *phase0.SignedBeaconBlock, db
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
@@ -36,6 +88,7 @@ func (s *DirectlyBlockScanner) altairToDBBlock(blk *altair.SignedBeaconBlock, db
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
@@ -51,6 +104,7 @@ func (s *DirectlyBlockScanner) bellatrixToDBBlock(blk *bellatrix.SignedBeaconBlo
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
@@ -66,6 +120,7 @@ func (s *DirectlyBlockScanner) capellaToDBBlock(blk *capella.SignedBeaconBlock,
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
@@ -81,6 +136,7 @@ func (s *DirectlyBlockScanner) denebToDBBlock(blk *deneb.SignedBeaconBlock, dbin
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
@@ -96,9 +152,15 @@ func (s *DirectlyBlockScanner) electraToDBBlock(blk *electra.SignedBeaconBlock,
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
+	if requests := blk.Message.Body.ExecutionRequests; requests != nil {
+		dbinfo.DepositRequests = uint(len(requests.Deposits))
+		dbinfo.WithdrawalRequests = uint(len(requests.Withdrawals))
+		dbinfo.ConsolidationRequests = uint(len(requests.Consolidations))
+	}
 	dbinfo.Signature = blk.Signature.String()
 	return dbinfo, nil
 }
@@ -111,9 +173,15 @@ func (s *DirectlyBlockScanner) fuluToDBBlock(blk *electra.SignedBeaconBlock, dbi
 	dbinfo.Eth1DepositCount = uint64(blk.Message.Body.ETH1Data.DepositCount)
 	dbinfo.Eth1DepositRoot = blk.Message.Body.ETH1Data.DepositRoot.String()
 	dbinfo.Graffiti = hex.EncodeToString(blk.Message.Body.Graffiti[:])
+	fillGraffiti(dbinfo, blk.Message.Body.Graffiti)
 	dbinfo.RandaoReveal = blk.Message.Body.RANDAOReveal.String()
 	dbinfo.ProposerSlashed = uint(len(blk.Message.Body.ProposerSlashings))
 	dbinfo.AttesterSlashed = uint(len(blk.Message.Body.AttesterSlashings))
+	if requests := blk.Message.Body.ExecutionRequests; requests != nil {
+		dbinfo.DepositRequests = uint(len(requests.Deposits))
+		dbinfo.WithdrawalRequests = uint(len(requests.Withdrawals))
+		dbinfo.ConsolidationRequests = uint(len(requests.Consolidations))
+	}
 	dbinfo.Signature = blk.Signature.String()
 	return dbinfo, nil
 }
@@ -0,0 +1,31 @@
+package directlysync
+
+import (
+	"fmt"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+// GetBlkDeposits return the deposits included in the block body by the eth1 deposit bridge.
+func (s *DirectlyBlockScanner) GetBlkDeposits(blk *spec.VersionedSignedBeaconBlock) []*dbmodels.BeaconBlockDeposit {
+	slot, _ := blk.Slot()
+	deposits, err := blk.Deposits()
+	if err != nil {
+		return nil
+	}
+	res := make([]*dbmodels.BeaconBlockDeposit, 0, len(deposits))
+	for i, deposit := range deposits {
+		if deposit.Data == nil {
+			continue
+		}
+		res = append(res, &dbmodels.BeaconBlockDeposit{
+			SlotNumber:            uint64(slot),
+			DepositPosition:       i,
+			Pubkey:                deposit.Data.PublicKey.String(),
+			WithdrawalCredentials: fmt.Sprintf("%#x", deposit.Data.WithdrawalCredentials),
+			Amount:                uint64(deposit.Data.Amount),
+			Signature:             deposit.Data.Signature.String(),
+		})
+	}
+	return res
+}
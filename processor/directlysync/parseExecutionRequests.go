@@ -0,0 +1,145 @@
+package directlysync
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"strings"
+)
+
+type executionRequests struct {
+	deposits       []*dbmodels.BeaconDepositRequest
+	withdrawals    []*dbmodels.BeaconWithdrawalRequest
+	consolidations []*dbmodels.BeaconConsolidationRequest
+}
+
+func (r *executionRequests) empty() bool {
+	return len(r.deposits) == 0 && len(r.withdrawals) == 0 && len(r.consolidations) == 0
+}
+
+func (s *DirectlyBlockScanner) getElectraExecutionRequests(blk *electra.SignedBeaconBlock) *executionRequests {
+	slot := uint64(blk.Message.Slot)
+	res := &executionRequests{
+		deposits:       make([]*dbmodels.BeaconDepositRequest, 0),
+		withdrawals:    make([]*dbmodels.BeaconWithdrawalRequest, 0),
+		consolidations: make([]*dbmodels.BeaconConsolidationRequest, 0),
+	}
+	requests := blk.Message.Body.ExecutionRequests
+	if requests == nil {
+		return res
+	}
+	for i, req := range requests.Deposits {
+		res.deposits = append(res.deposits, &dbmodels.BeaconDepositRequest{
+			SlotNumber:            slot,
+			RequestIndex:          i,
+			Pubkey:                req.Pubkey.String(),
+			WithdrawalCredentials: fmt.Sprintf("%#x", req.WithdrawalCredentials),
+			Amount:                uint64(req.Amount),
+			Signature:             req.Signature.String(),
+			DepositIndex:          req.Index,
+		})
+	}
+	for i, req := range requests.Withdrawals {
+		res.withdrawals = append(res.withdrawals, &dbmodels.BeaconWithdrawalRequest{
+			SlotNumber:      slot,
+			RequestIndex:    i,
+			SourceAddress:   req.SourceAddress.String(),
+			ValidatorPubkey: req.ValidatorPubkey.String(),
+			Amount:          uint64(req.Amount),
+			FullExit:        req.Amount == 0,
+		})
+	}
+	for i, req := range requests.Consolidations {
+		res.consolidations = append(res.consolidations, &dbmodels.BeaconConsolidationRequest{
+			SlotNumber:    slot,
+			RequestIndex:  i,
+			SourceAddress: req.SourceAddress.String(),
+			SourcePubkey:  req.SourcePubkey.String(),
+			TargetPubkey:  req.TargetPubkey.String(),
+		})
+	}
+	return res
+}
+
+func (s *DirectlyBlockScanner) getFuluExecutionRequests(blk *electra.SignedBeaconBlock) *executionRequests {
+	return s.getElectraExecutionRequests(blk)
+}
+
+func (s *DirectlyBlockScanner) GetBlkExecutionRequests(blk *spec.VersionedSignedBeaconBlock) *executionRequests {
+	if blk.Electra != nil {
+		return s.getElectraExecutionRequests(blk.Electra)
+	}
+	if blk.Fulu != nil {
+		return s.getFuluExecutionRequests(blk.Fulu)
+	}
+	return &executionRequests{}
+}
+
+// fillRequestValidatorIndex resolve the validator index of every pubkey referenced by the requests.
+// Deposits for brand-new validators stay unresolved until the deposit is processed by the chain,
+// they are resolved by the validator scanner once the validator is in the registry.
+func (s *DirectlyBlockScanner) fillRequestValidatorIndex(reqs *executionRequests) error {
+	pubkeys := make([]phase0.BLSPubKey, 0)
+	seen := make(map[string]bool)
+	collect := func(key string) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if pubkey, err := parsePubkey(key); err == nil {
+			pubkeys = append(pubkeys, pubkey)
+		}
+	}
+	for _, req := range reqs.deposits {
+		collect(req.Pubkey)
+	}
+	for _, req := range reqs.withdrawals {
+		collect(req.ValidatorPubkey)
+	}
+	for _, req := range reqs.consolidations {
+		collect(req.SourcePubkey)
+		collect(req.TargetPubkey)
+	}
+	indices, err := s.beaconClient.GetValidatorIndexByPubkeys("head", pubkeys)
+	if err != nil {
+		return err
+	}
+	lookup := func(key string) *uint64 {
+		pubkey, err := parsePubkey(key)
+		if err != nil {
+			return nil
+		}
+		if index, exist := indices[pubkey]; exist {
+			v := uint64(index)
+			return &v
+		}
+		return nil
+	}
+	for _, req := range reqs.deposits {
+		req.ValidatorIndex = lookup(req.Pubkey)
+	}
+	for _, req := range reqs.withdrawals {
+		req.ValidatorIndex = lookup(req.ValidatorPubkey)
+	}
+	for _, req := range reqs.consolidations {
+		req.SourceIndex = lookup(req.SourcePubkey)
+		req.TargetIndex = lookup(req.TargetPubkey)
+	}
+	return nil
+}
+
+func parsePubkey(key string) (phase0.BLSPubKey, error) {
+	var pubkey phase0.BLSPubKey
+	data, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+	if err != nil {
+		return pubkey, err
+	}
+	if len(data) != len(pubkey) {
+		return pubkey, fmt.Errorf("invalid pubkey length %d", len(data))
+	}
+	copy(pubkey[:], data)
+	return pubkey, nil
+}
@@ -0,0 +1,57 @@
+package directlysync
+
+import (
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+// GetSlashedValidators return the validators slashed by the proposer and attester slashings of the block.
+func (s *DirectlyBlockScanner) GetSlashedValidators(blk *spec.VersionedSignedBeaconBlock) []uint64 {
+	slashed := make([]uint64, 0)
+	seen := make(map[uint64]bool)
+	add := func(index uint64) {
+		if !seen[index] {
+			seen[index] = true
+			slashed = append(slashed, index)
+		}
+	}
+	if proposerSlashings, err := blk.ProposerSlashings(); err == nil {
+		for _, slashing := range proposerSlashings {
+			if slashing.SignedHeader1 != nil && slashing.SignedHeader1.Message != nil {
+				add(uint64(slashing.SignedHeader1.Message.ProposerIndex))
+			}
+		}
+	}
+	attesterSlashings, err := blk.AttesterSlashings()
+	if err != nil {
+		return slashed
+	}
+	for _, slashing := range attesterSlashings {
+		att1, err := slashing.Attestation1()
+		if err != nil {
+			continue
+		}
+		att2, err := slashing.Attestation2()
+		if err != nil {
+			continue
+		}
+		indices1, err := att1.AttestingIndices()
+		if err != nil {
+			continue
+		}
+		indices2, err := att2.AttestingIndices()
+		if err != nil {
+			continue
+		}
+		// only validators that signed both conflicting attestations are slashed.
+		signed := make(map[uint64]bool, len(indices1))
+		for _, index := range indices1 {
+			signed[index] = true
+		}
+		for _, index := range indices2 {
+			if signed[index] {
+				add(index)
+			}
+		}
+	}
+	return slashed
+}
@@ -0,0 +1,191 @@
+package dutyscanner
+
+import (
+	"encoding/hex"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"strconv"
+)
+
+type committeeKey struct {
+	slot      uint64
+	committee uint64
+}
+
+// reconcile mark duties whose inclusion window ended before indexedSlot as fulfilled, late or missed.
+func (s *DutyScanner) reconcile(indexedSlot uint64) error {
+	if err := s.reconcileProposers(indexedSlot); err != nil {
+		return err
+	}
+	// attestations for epoch e can be included until the end of epoch e+1.
+	if indexedSlot+1 < 2*slotsPerEpoch {
+		return nil
+	}
+	maxEpoch := (indexedSlot+1)/slotsPerEpoch - 2
+	epochs, err := s.services.Duty.GetPendingAttesterEpochs(maxEpoch)
+	if err != nil {
+		return err
+	}
+	for _, epoch := range epochs {
+		for slot := epoch * slotsPerEpoch; slot < (epoch+1)*slotsPerEpoch; slot++ {
+			if err := s.reconcileAttesterSlot(epoch, slot); err != nil {
+				return err
+			}
+		}
+		s.logger.WithField("epoch", epoch).Info("Reconciled attester duties")
+	}
+	return nil
+}
+
+func (s *DutyScanner) reconcileProposers(indexedSlot uint64) error {
+	duties, err := s.services.Duty.GetPendingProposerDuties(indexedSlot)
+	if err != nil || len(duties) == 0 {
+		return err
+	}
+	blocks, err := s.services.BeaconBlock.GetBlocksBySlotRange(duties[0].Slot, duties[len(duties)-1].Slot)
+	if err != nil {
+		return err
+	}
+	proposers := make(map[uint64]uint64)
+	for _, blk := range blocks {
+		proposers[blk.SlotNumber] = blk.ProposerIndex
+	}
+	for _, duty := range duties {
+		proposer, exist := proposers[duty.Slot]
+		if !exist {
+			// the block scanner skips the slots it failed to fetch, so an empty slot in the
+			// index is confirmed with the beacon node before the duty is marked missed.
+			header, err := s.beaconClient.GetBlockHeaderById(strconv.FormatUint(duty.Slot, 10))
+			switch {
+			case beaconapi.IsNotFound(err):
+			case err != nil || header == nil || header.Header == nil:
+				s.logger.WithField("slot", duty.Slot).WithError(err).Warn("Failed to confirm empty slot, keep proposer duty pending")
+				continue
+			default:
+				proposer, exist = uint64(header.Header.Message.ProposerIndex), true
+			}
+		}
+		status := constant.DUTY_STATUS_MISSED
+		if exist && proposer == duty.ValidatorIndex {
+			status = constant.DUTY_STATUS_FULFILLED
+		}
+		if err := s.services.Duty.UpdateProposerDutyStatus(duty.ID, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DutyScanner) reconcileAttesterSlot(epoch, slot uint64) error {
+	duties, err := s.services.Duty.GetAttesterDutiesBySlot(slot)
+	if err != nil || len(duties) == 0 {
+		return err
+	}
+	atts, err := s.services.Attest.GetAttestationsForSlot(slot, epoch)
+	if err != nil {
+		return err
+	}
+	inclusions := matchAttestations(duties, atts)
+
+	type inclusionGroup struct {
+		status string
+		slot   uint64
+	}
+	groups := make(map[inclusionGroup][]uint)
+	missed := make([]uint, 0)
+	for _, duty := range duties {
+		if duty.Status != constant.DUTY_STATUS_PENDING {
+			continue
+		}
+		included, exist := inclusions[duty.ID]
+		if !exist {
+			missed = append(missed, duty.ID)
+			continue
+		}
+		status := constant.DUTY_STATUS_FULFILLED
+		if included-slot > 1 {
+			status = constant.DUTY_STATUS_LATE
+		}
+		key := inclusionGroup{status: status, slot: included}
+		groups[key] = append(groups[key], duty.ID)
+	}
+	for key, ids := range groups {
+		inclusion, delay := key.slot, key.slot-slot
+		if err := s.services.Duty.UpdateAttesterDutyStatus(ids, key.status, &inclusion, &delay); err != nil {
+			return err
+		}
+	}
+	if err := s.services.Duty.UpdateAttesterDutyStatus(missed, constant.DUTY_STATUS_MISSED, nil, nil); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"slot":   slot,
+		"duties": len(duties),
+		"missed": len(missed),
+	}).Debug("Reconciled attester duties of slot")
+	return nil
+}
+
+// matchAttestations return the earliest inclusion slot of every duty covered by the attestations.
+func matchAttestations(duties []*dbmodels.AttesterDuty, atts []*dbmodels.BeaconAttestation) map[uint]uint64 {
+	committees := make(map[committeeKey][]*dbmodels.AttesterDuty)
+	for _, duty := range duties {
+		key := committeeKey{slot: duty.Slot, committee: duty.CommitteeIndex}
+		members, exist := committees[key]
+		if !exist {
+			members = make([]*dbmodels.AttesterDuty, duty.CommitteeLength)
+			committees[key] = members
+		}
+		if duty.ValidatorCommitteeIndex < uint64(len(members)) {
+			members[duty.ValidatorCommitteeIndex] = duty
+		}
+	}
+
+	inclusions := make(map[uint]uint64)
+	for _, att := range atts {
+		bits, err := hex.DecodeString(att.AggregationBits)
+		if err != nil {
+			continue
+		}
+		offset := uint64(0)
+		for _, committee := range attestationCommittees(att) {
+			members := committees[committeeKey{slot: att.AttestationSlot, committee: committee}]
+			for i, duty := range members {
+				if duty == nil || !bitAt(bits, offset+uint64(i)) {
+					continue
+				}
+				if included, exist := inclusions[duty.ID]; !exist || att.SlotNumber < included {
+					inclusions[duty.ID] = att.SlotNumber
+				}
+			}
+			offset += uint64(len(members))
+		}
+	}
+	return inclusions
+}
+
+// attestationCommittees return the committees covered by the aggregation bits, in order.
+// Since Electra one attestation may aggregate several committees selected by the committee bits.
+func attestationCommittees(att *dbmodels.BeaconAttestation) []uint64 {
+	committees := make([]uint64, 0)
+	if bits, err := hex.DecodeString(att.CommitteeBits); err == nil {
+		for i := uint64(0); i < uint64(len(bits))*8; i++ {
+			if bitAt(bits, i) {
+				committees = append(committees, i)
+			}
+		}
+	}
+	if len(committees) == 0 {
+		committees = append(committees, att.CommitteeIndex)
+	}
+	return committees
+}
+
+func bitAt(bits []byte, i uint64) bool {
+	if i/8 >= uint64(len(bits)) {
+		return false
+	}
+	return bits[i/8]&(1<<(i%8)) != 0
+}
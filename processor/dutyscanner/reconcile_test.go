@@ -0,0 +1,48 @@
+package dutyscanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func newDuties(id uint, slot, committee, length uint64) []*dbmodels.AttesterDuty {
+	duties := make([]*dbmodels.AttesterDuty, 0, length)
+	for i := uint64(0); i < length; i++ {
+		duties = append(duties, &dbmodels.AttesterDuty{
+			ID:                      id + uint(i),
+			Slot:                    slot,
+			CommitteeIndex:          committee,
+			CommitteeLength:         length,
+			ValidatorCommitteeIndex: i,
+		})
+	}
+	return duties
+}
+
+func TestMatchAttestationsPhase0(t *testing.T) {
+	duties := newDuties(1, 64, 0, 4)
+	duties = append(duties, newDuties(10, 64, 1, 3)...)
+	atts := []*dbmodels.BeaconAttestation{
+		// committee 0, validators 0 and 2
+		{SlotNumber: 66, AttestationSlot: 64, CommitteeIndex: 0, AggregationBits: "05"},
+		// committee 0, validator 0 included earlier
+		{SlotNumber: 65, AttestationSlot: 64, CommitteeIndex: 0, AggregationBits: "01"},
+		// committee 1, validator 1
+		{SlotNumber: 65, AttestationSlot: 64, CommitteeIndex: 1, AggregationBits: "02"},
+	}
+	inclusions := matchAttestations(duties, atts)
+	assert.Equal(t, map[uint]uint64{1: 65, 3: 66, 11: 65}, inclusions)
+}
+
+func TestMatchAttestationsElectra(t *testing.T) {
+	duties := newDuties(1, 64, 0, 4)
+	duties = append(duties, newDuties(10, 64, 2, 3)...)
+	// committees 0 and 2, bits span both: committee 0 validator 3, committee 2 validator 0 and 2
+	atts := []*dbmodels.BeaconAttestation{
+		{SlotNumber: 65, AttestationSlot: 64, CommitteeBits: "0500000000000000", AggregationBits: "58"},
+	}
+	inclusions := matchAttestations(duties, atts)
+	assert.Equal(t, map[uint]uint64{4: 65, 10: 65, 12: 65}, inclusions)
+}
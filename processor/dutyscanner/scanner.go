@@ -0,0 +1,206 @@
+package dutyscanner
+
+import (
+	"fmt"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	slotsPerEpoch = uint64(32)
+)
+
+// DutyScanner fetch proposer and attester duties once per epoch ahead of time, and reconcile
+// them against the indexed blocks and attestations once their inclusion window has passed.
+type DutyScanner struct {
+	config       *config.Config
+	db           *gorm.DB
+	rdb          *redis.Client
+	logger       *logrus.Logger
+	services     *services.Services
+	notifier     *notify.Dispatcher
+	quit         chan struct{}
+	beaconClient *beaconapi.BeaconClient
+	running      bool
+}
+
+func NewDutyScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *DutyScanner {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &DutyScanner{
+		config:       cfg,
+		db:           db,
+		rdb:          redis,
+		logger:       logger,
+		services:     svc,
+		quit:         make(chan struct{}),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
+	}
+}
+
+func (s *DutyScanner) Start() error {
+	s.logger.Info("Starting duty scanner service")
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Duty scanner service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_DUTIES)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Duty scan task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 10)
+		}
+	}
+}
+
+func (s *DutyScanner) Stop() {
+	close(s.quit)
+}
+
+func (s *DutyScanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "duty-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	latest, err := s.beaconClient.GetLatestBeaconHeader()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest beacon header")
+		return err
+	}
+	headEpoch := uint64(latest.Header.Message.Slot) / slotsPerEpoch
+
+	// duties of the next epoch are known one epoch ahead.
+	for epoch := task.LastNumber + 1; epoch <= headEpoch+1; epoch++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		if err := s.fetchDuties(epoch, uint64(latest.Header.Message.Slot)); err != nil {
+			if epoch > headEpoch {
+				// the beacon node may not serve proposer duties for the next epoch yet.
+				logger.WithField("epoch", epoch).WithError(err).Debug("Next epoch duties not available yet")
+				break
+			}
+			logger.WithField("epoch", epoch).WithError(err).Error("Failed to fetch duties")
+			return err
+		}
+		task.LastNumber = epoch
+		s.services.ScanTask.UpdateScanTask(task)
+	}
+
+	blockTask, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Warn("Beacon block scan task not found, skip reconciling duties")
+		return nil
+	}
+	if err := s.reconcile(blockTask.LastNumber); err != nil {
+		logger.WithError(err).Error("Failed to reconcile duties")
+		return err
+	}
+	return nil
+}
+
+// fetchDuties save the proposer and attester duties of epoch, headSlot is the slot of the head
+// block, the state of an epoch not started yet.
+func (s *DutyScanner) fetchDuties(epoch uint64, headSlot uint64) error {
+	proposers, err := s.beaconClient.GetProposerDuties(int(epoch))
+	if err != nil {
+		return err
+	}
+	// only the validators active in the epoch attest, they are taken from the state of the
+	// epoch rather than the head registry.
+	state := "head"
+	if start := epoch * slotsPerEpoch; start <= headSlot {
+		state = strconv.FormatUint(start, 10)
+	}
+	vals, err := s.beaconClient.GetValidators(state)
+	if err != nil {
+		return err
+	}
+	indices := activeIndices(vals, epoch)
+	if len(indices) == 0 {
+		return fmt.Errorf("no active validators at epoch %d", epoch)
+	}
+	attesters, err := s.beaconClient.GetAttesterDuties(int(epoch), indices)
+	if err != nil {
+		return err
+	}
+
+	pduties := make([]*dbmodels.ProposerDuty, 0, len(proposers))
+	for _, duty := range proposers {
+		pduties = append(pduties, &dbmodels.ProposerDuty{
+			Epoch:          epoch,
+			Slot:           uint64(duty.Slot),
+			ValidatorIndex: uint64(duty.ValidatorIndex),
+			Status:         constant.DUTY_STATUS_PENDING,
+		})
+	}
+	aduties := make([]*dbmodels.AttesterDuty, 0, len(attesters))
+	for _, duty := range attesters {
+		aduties = append(aduties, &dbmodels.AttesterDuty{
+			Epoch:                   epoch,
+			ValidatorIndex:          uint64(duty.ValidatorIndex),
+			Slot:                    uint64(duty.Slot),
+			CommitteeIndex:          uint64(duty.CommitteeIndex),
+			CommitteeLength:         duty.CommitteeLength,
+			CommitteesAtSlot:        duty.CommitteesAtSlot,
+			ValidatorCommitteeIndex: duty.ValidatorCommitteeIndex,
+			Status:                  constant.DUTY_STATUS_PENDING,
+		})
+	}
+	if err := s.services.Duty.SaveProposerDuties(pduties); err != nil {
+		return err
+	}
+	if err := s.services.Duty.SaveAttesterDuties(aduties); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"epoch":     epoch,
+		"proposers": len(pduties),
+		"attesters": len(aduties),
+	}).Info("Saved epoch duties")
+	return nil
+}
+
+// activeIndices return the indices of the validators active at epoch, in order.
+func activeIndices(vals map[phase0.ValidatorIndex]*apiv1.Validator, epoch uint64) []int {
+	indices := make([]int, 0, len(vals))
+	for index, val := range vals {
+		if val.Validator == nil {
+			continue
+		}
+		if uint64(val.Validator.ActivationEpoch) <= epoch && epoch < uint64(val.Validator.ExitEpoch) {
+			indices = append(indices, int(index))
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
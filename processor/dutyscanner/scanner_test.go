@@ -0,0 +1,21 @@
+package dutyscanner
+
+import (
+	"testing"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveIndices(t *testing.T) {
+	far := phase0.Epoch(1<<64 - 1)
+	vals := map[phase0.ValidatorIndex]*apiv1.Validator{
+		0: {Validator: &phase0.Validator{ActivationEpoch: 0, ExitEpoch: far}},
+		1: {Validator: &phase0.Validator{ActivationEpoch: 0, ExitEpoch: 10}},
+		2: {Validator: &phase0.Validator{ActivationEpoch: 11, ExitEpoch: far}},
+		3: {Validator: &phase0.Validator{ActivationEpoch: 10, ExitEpoch: far}},
+		4: {Validator: &phase0.Validator{ActivationEpoch: far, ExitEpoch: far}},
+	}
+	assert.Equal(t, []int{0, 3}, activeIndices(vals, 10))
+}
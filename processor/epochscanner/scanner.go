@@ -0,0 +1,358 @@
+package epochscanner
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	slotsPerEpoch = uint64(32)
+)
+
+// timelyHeadFlagIndex is the bit of the head vote in the participation flags.
+const timelyHeadFlagIndex = 2
+
+// EpochScanner produce one summary row per epoch once the epoch is finalized
+// and all of its blocks are indexed.
+type EpochScanner struct {
+	config       *config.Config
+	db           *gorm.DB
+	rdb          *redis.Client
+	logger       *logrus.Logger
+	services     *services.Services
+	notifier     *notify.Dispatcher
+	publisher    *stream.Publisher
+	quit         chan struct{}
+	beaconClient *beaconapi.BeaconClient
+	running      bool
+	genesis      time.Time
+	epochSeconds uint64
+	altairEpoch  uint64
+}
+
+func NewEpochScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *EpochScanner {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &EpochScanner{
+		config:       cfg,
+		db:           db,
+		rdb:          redis,
+		logger:       logger,
+		services:     svc,
+		quit:         make(chan struct{}),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		publisher:    stream.NewPublisher(redis, cfg.Stream),
+		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
+	}
+}
+
+func (s *EpochScanner) Start() error {
+	s.logger.Info("Starting epoch scanner service")
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Epoch scanner service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_EPOCH_SUMMARY)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Epoch summary task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 10)
+		}
+	}
+}
+
+func (s *EpochScanner) Stop() {
+	close(s.quit)
+}
+
+func (s *EpochScanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "epoch-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	finality, err := s.beaconClient.GetFinalityCheckpoints("head")
+	if err != nil {
+		logger.WithError(err).Error("Failed to get finality checkpoints")
+		return err
+	}
+	blockTask, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Warn("Beacon block scan task not found, skip epoch summary")
+		return nil
+	}
+	finalized := uint64(finality.Finalized.Epoch)
+	if err := s.loadChainTime(); err != nil {
+		logger.WithError(err).Error("Failed to load chain genesis")
+		return err
+	}
+
+	for epoch := task.LastNumber + 1; epoch < finalized && lastSlot(epoch) <= blockTask.LastNumber; epoch++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		summary, rewards, err := s.summarize(epoch)
+		if err != nil {
+			logger.WithField("epoch", epoch).WithError(err).Error("Failed to summarize epoch")
+			return err
+		}
+		if err := s.services.Balance.AddEpochRewards(s.dayOf(epoch), epoch, rewards); err != nil {
+			logger.WithField("epoch", epoch).WithError(err).Error("Failed to save validator rewards")
+			return err
+		}
+		if err := s.services.Epoch.SaveEpochSummary(summary); err != nil {
+			logger.WithField("epoch", epoch).WithError(err).Error("Failed to save epoch summary")
+			return err
+		}
+		if err := s.publisher.PublishEpochSummary(context.Background(), summary); err != nil {
+			logger.WithField("epoch", epoch).WithError(err).Warn("Failed to publish epoch summary")
+		}
+		task.LastNumber = epoch
+		s.services.ScanTask.UpdateScanTask(task)
+		logger.WithFields(logrus.Fields{
+			"epoch":    epoch,
+			"proposed": summary.ProposedBlocks,
+			"target":   summary.TargetParticipation,
+		}).Info("Saved epoch summary")
+	}
+	return nil
+}
+
+func lastSlot(epoch uint64) uint64 {
+	return (epoch+1)*slotsPerEpoch - 1
+}
+
+// loadChainTime fetch genesis time and epoch duration used to map epochs to days, and the
+// altair fork epoch.
+func (s *EpochScanner) loadChainTime() error {
+	if s.epochSeconds != 0 {
+		return nil
+	}
+	genesis, err := s.beaconClient.GetGenesis()
+	if err != nil {
+		return err
+	}
+	secondsPerSlot, err := s.beaconClient.GetIntConfig(beaconapi.SECONDS_PER_SLOT)
+	if err != nil {
+		return err
+	}
+	if secondsPerSlot == 0 {
+		secondsPerSlot = 12
+	}
+	// a chain without the key start at altair, a fork not scheduled is the far future epoch.
+	if v, ok := s.beaconClient.GetBeaconConfig()[beaconapi.ALTAIR_FORK_EPOCH]; ok {
+		if s.altairEpoch, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return err
+		}
+	}
+	s.genesis = genesis.GenesisTime
+	s.epochSeconds = uint64(secondsPerSlot) * slotsPerEpoch
+	return nil
+}
+
+// dayOf return the UTC day the epoch starts in.
+func (s *EpochScanner) dayOf(epoch uint64) time.Time {
+	start := s.genesis.Add(time.Duration(epoch*s.epochSeconds) * time.Second).UTC()
+	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// summarize build the summary of epoch and return it with the rewards of every validator.
+func (s *EpochScanner) summarize(epoch uint64) (*dbmodels.EpochSummary, []*dbmodels.ValidatorRewardDaily, error) {
+	summary := &dbmodels.EpochSummary{Epoch: epoch}
+	endSlot := lastSlot(epoch)
+	state := fmt.Sprintf("%d", endSlot)
+
+	blocks, err := s.services.BeaconBlock.GetBlocksBySlotRange(epoch*slotsPerEpoch, endSlot)
+	if err != nil {
+		return nil, nil, err
+	}
+	summary.ProposedBlocks = uint(len(blocks))
+	summary.MissedBlocks = uint(slotsPerEpoch) - summary.ProposedBlocks
+
+	finality, err := s.beaconClient.GetFinalityCheckpoints(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	summary.JustifiedEpoch = uint64(finality.Justified.Epoch)
+	summary.JustifiedRoot = finality.Justified.Root.String()
+	summary.FinalizedEpoch = uint64(finality.Finalized.Epoch)
+	summary.FinalizedRoot = finality.Finalized.Root.String()
+
+	vals, err := s.beaconClient.GetValidators(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	effective := countValidators(summary, vals)
+	if epoch < s.altairEpoch {
+		// phase0 states have no participation flags and the rewards api does not serve them,
+		// the summary has no participation and rewards.
+		return summary, nil, nil
+	}
+
+	rewards, err := s.beaconClient.GetAllValReward(int(epoch))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !applyAttestationRewards(summary, rewards, effective) {
+		// the participation of epoch is complete in the state at the end of the next epoch.
+		flags, err := s.beaconClient.GetPreviousEpochParticipation(fmt.Sprintf("%d", lastSlot(epoch+1)))
+		if err != nil {
+			return nil, nil, err
+		}
+		applyHeadParticipation(summary, flags, effective)
+	}
+
+	blockRewards := make([]*apiv1.BlockRewards, 0, len(blocks))
+	for _, blk := range blocks {
+		reward, err := s.beaconClient.GetBlockReward(int(blk.SlotNumber))
+		if err != nil {
+			return nil, nil, err
+		}
+		summary.ProposerRewards += int64(reward.Total)
+		blockRewards = append(blockRewards, reward)
+	}
+	summary.TotalRewards = summary.AttestationRewards + summary.ProposerRewards
+	return summary, validatorRewards(rewards, blockRewards), nil
+}
+
+// attestationReward return the sum of the reward components of one validator, penalties negative.
+func attestationReward(reward apiv1.ValidatorAttestationRewards) int64 {
+	total := int64(reward.Head) + reward.Target + reward.Source + int64(reward.Inactivity)
+	if reward.InclusionDelay != nil {
+		total += int64(*reward.InclusionDelay)
+	}
+	return total
+}
+
+// validatorRewards return the attestation and proposer rewards of every validator rewarded or
+// penalized in the epoch.
+func validatorRewards(rewards *apiv1.AttestationRewards, blocks []*apiv1.BlockRewards) []*dbmodels.ValidatorRewardDaily {
+	byIndex := make(map[uint64]*dbmodels.ValidatorRewardDaily)
+	get := func(index phase0.ValidatorIndex) *dbmodels.ValidatorRewardDaily {
+		reward, ok := byIndex[uint64(index)]
+		if !ok {
+			reward = &dbmodels.ValidatorRewardDaily{ValidatorIndex: uint64(index)}
+			byIndex[uint64(index)] = reward
+		}
+		return reward
+	}
+	for _, reward := range rewards.TotalRewards {
+		if total := attestationReward(reward); total != 0 {
+			get(reward.ValidatorIndex).AttestationRewards += total
+		}
+	}
+	for _, block := range blocks {
+		get(block.ProposerIndex).ProposerRewards += int64(block.Total)
+	}
+	result := make([]*dbmodels.ValidatorRewardDaily, 0, len(byIndex))
+	for _, reward := range byIndex {
+		result = append(result, reward)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ValidatorIndex < result[j].ValidatorIndex })
+	return result
+}
+
+// countValidators fill the validator counts and active balance, and return the effective
+// balance of every active validator.
+func countValidators(summary *dbmodels.EpochSummary, vals map[phase0.ValidatorIndex]*apiv1.Validator) map[phase0.ValidatorIndex]uint64 {
+	effective := make(map[phase0.ValidatorIndex]uint64)
+	for index, val := range vals {
+		switch val.Status {
+		case apiv1.ValidatorStatePendingInitialized, apiv1.ValidatorStatePendingQueued:
+			summary.PendingValidators++
+		case apiv1.ValidatorStateActiveExiting:
+			summary.ExitingValidators++
+		case apiv1.ValidatorStateActiveSlashed, apiv1.ValidatorStateExitedSlashed:
+			summary.SlashedValidators++
+		case apiv1.ValidatorStateExitedUnslashed:
+			summary.ExitedValidators++
+		case apiv1.ValidatorStateWithdrawalPossible, apiv1.ValidatorStateWithdrawalDone:
+			summary.WithdrawalValidators++
+		}
+		if val.Status.IsActive() {
+			summary.ActiveValidators++
+			effective[index] = uint64(val.Validator.EffectiveBalance)
+			summary.TotalActiveBalance += uint64(val.Validator.EffectiveBalance)
+		}
+	}
+	return effective
+}
+
+// applyAttestationRewards compute balance weighted participation from the reward components.
+// A missed source or target vote is always penalized, so a non-negative component is a correct
+// vote, also during an inactivity leak. The head vote is neither rewarded nor penalized during
+// a leak, it return false when head participation could not be told from the rewards.
+func applyAttestationRewards(summary *dbmodels.EpochSummary, rewards *apiv1.AttestationRewards, effective map[phase0.ValidatorIndex]uint64) bool {
+	leaking := true
+	for _, ideal := range rewards.IdealRewards {
+		if ideal.Head > 0 {
+			leaking = false
+			break
+		}
+	}
+	var source, target, head uint64
+	for _, reward := range rewards.TotalRewards {
+		balance := effective[reward.ValidatorIndex]
+		if reward.Source >= 0 {
+			source += balance
+		}
+		if reward.Target >= 0 {
+			target += balance
+		}
+		if reward.Head > 0 {
+			head += balance
+		}
+		summary.AttestationRewards += attestationReward(reward)
+	}
+	if summary.TotalActiveBalance > 0 {
+		summary.SourceParticipation = float64(source) / float64(summary.TotalActiveBalance)
+		summary.TargetParticipation = float64(target) / float64(summary.TotalActiveBalance)
+		summary.HeadParticipation = float64(head) / float64(summary.TotalActiveBalance)
+	}
+	return !leaking
+}
+
+// applyHeadParticipation compute balance weighted head participation from the participation
+// flags of the epoch.
+func applyHeadParticipation(summary *dbmodels.EpochSummary, flags []altair.ParticipationFlags, effective map[phase0.ValidatorIndex]uint64) {
+	var head uint64
+	for index, balance := range effective {
+		if int(index) < len(flags) && flags[index]&(1<<timelyHeadFlagIndex) != 0 {
+			head += balance
+		}
+	}
+	if summary.TotalActiveBalance > 0 {
+		summary.HeadParticipation = float64(head) / float64(summary.TotalActiveBalance)
+	}
+}
@@ -0,0 +1,68 @@
+package epochscanner
+
+import (
+	"testing"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+var effective = map[phase0.ValidatorIndex]uint64{0: 32, 1: 32, 2: 32, 3: 32}
+
+func TestApplyAttestationRewards(t *testing.T) {
+	summary := &dbmodels.EpochSummary{TotalActiveBalance: 128}
+	rewards := &apiv1.AttestationRewards{
+		IdealRewards: []apiv1.IdealAttestationRewards{{EffectiveBalance: 32, Head: 10, Target: 20, Source: 10}},
+		TotalRewards: []apiv1.ValidatorAttestationRewards{
+			{ValidatorIndex: 0, Head: 10, Target: 20, Source: 10},
+			{ValidatorIndex: 1, Target: 20, Source: 10},
+			{ValidatorIndex: 2, Target: -20, Source: 10},
+			{ValidatorIndex: 3, Target: -20, Source: -10},
+		},
+	}
+	assert.True(t, applyAttestationRewards(summary, rewards, effective))
+	assert.Equal(t, 0.75, summary.SourceParticipation)
+	assert.Equal(t, 0.5, summary.TargetParticipation)
+	assert.Equal(t, 0.25, summary.HeadParticipation)
+	assert.Equal(t, int64(30), summary.AttestationRewards)
+}
+
+func TestApplyAttestationRewardsLeak(t *testing.T) {
+	summary := &dbmodels.EpochSummary{TotalActiveBalance: 128}
+	// correct votes get no reward during an inactivity leak, missed ones are still penalized.
+	rewards := &apiv1.AttestationRewards{
+		IdealRewards: []apiv1.IdealAttestationRewards{{EffectiveBalance: 32}},
+		TotalRewards: []apiv1.ValidatorAttestationRewards{
+			{ValidatorIndex: 0},
+			{ValidatorIndex: 1},
+			{ValidatorIndex: 2, Target: -20, Inactivity: 5},
+			{ValidatorIndex: 3, Target: -20, Source: -10, Inactivity: 5},
+		},
+	}
+	assert.False(t, applyAttestationRewards(summary, rewards, effective))
+	assert.Equal(t, 0.75, summary.SourceParticipation)
+	assert.Equal(t, 0.5, summary.TargetParticipation)
+
+	flags := []altair.ParticipationFlags{7, 3, 1, 0}
+	applyHeadParticipation(summary, flags, effective)
+	assert.Equal(t, 0.25, summary.HeadParticipation)
+}
+
+func TestValidatorRewards(t *testing.T) {
+	rewards := &apiv1.AttestationRewards{
+		TotalRewards: []apiv1.ValidatorAttestationRewards{
+			{ValidatorIndex: 0, Head: 10, Target: 20, Source: 10},
+			{ValidatorIndex: 1},
+			{ValidatorIndex: 2, Target: -20, Source: -10},
+		},
+	}
+	blocks := []*apiv1.BlockRewards{{ProposerIndex: 2, Total: 100}, {ProposerIndex: 3, Total: 50}}
+	assert.Equal(t, []*dbmodels.ValidatorRewardDaily{
+		{ValidatorIndex: 0, AttestationRewards: 40},
+		{ValidatorIndex: 2, AttestationRewards: -30, ProposerRewards: 100},
+		{ValidatorIndex: 3, ProposerRewards: 50},
+	}, validatorRewards(rewards, blocks))
+}
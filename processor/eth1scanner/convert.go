@@ -0,0 +1,129 @@
+package eth1scanner
+
+import (
+	"encoding/json"
+	execapi "github.com/xueqianLu/deep-dive-beacon/execution"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func toEth1Block(block *execapi.Block, receipts []*execapi.Receipt) *services.Eth1Block {
+	res := &services.Eth1Block{
+		Header: &dbmodels.Eth1BlockHeader{
+			BlockHash:        block.Hash,
+			ParentHash:       block.ParentHash,
+			Number:           block.Number.Uint64(),
+			Miner:            block.Miner,
+			Timestamp:        block.Timestamp.Uint64(),
+			GasLimit:         block.GasLimit.Uint64(),
+			GasUsed:          block.GasUsed.Uint64(),
+			BaseFeePerGas:    "0",
+			BlobGasUsed:      quantityPtr(block.BlobGasUsed),
+			ExcessBlobGas:    quantityPtr(block.ExcessBlobGas),
+			StateRoot:        block.StateRoot,
+			TransactionsRoot: block.TransactionsRoot,
+			ReceiptsRoot:     block.ReceiptsRoot,
+			ExtraData:        block.ExtraData,
+			ParentBeaconRoot: block.ParentBeaconRoot,
+			Size:             block.Size.Uint64(),
+		},
+		Body: &dbmodels.Eth1BlockBody{
+			BlockHash:        block.Hash,
+			Number:           block.Number.Uint64(),
+			TransactionCount: uint(len(block.TransactionHashes)),
+			WithdrawalCount:  uint(len(block.Withdrawals)),
+			WithdrawalsRoot:  block.WithdrawalsRoot,
+			Withdrawals:      "[]",
+		},
+		Transactions: make([]*dbmodels.Eth1Transaction, 0, len(block.Transactions)),
+		Receipts:     make([]*dbmodels.Eth1Receipt, 0, len(receipts)),
+		Logs:         make([]*dbmodels.Eth1Log, 0),
+	}
+	if block.BaseFeePerGas != nil {
+		res.Header.BaseFeePerGas = block.BaseFeePerGas.String()
+	}
+	if len(block.Withdrawals) > 0 {
+		if data, err := json.Marshal(block.Withdrawals); err == nil {
+			res.Body.Withdrawals = string(data)
+		}
+	}
+	for _, tx := range block.Transactions {
+		res.Transactions = append(res.Transactions, &dbmodels.Eth1Transaction{
+			Hash:                 tx.Hash,
+			BlockHash:            block.Hash,
+			BlockNumber:          block.Number.Uint64(),
+			TransactionIndex:     uint(tx.TransactionIndex),
+			Type:                 uint8(tx.Type),
+			From:                 tx.From,
+			To:                   tx.To,
+			Nonce:                tx.Nonce.Uint64(),
+			Value:                tx.Value.String(),
+			Gas:                  tx.Gas.Uint64(),
+			GasPrice:             bigPtr(tx.GasPrice),
+			MaxFeePerGas:         bigPtr(tx.MaxFeePerGas),
+			MaxPriorityFeePerGas: bigPtr(tx.MaxPriorityFeePerGas),
+			MaxFeePerBlobGas:     bigPtr(tx.MaxFeePerBlobGas),
+			BlobHashes:           uint(len(tx.BlobVersionedHashes)),
+			Input:                tx.Input,
+		})
+	}
+	for _, receipt := range receipts {
+		res.Receipts = append(res.Receipts, &dbmodels.Eth1Receipt{
+			TransactionHash:   receipt.TransactionHash,
+			BlockHash:         block.Hash,
+			BlockNumber:       block.Number.Uint64(),
+			Status:            uint8(receipt.Status),
+			GasUsed:           receipt.GasUsed.Uint64(),
+			CumulativeGasUsed: receipt.CumulativeGasUsed.Uint64(),
+			EffectiveGasPrice: bigString(receipt.EffectiveGasPrice),
+			ContractAddress:   receipt.ContractAddress,
+			BlobGasUsed:       quantityPtr(receipt.BlobGasUsed),
+			BlobGasPrice:      bigPtr(receipt.BlobGasPrice),
+			LogCount:          uint(len(receipt.Logs)),
+		})
+		for _, log := range receipt.Logs {
+			res.Logs = append(res.Logs, toEth1Log(block, log))
+		}
+	}
+	return res
+}
+
+func toEth1Log(block *execapi.Block, log *execapi.Log) *dbmodels.Eth1Log {
+	topics := make([]string, 4)
+	copy(topics, log.Topics)
+	return &dbmodels.Eth1Log{
+		BlockHash:       block.Hash,
+		LogIndex:        uint(log.LogIndex),
+		BlockNumber:     block.Number.Uint64(),
+		TransactionHash: log.TransactionHash,
+		Address:         log.Address,
+		Topic0:          topics[0],
+		Topic1:          topics[1],
+		Topic2:          topics[2],
+		Topic3:          topics[3],
+		Data:            log.Data,
+	}
+}
+
+func quantityPtr(q *execapi.Quantity) *uint64 {
+	if q == nil {
+		return nil
+	}
+	v := q.Uint64()
+	return &v
+}
+
+func bigPtr(b *execapi.BigInt) *string {
+	if b == nil {
+		return nil
+	}
+	v := b.String()
+	return &v
+}
+
+func bigString(b *execapi.BigInt) string {
+	if b == nil {
+		return "0"
+	}
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package eth1scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	execapi "github.com/xueqianLu/deep-dive-beacon/execution"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	// maxReorgDepth bound how far back the scanner look for the common ancestor.
+	maxReorgDepth   = uint64(128)
+	errReorgTooDeep = errors.New("reorg deeper than max depth")
+)
+
+// Eth1Scanner index execution headers, transactions, receipts and logs, and unwind
+// the indexed blocks when the parent hash no longer match.
+type Eth1Scanner struct {
+	config     *config.Config
+	db         *gorm.DB
+	rdb        *redis.Client
+	logger     *logrus.Logger
+	services   *services.Services
+	notifier   *notify.Dispatcher
+	publisher  *stream.Publisher
+	quit       chan struct{}
+	execClient *execapi.ExecutionClient
+	running    bool
+}
+
+func NewEth1Scanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *Eth1Scanner {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &Eth1Scanner{
+		config:     cfg,
+		db:         db,
+		rdb:        redis,
+		logger:     logger,
+		services:   svc,
+		quit:       make(chan struct{}),
+		notifier:   notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		publisher:  stream.NewPublisher(redis, cfg.Stream),
+		execClient: execapi.NewExecutionClient(cfg.Chain.GethUrl),
+	}
+}
+
+func (s *Eth1Scanner) Start() error {
+	s.logger.Info("Starting eth1 scanner service")
+
+	ticker := time.NewTicker(6 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Eth1 scanner service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_ETH1_BLOCK)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Eth1 scan task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 6)
+		}
+	}
+}
+
+func (s *Eth1Scanner) Stop() {
+	close(s.quit)
+}
+
+func (s *Eth1Scanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "eth1-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	latest, err := s.execClient.BlockNumber()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest block number")
+		return err
+	}
+
+	for number := task.LastNumber + 1; number <= latest; number++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		block, err := s.execClient.GetBlockByNumber(number, true)
+		if err != nil {
+			logger.WithField("number", number).WithError(err).Error("Failed to get block")
+			return err
+		}
+		parent, err := s.services.Eth1.GetHeaderByNumber(number - 1)
+		if err != nil {
+			return err
+		}
+		if parent != nil && parent.BlockHash != block.ParentHash {
+			ancestor, err := s.findCommonAncestor(number - 1)
+			if err != nil {
+				logger.WithField("number", number).WithError(err).Error("Failed to find common ancestor")
+				return err
+			}
+			// the event is dispatched before unwinding, the reorg is not detected again after.
+			err = s.notifier.Dispatch(&notify.Event{
+				Type:    constant.EVENT_REORG,
+				Message: fmt.Sprintf("execution chain reorg at block %d, depth %d", ancestor+1, number-1-ancestor),
+				Data: map[string]interface{}{
+					"layer":    "execution",
+					"ancestor": ancestor,
+					"depth":    number - 1 - ancestor,
+				},
+			})
+			if err != nil {
+				logger.WithError(err).Error("Failed to dispatch reorg")
+				return err
+			}
+			if err := s.services.Eth1.DeleteFromNumber(ancestor + 1); err != nil {
+				logger.WithError(err).Error("Failed to unwind reorged blocks")
+				return err
+			}
+			logger.WithFields(logrus.Fields{
+				"ancestor": ancestor,
+				"depth":    number - 1 - ancestor,
+			}).Warn("Execution chain reorg detected, unwound indexed blocks")
+			reorg := &stream.Reorg{Layer: "execution", Ancestor: ancestor, Depth: number - 1 - ancestor}
+			if err := s.publisher.PublishReorg(context.Background(), reorg); err != nil {
+				logger.WithError(err).Warn("Failed to publish reorg")
+			}
+			task.LastNumber = ancestor
+			s.services.ScanTask.UpdateScanTask(task)
+			return nil
+		}
+
+		receipts, err := s.execClient.GetBlockReceipts(block)
+		if err != nil {
+			logger.WithField("number", number).WithError(err).Error("Failed to get block receipts")
+			return err
+		}
+		if err := s.services.Eth1.SaveBlock(toEth1Block(block, receipts)); err != nil {
+			logger.WithField("number", number).WithError(err).Error("Failed to save block")
+			return err
+		}
+		task.LastNumber = number
+		s.services.ScanTask.UpdateScanTask(task)
+		logger.WithFields(logrus.Fields{
+			"number": number,
+			"txs":    len(block.Transactions),
+		}).Debug("Indexed execution block")
+	}
+	return nil
+}
+
+// findCommonAncestor walk back from number until the indexed hash match the canonical chain.
+func (s *Eth1Scanner) findCommonAncestor(number uint64) (uint64, error) {
+	for depth := uint64(0); depth < maxReorgDepth && number > 0; depth++ {
+		indexed, err := s.services.Eth1.GetHeaderByNumber(number)
+		if err != nil {
+			return 0, err
+		}
+		canonical, err := s.execClient.GetBlockByNumber(number, false)
+		if err != nil {
+			return 0, err
+		}
+		if indexed == nil || indexed.BlockHash == canonical.Hash {
+			return number, nil
+		}
+		number--
+	}
+	return 0, errReorgTooDeep
+}
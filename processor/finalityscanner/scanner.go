@@ -0,0 +1,306 @@
+package finalityscanner
+
+import (
+	"context"
+	"fmt"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	slotsPerEpoch = uint64(32)
+	// maxConfirmBlocks bound the blocks checked against the beacon node in one pass.
+	maxConfirmBlocks = 64
+)
+
+// FinalityScanner record the head checkpoints once per epoch, mark indexed blocks
+// finalized and raise an alert when finality falls behind.
+type FinalityScanner struct {
+	config       *config.Config
+	db           *gorm.DB
+	rdb          *redis.Client
+	logger       *logrus.Logger
+	services     *services.Services
+	notifier     *notify.Dispatcher
+	publisher    *stream.Publisher
+	quit         chan struct{}
+	beaconClient *beaconapi.BeaconClient
+	running      bool
+	// confirmed is the slot the next confirmUnfinalized pass start above.
+	confirmed uint64
+}
+
+func NewFinalityScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *FinalityScanner {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &FinalityScanner{
+		config:       cfg,
+		db:           db,
+		rdb:          redis,
+		logger:       logger,
+		services:     svc,
+		quit:         make(chan struct{}),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		publisher:    stream.NewPublisher(redis, cfg.Stream),
+		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
+	}
+}
+
+func (s *FinalityScanner) Start() error {
+	s.logger.Info("Starting finality scanner service")
+
+	ticker := time.NewTicker(12 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Finality scanner service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_FINALITY)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Finality task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 12)
+		}
+	}
+}
+
+func (s *FinalityScanner) Stop() {
+	close(s.quit)
+}
+
+func (s *FinalityScanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "finality-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	latest, err := s.beaconClient.GetLatestBeaconHeader()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest beacon header")
+		return err
+	}
+	headSlot := uint64(latest.Header.Message.Slot)
+	finality, err := s.beaconClient.GetFinalityCheckpoints(fmt.Sprintf("%d", headSlot))
+	if err != nil {
+		logger.WithError(err).Error("Failed to get finality checkpoints")
+		return err
+	}
+
+	headEpoch := headSlot / slotsPerEpoch
+	finalizedEpoch := uint64(finality.Finalized.Epoch)
+	distance := uint64(0)
+	if headEpoch > finalizedEpoch {
+		distance = headEpoch - finalizedEpoch
+	}
+
+	if headEpoch > task.LastNumber {
+		previous, err := s.services.Finality.GetLatestCheckpoint()
+		if err != nil {
+			logger.WithError(err).Error("Failed to get latest finality checkpoint")
+			return err
+		}
+		// the event is dispatched before the checkpoint is saved, so a failed dispatch is
+		// retried next round instead of being lost.
+		if previous != nil && finalizedEpoch > previous.FinalizedEpoch {
+			err := s.notifier.Dispatch(&notify.Event{
+				Type:    constant.EVENT_FINALIZED_EPOCH,
+				Epoch:   finalizedEpoch,
+				Message: fmt.Sprintf("epoch %d finalized, root %s", finalizedEpoch, finality.Finalized.Root.String()),
+				Data: map[string]interface{}{
+					"root":     finality.Finalized.Root.String(),
+					"previous": previous.FinalizedEpoch,
+				},
+			})
+			if err != nil {
+				logger.WithError(err).Error("Failed to dispatch finalized epoch")
+				return err
+			}
+		}
+		checkpoint := toCheckpoint(headSlot, distance, finality)
+		if err := s.services.Finality.SaveCheckpoint(checkpoint); err != nil {
+			logger.WithError(err).Error("Failed to save finality checkpoint")
+			return err
+		}
+		task.LastNumber = headEpoch
+		s.services.ScanTask.UpdateScanTask(task)
+		logger.WithFields(logrus.Fields{
+			"epoch":     headEpoch,
+			"justified": uint64(finality.Justified.Epoch),
+			"finalized": finalizedEpoch,
+			"distance":  distance,
+		}).Info("Recorded finality checkpoint")
+		if previous == nil || finalizedEpoch > previous.FinalizedEpoch {
+			if err := s.publisher.PublishFinalized(context.Background(), checkpoint); err != nil {
+				logger.WithError(err).Warn("Failed to publish finalized checkpoint")
+			}
+		}
+	}
+
+	// the checkpoint block and its ancestors are final, other blocks at or below it are orphaned.
+	marked, orphaned, err := s.services.Finality.MarkFinalized(finality.Finalized.Root.String())
+	if err != nil {
+		logger.WithError(err).Error("Failed to mark blocks finalized")
+		return err
+	}
+	if marked > 0 {
+		logger.WithFields(logrus.Fields{
+			"finalized": finalizedEpoch,
+			"blocks":    marked,
+		}).Debug("Marked blocks finalized")
+	}
+	if orphaned > 0 {
+		logger.WithFields(logrus.Fields{
+			"finalized": finalizedEpoch,
+			"blocks":    orphaned,
+		}).Warn("Removed orphaned blocks")
+	}
+	if err := s.confirmUnfinalized(finalizedEpoch * slotsPerEpoch); err != nil {
+		logger.WithError(err).Error("Failed to confirm unfinalized blocks")
+		return err
+	}
+
+	return s.checkStall(headEpoch, finalizedEpoch, distance)
+}
+
+// blockResolution is how an unfinalized block is resolved against the canonical root of its slot.
+type blockResolution int
+
+const (
+	// resolveUnknown leave the block as it is.
+	resolveUnknown blockResolution = iota
+	// resolveFinalize flag the block finalized.
+	resolveFinalize
+	// resolveBackfill fill the missing root of the block and flag it finalized.
+	resolveBackfill
+	// resolveOrphan remove the block as orphaned.
+	resolveOrphan
+)
+
+// resolveBlock decide how to resolve a block with the stored root against the canonical root,
+// canonical is empty if the slot has no block. Blocks indexed before the root was recorded
+// have an empty stored root, they are never removed since there is nothing to compare.
+func resolveBlock(stored, canonical string) blockResolution {
+	switch {
+	case stored == "" && canonical == "":
+		return resolveUnknown
+	case stored == "":
+		return resolveBackfill
+	case strings.EqualFold(stored, canonical):
+		return resolveFinalize
+	default:
+		return resolveOrphan
+	}
+}
+
+// confirmUnfinalized check the blocks at or below slot the walk from the finalized checkpoint
+// did not reach, such as blocks below a gap of the index, against the canonical root of their
+// slot on the beacon node.
+func (s *FinalityScanner) confirmUnfinalized(slot uint64) error {
+	blocks, err := s.services.Finality.GetUnfinalizedBlocks(s.confirmed, slot, maxConfirmBlocks)
+	if err != nil {
+		return err
+	}
+	// blocks left unknown stay unfinalized, the next pass start above them until a pass
+	// reach the end, so they do not hold back the blocks after them.
+	s.confirmed = 0
+	if len(blocks) == maxConfirmBlocks {
+		s.confirmed = blocks[len(blocks)-1].SlotNumber
+	}
+	for _, blk := range blocks {
+		root, err := s.beaconClient.GetCanonicalRoot(blk.SlotNumber)
+		if err != nil {
+			return err
+		}
+		switch resolveBlock(blk.BlockRoot, root) {
+		case resolveFinalize, resolveBackfill:
+			if err := s.services.Finality.FinalizeBlock(blk, root); err != nil {
+				return err
+			}
+		case resolveOrphan:
+			if err := s.services.Finality.RemoveOrphan(blk); err != nil {
+				return err
+			}
+			s.logger.WithFields(logrus.Fields{
+				"slot": blk.SlotNumber,
+				"root": blk.BlockRoot,
+			}).Warn("Removed orphaned block")
+		default:
+			s.logger.WithField("slot", blk.SlotNumber).Warn("No canonical block for block without root")
+		}
+	}
+	return nil
+}
+
+// checkStall raise an alert when finality distance crosses the configured threshold,
+// and another one when it recovers. The last alert decide the current state, so a
+// restart does not alert again for the same stall.
+func (s *FinalityScanner) checkStall(headEpoch, finalizedEpoch, distance uint64) error {
+	last, err := s.services.Finality.GetLatestAlert(constant.ALERT_FINALITY_STALL, constant.ALERT_FINALITY_RECOVERED)
+	if err != nil {
+		return err
+	}
+	stalled := last != nil && last.AlertType == constant.ALERT_FINALITY_STALL
+	threshold := s.config.Indexer.FinalityStallEpochs
+
+	var alert *dbmodels.ChainAlert
+	switch {
+	case distance > threshold && !stalled:
+		alert = &dbmodels.ChainAlert{
+			AlertType: constant.ALERT_FINALITY_STALL,
+			Epoch:     headEpoch,
+			Message: fmt.Sprintf("finality delayed: head epoch %d, finalized epoch %d, distance %d exceeds %d epochs",
+				headEpoch, finalizedEpoch, distance, threshold),
+		}
+	case distance <= threshold && stalled:
+		alert = &dbmodels.ChainAlert{
+			AlertType: constant.ALERT_FINALITY_RECOVERED,
+			Epoch:     headEpoch,
+			Message: fmt.Sprintf("finality recovered: head epoch %d, finalized epoch %d, stalled since epoch %d",
+				headEpoch, finalizedEpoch, last.Epoch),
+		}
+	default:
+		return nil
+	}
+	s.logger.WithFields(logrus.Fields{
+		"alert":     alert.AlertType,
+		"epoch":     headEpoch,
+		"finalized": finalizedEpoch,
+		"distance":  distance,
+	}).Warn(alert.Message)
+	return s.services.Finality.SaveAlert(alert)
+}
+
+func toCheckpoint(headSlot, distance uint64, finality *apiv1.Finality) *dbmodels.FinalityCheckpoint {
+	return &dbmodels.FinalityCheckpoint{
+		Epoch:                  headSlot / slotsPerEpoch,
+		HeadSlot:               headSlot,
+		PreviousJustifiedEpoch: uint64(finality.PreviousJustified.Epoch),
+		PreviousJustifiedRoot:  finality.PreviousJustified.Root.String(),
+		JustifiedEpoch:         uint64(finality.Justified.Epoch),
+		JustifiedRoot:          finality.Justified.Root.String(),
+		FinalizedEpoch:         uint64(finality.Finalized.Epoch),
+		FinalizedRoot:          finality.Finalized.Root.String(),
+		FinalityDistance:       distance,
+	}
+}
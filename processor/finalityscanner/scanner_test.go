@@ -0,0 +1,20 @@
+package finalityscanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveBlock(t *testing.T) {
+	root := "0xAB01"
+	assert.Equal(t, resolveFinalize, resolveBlock("0xab01", root))
+	assert.Equal(t, resolveOrphan, resolveBlock("0xab02", root))
+	assert.Equal(t, resolveOrphan, resolveBlock("0xab02", ""))
+}
+
+func TestResolveLegacyBlock(t *testing.T) {
+	// a row indexed before the block root was recorded is backfilled, never removed.
+	assert.Equal(t, resolveBackfill, resolveBlock("", "0xab01"))
+	assert.Equal(t, resolveUnknown, resolveBlock("", ""))
+}
@@ -0,0 +1,325 @@
+package mevscanner
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	relayapi "github.com/xueqianLu/deep-dive-beacon/relay"
+	"gorm.io/gorm"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// attributionDelay leave relays time to publish the delivered payload of a slot.
+	attributionDelay = uint64(64)
+	attributionBatch = 500
+)
+
+// MevScanner ingest relay delivered payloads from relay data apis and local dumps, and
+// attribute every indexed post-merge block to its builder and relays, or as locally built.
+type MevScanner struct {
+	config      *config.Config
+	db          *gorm.DB
+	rdb         *redis.Client
+	logger      *logrus.Logger
+	services    *services.Services
+	notifier    *notify.Dispatcher
+	quit        chan struct{}
+	relays      []*relayapi.RelayClient
+	loadedDumps map[string]bool
+	running     bool
+}
+
+func NewMevScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *MevScanner {
+	relays := make([]*relayapi.RelayClient, 0, len(cfg.Mev.Relays))
+	for _, relay := range cfg.Mev.Relays {
+		relays = append(relays, relayapi.NewRelayClient(relay.Name, relay.URL))
+	}
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &MevScanner{
+		config:      cfg,
+		db:          db,
+		rdb:         redis,
+		logger:      logger,
+		services:    svc,
+		quit:        make(chan struct{}),
+		notifier:    notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		relays:      relays,
+		loadedDumps: make(map[string]bool),
+	}
+}
+
+func (s *MevScanner) Start() error {
+	s.logger.Info("Starting mev scanner service")
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Mev scanner service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_MEV)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Mev scan task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 30)
+		}
+	}
+}
+
+func (s *MevScanner) Stop() {
+	close(s.quit)
+}
+
+func (s *MevScanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "mev-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	s.loadDumps(logger)
+	beaconTask, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Warn("Beacon block scan task not found, skip mev attribution")
+		return nil
+	}
+	if beaconTask.LastNumber < attributionDelay {
+		return nil
+	}
+	maxSlot := beaconTask.LastNumber - attributionDelay
+	// the slots after a pending range task, such as a reindex, are not indexed yet.
+	pending, found, err := s.services.DirectlyScan.GetPendingLastNumber(constant.DIRECTLY_SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get pending range tasks")
+		return err
+	}
+	if found && pending < maxSlot {
+		maxSlot = pending
+	}
+	// a slot is attributed only once every relay deliveries of the slot are stored, otherwise
+	// blocks of relays failing to respond would be saved as locally built.
+	for _, relay := range s.relays {
+		low, high, err := s.ingestRelay(relay, task.LastNumber, beaconTask.LastNumber)
+		if err != nil {
+			logger.WithField("relay", relay.Name()).WithError(err).Warn("Failed to ingest relay payloads")
+		}
+		if low > task.LastNumber {
+			maxSlot = task.LastNumber
+		} else if high < maxSlot {
+			maxSlot = high
+		}
+	}
+	for task.LastNumber < maxSlot {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		blocks, err := s.services.BeaconBlock.GetPayloadBlocks(task.LastNumber, maxSlot, attributionBatch)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get beacon blocks")
+			return err
+		}
+		if len(blocks) == 0 {
+			task.LastNumber = maxSlot
+			break
+		}
+		payloads, err := s.services.Mev.GetRelayPayloadsBySlotRange(blocks[0].SlotNumber, blocks[len(blocks)-1].SlotNumber)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get relay payloads")
+			return err
+		}
+		for _, blk := range blocks {
+			if blk.ExecutionBlockHash != "" {
+				if err := s.services.Mev.SaveBlockMev(attribute(blk, payloads[blk.SlotNumber])); err != nil {
+					logger.WithField("slot", blk.SlotNumber).WithError(err).Error("Failed to save block mev")
+					return err
+				}
+				s.services.BeaconBlock.InvalidateCache(blk)
+			}
+			task.LastNumber = blk.SlotNumber
+		}
+		s.services.ScanTask.UpdateScanTask(task)
+		logger.WithField("slot", task.LastNumber).Debug("Attributed blocks to builders")
+	}
+	s.services.ScanTask.UpdateScanTask(task)
+	return nil
+}
+
+// relayCursor name a persisted bound of the deliveries stored for relay, every delivery with
+// slot in (low, high] is stored.
+func relayCursor(relay string, bound string) string {
+	return "mev:" + relay + ":" + bound
+}
+
+// ingestRelay store the deliveries of relay newer than its high cursor, then continue the
+// backfill down from its low cursor to startSlot, page by page so an interrupted backfill
+// resume where it stopped. headSlot is the latest indexed slot, the relay has delivered every
+// payload up to it. Return the bounds of the stored slots (low, high].
+func (s *MevScanner) ingestRelay(relay *relayapi.RelayClient, startSlot, headSlot uint64) (uint64, uint64, error) {
+	highName, lowName := relayCursor(relay.Name(), "high"), relayCursor(relay.Name(), "low")
+	high, found, err := s.services.ScanTask.GetCursor(highName)
+	if err != nil {
+		return 0, 0, err
+	}
+	low, _, err := s.services.ScanTask.GetCursor(lowName)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		// nothing stored yet, the backfill below start from the head.
+		high, low = headSlot, headSlot
+		if err := s.services.ScanTask.SetCursor(lowName, low); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	cursor := uint64(0)
+	for {
+		traces, err := relay.GetDeliveredPayloads(cursor, relayapi.PageLimit)
+		if err != nil {
+			return low, high, err
+		}
+		payloads := toRelayPayloads(relay.Name(), traces, high)
+		if err := s.services.Mev.SaveRelayPayloads(payloads); err != nil {
+			return low, high, err
+		}
+		if len(traces) < relayapi.PageLimit || len(payloads) < len(traces) {
+			break
+		}
+		oldest := traces[len(traces)-1].Slot
+		if oldest == 0 {
+			break
+		}
+		cursor = oldest - 1
+	}
+	if headSlot > high {
+		high = headSlot
+	}
+	if err := s.services.ScanTask.SetCursor(highName, high); err != nil {
+		return low, high, err
+	}
+
+	for low > startSlot {
+		traces, err := relay.GetDeliveredPayloads(low, relayapi.PageLimit)
+		if err != nil {
+			return low, high, err
+		}
+		if err := s.services.Mev.SaveRelayPayloads(toRelayPayloads(relay.Name(), traces, startSlot)); err != nil {
+			return low, high, err
+		}
+		next := startSlot
+		if len(traces) == relayapi.PageLimit {
+			if oldest := traces[len(traces)-1].Slot; oldest > startSlot+1 {
+				next = oldest - 1
+			}
+		}
+		if err := s.services.ScanTask.SetCursor(lowName, next); err != nil {
+			return low, high, err
+		}
+		low = next
+	}
+	return low, high, nil
+}
+
+// loadDumps ingest every <relay>.json dump of the dump directory once per process.
+func (s *MevScanner) loadDumps(logger *logrus.Entry) {
+	if s.config.Mev.DumpDir == "" {
+		return
+	}
+	files, err := filepath.Glob(filepath.Join(s.config.Mev.DumpDir, "*.json"))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to list relay dumps")
+		return
+	}
+	for _, file := range files {
+		if s.loadedDumps[file] {
+			continue
+		}
+		traces, err := relayapi.LoadDump(file)
+		if err != nil {
+			logger.WithField("file", file).WithError(err).Warn("Failed to load relay dump")
+			continue
+		}
+		relay := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if err := s.services.Mev.SaveRelayPayloads(toRelayPayloads(relay, traces, 0)); err != nil {
+			logger.WithField("file", file).WithError(err).Warn("Failed to save relay dump")
+			continue
+		}
+		s.loadedDumps[file] = true
+		logger.WithFields(logrus.Fields{"relay": relay, "payloads": len(traces)}).Info("Loaded relay dump")
+	}
+}
+
+// toRelayPayloads convert the traces with slot after minSlot.
+func toRelayPayloads(relay string, traces []*relayapi.BidTrace, minSlot uint64) []*dbmodels.RelayPayload {
+	payloads := make([]*dbmodels.RelayPayload, 0, len(traces))
+	for _, trace := range traces {
+		if trace.Slot <= minSlot {
+			continue
+		}
+		value := trace.Value
+		if value == "" {
+			value = "0"
+		}
+		payloads = append(payloads, &dbmodels.RelayPayload{
+			Relay:                relay,
+			SlotNumber:           trace.Slot,
+			BlockHash:            strings.ToLower(trace.BlockHash),
+			BlockNumber:          trace.BlockNumber,
+			BuilderPubkey:        strings.ToLower(trace.BuilderPubkey),
+			ProposerPubkey:       strings.ToLower(trace.ProposerPubkey),
+			ProposerFeeRecipient: strings.ToLower(trace.ProposerFeeRecipient),
+			Value:                value,
+			GasUsed:              trace.GasUsed,
+			GasLimit:             trace.GasLimit,
+			NumTx:                uint(trace.NumTx),
+		})
+	}
+	return payloads
+}
+
+// attribute match the block payload with the relay deliveries of its slot, a block no relay
+// delivered is considered locally built.
+func attribute(blk *dbmodels.BeaconBlock, payloads []*dbmodels.RelayPayload) *dbmodels.BlockMev {
+	mev := &dbmodels.BlockMev{
+		SlotNumber:   blk.SlotNumber,
+		BlockHash:    blk.ExecutionBlockHash,
+		LocallyBuilt: true,
+		Value:        "0",
+	}
+	relays := make([]string, 0)
+	best := new(big.Int)
+	for _, payload := range payloads {
+		if !strings.EqualFold(payload.BlockHash, blk.ExecutionBlockHash) {
+			continue
+		}
+		mev.LocallyBuilt = false
+		mev.BuilderPubkey = payload.BuilderPubkey
+		relays = append(relays, payload.Relay)
+		if value, ok := new(big.Int).SetString(payload.Value, 10); ok && value.Cmp(best) > 0 {
+			best = value
+		}
+	}
+	mev.Relays = strings.Join(relays, ",")
+	mev.Value = best.String()
+	return mev
+}
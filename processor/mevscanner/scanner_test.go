@@ -0,0 +1,40 @@
+package mevscanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	relayapi "github.com/xueqianLu/deep-dive-beacon/relay"
+)
+
+func TestAttribute(t *testing.T) {
+	blk := &dbmodels.BeaconBlock{SlotNumber: 100, ExecutionBlockHash: "0xaa"}
+	payloads := []*dbmodels.RelayPayload{
+		{Relay: "flashbots", BlockHash: "0xAA", BuilderPubkey: "0xb1", Value: "100"},
+		{Relay: "ultrasound", BlockHash: "0xaa", BuilderPubkey: "0xb1", Value: "120"},
+		{Relay: "other", BlockHash: "0xbb", BuilderPubkey: "0xb2", Value: "500"},
+	}
+
+	mev := attribute(blk, payloads)
+	assert.False(t, mev.LocallyBuilt)
+	assert.Equal(t, "0xb1", mev.BuilderPubkey)
+	assert.Equal(t, "flashbots,ultrasound", mev.Relays)
+	assert.Equal(t, "120", mev.Value)
+
+	local := attribute(blk, payloads[2:])
+	assert.True(t, local.LocallyBuilt)
+	assert.Equal(t, "", local.Relays)
+	assert.Equal(t, "0", local.Value)
+}
+
+func TestToRelayPayloads(t *testing.T) {
+	traces := []*relayapi.BidTrace{
+		{Slot: 12, BlockHash: "0xAB", Value: "7"},
+		{Slot: 10, BlockHash: "0xCD"},
+	}
+	payloads := toRelayPayloads("local", traces, 10)
+	assert.Len(t, payloads, 1)
+	assert.Equal(t, "0xab", payloads[0].BlockHash)
+	assert.Equal(t, "local", payloads[0].Relay)
+}
@@ -0,0 +1,173 @@
+package payloadlinker
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	linkBatch = 500
+)
+
+// PayloadLinker join every indexed beacon block to the indexed execution block of its payload,
+// and flag payloads missing from the execution index or not matching it.
+type PayloadLinker struct {
+	config   *config.Config
+	db       *gorm.DB
+	rdb      *redis.Client
+	logger   *logrus.Logger
+	services *services.Services
+	notifier *notify.Dispatcher
+	quit     chan struct{}
+	running  bool
+}
+
+func NewPayloadLinker(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *PayloadLinker {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &PayloadLinker{
+		config:   cfg,
+		db:       db,
+		rdb:      redis,
+		logger:   logger,
+		services: svc,
+		quit:     make(chan struct{}),
+		notifier: notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+	}
+}
+
+func (s *PayloadLinker) Start() error {
+	s.logger.Info("Starting payload linker service")
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Payload linker service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_PAYLOAD_LINK)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Payload link task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 10)
+		}
+	}
+}
+
+func (s *PayloadLinker) Stop() {
+	close(s.quit)
+}
+
+func (s *PayloadLinker) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "payload-linker")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	beaconTask, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Warn("Beacon block scan task not found, skip linking payloads")
+		return nil
+	}
+	eth1Task, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_ETH1_BLOCK)
+	if err != nil {
+		logger.WithError(err).Warn("Eth1 block scan task not found, skip linking payloads")
+		return nil
+	}
+	maxSlot := beaconTask.LastNumber
+	// the slots after a pending range task, such as a reindex, are not indexed yet.
+	pending, found, err := s.services.DirectlyScan.GetPendingLastNumber(constant.DIRECTLY_SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get pending range tasks")
+		return err
+	}
+	if found && pending < maxSlot {
+		maxSlot = pending
+	}
+
+	for {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		blocks, err := s.services.BeaconBlock.GetPayloadBlocks(task.LastNumber, maxSlot, linkBatch)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get beacon blocks")
+			return err
+		}
+		if len(blocks) == 0 {
+			return nil
+		}
+		numbers := make([]uint64, 0, len(blocks))
+		for _, blk := range blocks {
+			if blk.ExecutionBlockNumber != nil {
+				numbers = append(numbers, *blk.ExecutionBlockNumber)
+			}
+		}
+		headers, err := s.services.Eth1.GetHeadersByNumbers(numbers)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get execution headers")
+			return err
+		}
+		for _, blk := range blocks {
+			if blk.ExecutionBlockNumber != nil {
+				// wait for the eth1 scanner to index the execution block.
+				if *blk.ExecutionBlockNumber > eth1Task.LastNumber {
+					s.services.ScanTask.UpdateScanTask(task)
+					return nil
+				}
+				status := checkPayload(blk, headers[*blk.ExecutionBlockNumber])
+				if err := s.services.BeaconBlock.UpdatePayloadStatus(blk, status); err != nil {
+					logger.WithField("slot", blk.SlotNumber).WithError(err).Error("Failed to update payload status")
+					return err
+				}
+				if status != constant.PAYLOAD_STATUS_LINKED {
+					logger.WithFields(logrus.Fields{
+						"slot":   blk.SlotNumber,
+						"number": *blk.ExecutionBlockNumber,
+						"hash":   blk.ExecutionBlockHash,
+						"status": status,
+					}).Warn("Execution payload not consistent with execution index")
+				}
+			}
+			task.LastNumber = blk.SlotNumber
+		}
+		s.services.ScanTask.UpdateScanTask(task)
+		logger.WithField("slot", task.LastNumber).Debug("Linked execution payloads")
+	}
+}
+
+// checkPayload compare the payload reference of the beacon block with the execution block
+// indexed at the same number.
+func checkPayload(blk *dbmodels.BeaconBlock, header *dbmodels.Eth1BlockHeader) string {
+	if header == nil {
+		return constant.PAYLOAD_STATUS_MISSING
+	}
+	if !strings.EqualFold(header.BlockHash, blk.ExecutionBlockHash) {
+		return constant.PAYLOAD_STATUS_MISMATCH
+	}
+	// since Deneb the execution block commit to the parent beacon block root.
+	if header.ParentBeaconRoot != "" && !strings.EqualFold(header.ParentBeaconRoot, blk.ParentRoot) {
+		return constant.PAYLOAD_STATUS_MISMATCH
+	}
+	return constant.PAYLOAD_STATUS_LINKED
+}
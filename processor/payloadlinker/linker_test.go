@@ -0,0 +1,19 @@
+package payloadlinker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func TestCheckPayload(t *testing.T) {
+	blk := &dbmodels.BeaconBlock{ExecutionBlockHash: "0xAA", ParentRoot: "0x01"}
+
+	assert.Equal(t, constant.PAYLOAD_STATUS_MISSING, checkPayload(blk, nil))
+	assert.Equal(t, constant.PAYLOAD_STATUS_LINKED, checkPayload(blk, &dbmodels.Eth1BlockHeader{BlockHash: "0xaa"}))
+	assert.Equal(t, constant.PAYLOAD_STATUS_LINKED, checkPayload(blk, &dbmodels.Eth1BlockHeader{BlockHash: "0xaa", ParentBeaconRoot: "0x01"}))
+	assert.Equal(t, constant.PAYLOAD_STATUS_MISMATCH, checkPayload(blk, &dbmodels.Eth1BlockHeader{BlockHash: "0xbb"}))
+	assert.Equal(t, constant.PAYLOAD_STATUS_MISMATCH, checkPayload(blk, &dbmodels.Eth1BlockHeader{BlockHash: "0xaa", ParentBeaconRoot: "0x02"}))
+}
@@ -0,0 +1,181 @@
+package validatorscanner
+
+import (
+	"fmt"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+var (
+	slotsPerEpoch  = uint64(32)
+	farFutureEpoch = phase0.Epoch(0xffffffffffffffff)
+)
+
+// ValidatorScanner snapshot the validator registry every configured number of epochs.
+type ValidatorScanner struct {
+	config       *config.Config
+	db           *gorm.DB
+	rdb          *redis.Client
+	logger       *logrus.Logger
+	services     *services.Services
+	notifier     *notify.Dispatcher
+	quit         chan struct{}
+	beaconClient *beaconapi.BeaconClient
+	interval     uint64
+	running      bool
+}
+
+func NewValidatorScanner(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *ValidatorScanner {
+	interval := cfg.Indexer.ValidatorSnapshotInterval
+	if interval == 0 {
+		interval = 1
+	}
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &ValidatorScanner{
+		config:       cfg,
+		db:           db,
+		rdb:          redis,
+		logger:       logger,
+		services:     svc,
+		quit:         make(chan struct{}),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
+		interval:     interval,
+	}
+}
+
+func (s *ValidatorScanner) Start() error {
+	s.logger.Info("Starting validator snapshot service")
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Validator snapshot service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_VALIDATOR_SNAPSHOT)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Validator snapshot task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 10)
+		}
+	}
+}
+
+func (s *ValidatorScanner) Stop() {
+	close(s.quit)
+}
+
+// nextEpoch return the next snapshot epoch after last, aligned to the interval.
+func (s *ValidatorScanner) nextEpoch(last uint64) uint64 {
+	return (last/s.interval + 1) * s.interval
+}
+
+func (s *ValidatorScanner) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "validator-scanner")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	latest, err := s.beaconClient.GetLatestBeaconHeader()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get latest beacon header")
+		return err
+	}
+	headEpoch := uint64(latest.Header.Message.Slot) / slotsPerEpoch
+
+	for epoch := s.nextEpoch(task.LastNumber); epoch <= headEpoch; epoch = s.nextEpoch(epoch) {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		if err := s.snapshot(epoch); err != nil {
+			logger.WithField("epoch", epoch).WithError(err).Error("Failed to snapshot validators")
+			return err
+		}
+		task.LastNumber = epoch
+		s.services.ScanTask.UpdateScanTask(task)
+	}
+	return nil
+}
+
+func (s *ValidatorScanner) snapshot(epoch uint64) error {
+	state := fmt.Sprintf("%d", epoch*slotsPerEpoch)
+	vals, err := s.beaconClient.GetValidators(state)
+	if err != nil {
+		return err
+	}
+	dbvals := make([]*dbmodels.Validator, 0, len(vals))
+	for _, val := range vals {
+		dbvals = append(dbvals, ToDBValidator(val))
+	}
+	sort.Slice(dbvals, func(i, j int) bool {
+		return dbvals[i].ValidatorIndex < dbvals[j].ValidatorIndex
+	})
+	changed, err := s.services.Validator.SaveSnapshot(epoch, dbvals)
+	if err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"epoch":      epoch,
+		"validators": len(dbvals),
+		"changed":    changed,
+	}).Info("Saved validator snapshot")
+
+	// new validators may resolve the execution requests indexed before they joined the registry.
+	resolved, err := s.services.BeaconBlock.ResolveRequestValidatorIndices()
+	if err != nil {
+		return err
+	}
+	if resolved > 0 {
+		s.logger.WithField("requests", resolved).Info("Resolved execution request validator indices")
+	}
+	return nil
+}
+
+// ToDBValidator convert the api validator, FAR_FUTURE_EPOCH is stored as null.
+func ToDBValidator(val *apiv1.Validator) *dbmodels.Validator {
+	return &dbmodels.Validator{
+		ValidatorIndex:             uint64(val.Index),
+		Pubkey:                     val.Validator.PublicKey.String(),
+		WithdrawalCredentials:      fmt.Sprintf("%#x", val.Validator.WithdrawalCredentials),
+		EffectiveBalance:           uint64(val.Validator.EffectiveBalance),
+		Slashed:                    val.Validator.Slashed,
+		ActivationEligibilityEpoch: toDBEpoch(val.Validator.ActivationEligibilityEpoch),
+		ActivationEpoch:            toDBEpoch(val.Validator.ActivationEpoch),
+		ExitEpoch:                  toDBEpoch(val.Validator.ExitEpoch),
+		WithdrawableEpoch:          toDBEpoch(val.Validator.WithdrawableEpoch),
+		Status:                     val.Status.String(),
+	}
+}
+
+func toDBEpoch(epoch phase0.Epoch) *uint64 {
+	if epoch == farFutureEpoch {
+		return nil
+	}
+	v := uint64(epoch)
+	return &v
+}
@@ -0,0 +1,293 @@
+package watchmonitor
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	beaconapi "github.com/xueqianLu/deep-dive-beacon/beacon"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/internal/stream"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	slotsPerEpoch = uint64(32)
+)
+
+// WatchMonitor report, for every finalized epoch, the missed duties, balance decreases and
+// slashings of the watched validators through the notifiers.
+type WatchMonitor struct {
+	config       *config.Config
+	db           *gorm.DB
+	rdb          *redis.Client
+	logger       *logrus.Logger
+	services     *services.Services
+	quit         chan struct{}
+	beaconClient *beaconapi.BeaconClient
+	notifier     *notify.Dispatcher
+	publisher    *stream.Publisher
+	running      bool
+}
+
+func NewWatchMonitor(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *WatchMonitor {
+	svc := services.NewServices(db, redis, logger, cfg)
+	return &WatchMonitor{
+		config:       cfg,
+		db:           db,
+		rdb:          redis,
+		logger:       logger,
+		services:     svc,
+		quit:         make(chan struct{}),
+		beaconClient: beaconapi.NewBeaconGwClient(cfg.Chain.BeaconURL),
+		notifier:     notify.NewDispatcherFromConfig(cfg, svc.Notify, logger),
+		publisher:    stream.NewPublisher(redis, cfg.Stream),
+	}
+}
+
+func (s *WatchMonitor) Start() error {
+	s.logger.Info("Starting watchlist monitor service")
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Watchlist monitor service stopped")
+			return nil
+
+		case <-ticker.C:
+			task, err := s.services.ScanTask.GetEnabledScanTaskByType(constant.SCAN_TYPE_WATCHLIST)
+			if task == nil || err != nil {
+				s.logger.WithError(err).Info("Watchlist task is not enabled, skipping...")
+				continue
+			}
+			if !s.running {
+				go func() {
+					s.notifier.TaskResult(task.TaskType, s.doScanTask(task))
+				}()
+			}
+			ticker.Reset(time.Second * 10)
+		}
+	}
+}
+
+func (s *WatchMonitor) Stop() {
+	close(s.quit)
+}
+
+func (s *WatchMonitor) doScanTask(task *dbmodels.ScanTask) error {
+	logger := s.logger.WithField("module", "watch-monitor")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	finality, err := s.beaconClient.GetFinalityCheckpoints("head")
+	if err != nil {
+		logger.WithError(err).Error("Failed to get finality checkpoints")
+		return err
+	}
+	blockTask, err := s.services.ScanTask.GetScanTaskByType(constant.SCAN_TYPE_BEACON_BLOCK)
+	if err != nil {
+		logger.WithError(err).Warn("Beacon block scan task not found, skip watchlist monitor")
+		return nil
+	}
+	finalized := uint64(finality.Finalized.Epoch)
+
+	for epoch := task.LastNumber + 1; epoch < finalized && lastSlot(epoch) <= blockTask.LastNumber; epoch++ {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		watched, err := s.services.Watchlist.List()
+		if err != nil {
+			logger.WithError(err).Error("Failed to get watchlist")
+			return err
+		}
+		if len(watched) > 0 {
+			indices := make([]uint64, 0, len(watched))
+			for _, w := range watched {
+				indices = append(indices, w.ValidatorIndex)
+			}
+			pending, err := s.services.Duty.CountPendingDuties(epoch, indices)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				// wait for the duty scanner to reconcile the epoch.
+				return nil
+			}
+			events, err := s.collect(epoch, watched, indices)
+			if err != nil {
+				logger.WithField("epoch", epoch).WithError(err).Error("Failed to collect watchlist events")
+				return err
+			}
+			for _, event := range events {
+				if err := s.notifier.Dispatch(event); err != nil {
+					logger.WithField("epoch", epoch).WithError(err).Error("Failed to dispatch watchlist event")
+					return err
+				}
+				// the slashing is recorded once notified, so a failed dispatch is retried.
+				if event.Type == constant.EVENT_SLASHED {
+					if err := s.services.Watchlist.MarkSlashed(*event.ValidatorIndex); err != nil {
+						return err
+					}
+				}
+				if err := s.publisher.PublishValidatorEvent(context.Background(), event); err != nil {
+					logger.WithError(err).Warn("Failed to publish watchlist event")
+				}
+			}
+			logger.WithFields(logrus.Fields{
+				"epoch":  epoch,
+				"events": len(events),
+			}).Info("Checked watched validators")
+		}
+		task.LastNumber = epoch
+		s.services.ScanTask.UpdateScanTask(task)
+	}
+	return nil
+}
+
+func lastSlot(epoch uint64) uint64 {
+	return (epoch+1)*slotsPerEpoch - 1
+}
+
+func (s *WatchMonitor) collect(epoch uint64, watched []*dbmodels.WatchedValidator, indices []uint64) ([]*notify.Event, error) {
+	events := make([]*notify.Event, 0)
+
+	proposals, err := s.services.Duty.GetProposerDutiesByEpoch(epoch, indices, constant.DUTY_STATUS_MISSED)
+	if err != nil {
+		return nil, err
+	}
+	for _, duty := range proposals {
+		events = append(events, newEvent(constant.EVENT_MISSED_PROPOSAL, epoch, duty.ValidatorIndex, &duty.Slot,
+			fmt.Sprintf("validator %d missed block proposal at slot %d", duty.ValidatorIndex, duty.Slot), nil))
+	}
+
+	attestations, err := s.services.Duty.GetAttesterDutiesByEpoch(epoch, indices, constant.DUTY_STATUS_MISSED)
+	if err != nil {
+		return nil, err
+	}
+	for _, duty := range attestations {
+		events = append(events, newEvent(constant.EVENT_MISSED_ATTESTATION, epoch, duty.ValidatorIndex, &duty.Slot,
+			fmt.Sprintf("validator %d missed attestation for slot %d", duty.ValidatorIndex, duty.Slot), nil))
+	}
+
+	syncEvents, err := s.collectSync(epoch, indices)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, syncEvents...)
+
+	validatorEvents, err := s.collectValidators(epoch, watched)
+	if err != nil {
+		return nil, err
+	}
+	return append(events, validatorEvents...), nil
+}
+
+// collectSync count the sync committee signatures missing from the blocks of the epoch.
+func (s *WatchMonitor) collectSync(epoch uint64, indices []uint64) ([]*notify.Event, error) {
+	committee, err := s.beaconClient.GetSyncCommittee(fmt.Sprintf("%d", lastSlot(epoch)), phase0.Epoch(epoch))
+	if err != nil {
+		// no sync committee before altair.
+		s.logger.WithField("epoch", epoch).WithError(err).Debug("Skip sync duties")
+		return nil, nil
+	}
+	blocks, err := s.services.BeaconBlock.GetBlocksBySlotRange(epoch*slotsPerEpoch, lastSlot(epoch))
+	if err != nil {
+		return nil, err
+	}
+	bits := make([][]byte, 0, len(blocks))
+	for _, blk := range blocks {
+		if data, err := hex.DecodeString(strings.TrimPrefix(blk.SyncAggregateBits, "0x")); err == nil && len(data) > 0 {
+			bits = append(bits, data)
+		}
+	}
+	events := make([]*notify.Event, 0)
+	for index, count := range missedSync(committee, indices, bits) {
+		events = append(events, newEvent(constant.EVENT_MISSED_SYNC, epoch, index, nil,
+			fmt.Sprintf("validator %d missed %d sync committee signatures", index, count),
+			map[string]interface{}{"missed": count, "blocks": len(bits)}))
+	}
+	return events, nil
+}
+
+// collectValidators compare the watched validators at the end of the epoch with the previous epoch.
+func (s *WatchMonitor) collectValidators(epoch uint64, watched []*dbmodels.WatchedValidator) ([]*notify.Event, error) {
+	indices := make([]phase0.ValidatorIndex, 0, len(watched))
+	for _, w := range watched {
+		indices = append(indices, phase0.ValidatorIndex(w.ValidatorIndex))
+	}
+	current, err := s.beaconClient.GetValidatorsByIndices(fmt.Sprintf("%d", lastSlot(epoch)), indices)
+	if err != nil {
+		return nil, err
+	}
+	previous := current
+	if epoch > 0 {
+		if previous, err = s.beaconClient.GetValidatorsByIndices(fmt.Sprintf("%d", lastSlot(epoch-1)), indices); err != nil {
+			return nil, err
+		}
+	}
+	events := make([]*notify.Event, 0)
+	for _, w := range watched {
+		val, exist := current[phase0.ValidatorIndex(w.ValidatorIndex)]
+		if !exist {
+			continue
+		}
+		if prev, exist := previous[phase0.ValidatorIndex(w.ValidatorIndex)]; exist && val.Balance < prev.Balance {
+			delta := uint64(prev.Balance - val.Balance)
+			events = append(events, newEvent(constant.EVENT_BALANCE_DECREASE, epoch, w.ValidatorIndex, nil,
+				fmt.Sprintf("validator %d balance decreased by %d gwei", w.ValidatorIndex, delta),
+				map[string]interface{}{"previous": uint64(prev.Balance), "current": uint64(val.Balance)}))
+		}
+		if val.Validator != nil && val.Validator.Slashed && !w.Slashed {
+			events = append(events, newEvent(constant.EVENT_SLASHED, epoch, w.ValidatorIndex, nil,
+				fmt.Sprintf("validator %d has been slashed", w.ValidatorIndex), nil))
+		}
+	}
+	return events, nil
+}
+
+// missedSync return, for every watched validator of the committee, the number of sync aggregates
+// that miss its signature. A validator may hold several committee positions.
+func missedSync(committee []phase0.ValidatorIndex, indices []uint64, aggregates [][]byte) map[uint64]int {
+	watched := make(map[uint64]bool, len(indices))
+	for _, index := range indices {
+		watched[index] = true
+	}
+	missed := make(map[uint64]int)
+	for position, index := range committee {
+		if !watched[uint64(index)] {
+			continue
+		}
+		for _, bits := range aggregates {
+			if position/8 >= len(bits) || bits[position/8]&(1<<(position%8)) == 0 {
+				missed[uint64(index)]++
+			}
+		}
+	}
+	return missed
+}
+
+func newEvent(eventType string, epoch uint64, index uint64, slot *uint64, message string, data map[string]interface{}) *notify.Event {
+	return &notify.Event{
+		Type:           eventType,
+		Epoch:          epoch,
+		Slot:           slot,
+		ValidatorIndex: &index,
+		Message:        message,
+		Data:           data,
+	}
+}
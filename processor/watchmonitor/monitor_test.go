@@ -0,0 +1,20 @@
+package watchmonitor
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissedSync(t *testing.T) {
+	// validator 7 hold positions 1 and 9, validator 8 position 2.
+	committee := []phase0.ValidatorIndex{3, 7, 8, 4, 5, 6, 1, 2, 0, 7}
+	aggregates := [][]byte{
+		{0xff, 0xff}, // everyone signed
+		{0x04, 0x02}, // position 2 and 9 signed
+		{0x00},       // too short, position 9 missing
+	}
+	missed := missedSync(committee, []uint64{7, 8, 42}, aggregates)
+	assert.Equal(t, map[uint64]int{7: 3, 8: 1}, missed)
+}
@@ -0,0 +1,148 @@
+package webhooksender
+
+import (
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/xueqianLu/deep-dive-beacon/config"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/internal/notify"
+	"github.com/xueqianLu/deep-dive-beacon/internal/services"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	batchSize   = 100
+	sendTimeout = 10 * time.Second
+)
+
+// WebhookSender deliver the events queued in the webhook outbox, failed deliveries are
+// retried with exponential backoff until the configured max attempts.
+type WebhookSender struct {
+	config   *config.Config
+	db       *gorm.DB
+	rdb      *redis.Client
+	logger   *logrus.Logger
+	services *services.Services
+	quit     chan struct{}
+	client   *notify.WebhookClient
+	webhooks map[string]config.WebhookConfig
+	running  bool
+}
+
+func NewWebhookSender(cfg *config.Config, db *gorm.DB, redis *redis.Client, logger *logrus.Logger) *WebhookSender {
+	webhooks := make(map[string]config.WebhookConfig)
+	for _, hook := range cfg.Notify.Webhooks {
+		webhooks[hook.Name] = hook
+	}
+	return &WebhookSender{
+		config:   cfg,
+		db:       db,
+		rdb:      redis,
+		logger:   logger,
+		services: services.NewServices(db, redis, logger, cfg),
+		quit:     make(chan struct{}),
+		client:   notify.NewWebhookClient(sendTimeout),
+		webhooks: webhooks,
+	}
+}
+
+func (s *WebhookSender) Start() error {
+	s.logger.Info("Starting webhook sender service")
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			s.logger.Info("Webhook sender service stopped")
+			return nil
+
+		case <-ticker.C:
+			if !s.running {
+				go s.deliverDue()
+			}
+		}
+	}
+}
+
+func (s *WebhookSender) Stop() {
+	close(s.quit)
+}
+
+func (s *WebhookSender) deliverDue() error {
+	logger := s.logger.WithField("module", "webhook-sender")
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+
+	entries, err := s.services.Notify.GetDueOutbox(time.Now(), batchSize)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get due webhook events")
+		return err
+	}
+	for _, entry := range entries {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		delivery := s.deliver(entry)
+		if err := s.services.Notify.SaveDeliveryResult(entry, delivery); err != nil {
+			logger.WithField("id", entry.ID).WithError(err).Error("Failed to save webhook delivery")
+			return err
+		}
+		logger.WithFields(logrus.Fields{
+			"id":      entry.ID,
+			"webhook": entry.Webhook,
+			"event":   entry.EventType,
+			"attempt": entry.Attempts,
+			"status":  entry.Status,
+		}).Debug("Delivered webhook event")
+	}
+	return nil
+}
+
+func (s *WebhookSender) deliver(entry *dbmodels.WebhookOutbox) *dbmodels.WebhookDelivery {
+	start := time.Now()
+	var code int
+	var err error
+	if hook, exist := s.webhooks[entry.Webhook]; exist {
+		code, err = s.client.Send(hook, entry.ID, entry.EventType, []byte(entry.Payload))
+	} else {
+		err = fmt.Errorf("webhook %s is not configured", entry.Webhook)
+	}
+	applyResult(entry, err, s.config.Notify.MaxAttempts, time.Now())
+	delivery := &dbmodels.WebhookDelivery{
+		OutboxID:   entry.ID,
+		Webhook:    entry.Webhook,
+		Attempt:    entry.Attempts,
+		StatusCode: code,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	return delivery
+}
+
+// applyResult move the outbox entry to its state after one more delivery attempt.
+func applyResult(entry *dbmodels.WebhookOutbox, err error, maxAttempts int, now time.Time) {
+	entry.Attempts++
+	if err == nil {
+		entry.Status = constant.OUTBOX_STATUS_DELIVERED
+		entry.LastError = ""
+		entry.DeliveredAt = &now
+		return
+	}
+	entry.LastError = err.Error()
+	if entry.Attempts >= maxAttempts {
+		entry.Status = constant.OUTBOX_STATUS_FAILED
+		return
+	}
+	entry.NextAttemptAt = now.Add(notify.Backoff(entry.Attempts))
+}
@@ -0,0 +1,34 @@
+package webhooksender
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xueqianLu/deep-dive-beacon/constant"
+	"github.com/xueqianLu/deep-dive-beacon/models/dbmodels"
+)
+
+func TestApplyResult(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	entry := &dbmodels.WebhookOutbox{Status: constant.OUTBOX_STATUS_PENDING}
+
+	applyResult(entry, errors.New("connection refused"), 3, now)
+	assert.Equal(t, constant.OUTBOX_STATUS_PENDING, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.Equal(t, now.Add(10*time.Second), entry.NextAttemptAt)
+
+	applyResult(entry, errors.New("503"), 3, now)
+	assert.Equal(t, now.Add(20*time.Second), entry.NextAttemptAt)
+
+	applyResult(entry, errors.New("503"), 3, now)
+	assert.Equal(t, constant.OUTBOX_STATUS_FAILED, entry.Status)
+	assert.Equal(t, "503", entry.LastError)
+
+	delivered := &dbmodels.WebhookOutbox{Status: constant.OUTBOX_STATUS_PENDING, LastError: "timeout", Attempts: 1}
+	applyResult(delivered, nil, 3, now)
+	assert.Equal(t, constant.OUTBOX_STATUS_DELIVERED, delivered.Status)
+	assert.Empty(t, delivered.LastError)
+	assert.Equal(t, now, *delivered.DeliveredAt)
+}
@@ -0,0 +1,89 @@
+package relayapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const deliveredPayloadsPath = "/relay/v1/data/bidtraces/proposer_payload_delivered"
+
+// PageLimit is the max page size accepted by the relay data api.
+const PageLimit = 200
+
+// BidTrace is a payload delivered by a relay to a proposer.
+type BidTrace struct {
+	Slot                 uint64 `json:"slot,string"`
+	ParentHash           string `json:"parent_hash"`
+	BlockHash            string `json:"block_hash"`
+	BuilderPubkey        string `json:"builder_pubkey"`
+	ProposerPubkey       string `json:"proposer_pubkey"`
+	ProposerFeeRecipient string `json:"proposer_fee_recipient"`
+	GasLimit             uint64 `json:"gas_limit,string"`
+	GasUsed              uint64 `json:"gas_used,string"`
+	Value                string `json:"value"`
+	BlockNumber          uint64 `json:"block_number,string"`
+	NumTx                uint64 `json:"num_tx,string"`
+}
+
+// RelayClient read the data api of a mev-boost relay.
+type RelayClient struct {
+	name     string
+	endpoint string
+	http     *http.Client
+}
+
+func NewRelayClient(name string, endpoint string) *RelayClient {
+	return &RelayClient{
+		name:     name,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		http:     &http.Client{Timeout: time.Second * 30},
+	}
+}
+
+func (r *RelayClient) Name() string {
+	return r.name
+}
+
+// GetDeliveredPayloads return up to limit delivered payloads with slot <= cursor, newest first.
+// A zero cursor start from the latest delivered payload.
+func (r *RelayClient) GetDeliveredPayloads(cursor uint64, limit int) ([]*BidTrace, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if cursor > 0 {
+		query.Set("cursor", strconv.FormatUint(cursor, 10))
+	}
+	res, err := r.http.Get(r.endpoint + deliveredPayloadsPath + "?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("relay %s http status %d: %s", r.name, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var traces []*BidTrace
+	if err := json.NewDecoder(res.Body).Decode(&traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// LoadDump read delivered payloads saved from the relay data api as a json array.
+func LoadDump(path string) ([]*BidTrace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var traces []*BidTrace
+	if err := json.Unmarshal(data, &traces); err != nil {
+		return nil, fmt.Errorf("parse relay dump %s: %w", path, err)
+	}
+	return traces, nil
+}
@@ -0,0 +1,44 @@
+package relayapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deliveredPayloads = `[{"slot":"101","parent_hash":"0x01","block_hash":"0xaa","builder_pubkey":"0xb1",
+"proposer_pubkey":"0xp1","proposer_fee_recipient":"0xf1","gas_limit":"30000000","gas_used":"12000000",
+"value":"54321000000000000","block_number":"2001","num_tx":"120"}]`
+
+func TestGetDeliveredPayloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, deliveredPayloadsPath, r.URL.Path)
+		assert.Equal(t, "150", r.URL.Query().Get("cursor"))
+		assert.Equal(t, "200", r.URL.Query().Get("limit"))
+		w.Write([]byte(deliveredPayloads))
+	}))
+	defer server.Close()
+
+	traces, err := NewRelayClient("local", server.URL+"/").GetDeliveredPayloads(150, PageLimit)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(101), traces[0].Slot)
+	assert.Equal(t, "0xaa", traces[0].BlockHash)
+	assert.Equal(t, "54321000000000000", traces[0].Value)
+	assert.Equal(t, uint64(120), traces[0].NumTx)
+}
+
+func TestLoadDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local.json")
+	require.NoError(t, os.WriteFile(path, []byte(deliveredPayloads), 0o644))
+
+	traces, err := LoadDump(path)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(2001), traces[0].BlockNumber)
+}
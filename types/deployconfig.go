@@ -8,7 +8,23 @@ type DirectScanTask struct {
 	Start uint64 `json:"start"`
 	End   uint64 `json:"end"`
 }
+
+// EpochScanTask start from the first epoch after Start.
+type EpochScanTask struct {
+	Start uint64 `json:"start"`
+}
+
 type DeployConfig struct {
-	BlockScan  *BlockScanTask    `json:"block_scan"`
-	DirectScan []*DirectScanTask `json:"direct_scan"`
+	BlockScan         *BlockScanTask    `json:"block_scan"`
+	DirectScan        []*DirectScanTask `json:"direct_scan"`
+	ValidatorSnapshot *EpochScanTask    `json:"validator_snapshot"`
+	BalanceSample     *EpochScanTask    `json:"balance_sample"`
+	Duties            *EpochScanTask    `json:"duties"`
+	EpochSummary      *EpochScanTask    `json:"epoch_summary"`
+	Finality          *EpochScanTask    `json:"finality"`
+	Eth1Scan          *BlockScanTask    `json:"eth1_scan"`
+	DepositScan       *BlockScanTask    `json:"deposit_scan"`
+	PayloadLink       *BlockScanTask    `json:"payload_link"`
+	Mev               *BlockScanTask    `json:"mev"`
+	Watchlist         *EpochScanTask    `json:"watchlist"`
 }